@@ -0,0 +1,58 @@
+// bls package implements the verification of BLS (Boneh-Lynn-Shacham)
+// signatures in gnark, using the emulated pairings of BLS12-381 and BN254.
+// Both variants of the scheme are supported:
+//
+//   - minimal public key size (min-pk): public keys in G1, signatures and
+//     hashed messages in G2.
+//   - minimal signature size (min-sig): public keys in G2, signatures and
+//     hashed messages in G1.
+//
+// For BLS12-381, messages are hashed to the curve in-circuit following RFC
+// 9380 (SSWU, SHA-256 expansion) with the proof-of-possession ciphersuites.
+// For BN254, there is no in-circuit hash-to-curve in gnark, so the messages
+// must be hashed natively (see HashMinPkBN254 and HashMinSigBN254) and the
+// verification receives the hashed point. Public keys can be aggregated
+// in-circuit by a bitmap of signers, so a committee attestation can be
+// verified with a single pairing check. The aggregation is only secure if the
+// public keys have been registered with a proof-of-possession, which is not
+// verified here.
+package bls
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/uints"
+)
+
+const (
+	// DSTMinPk is the domain separation tag used to hash the messages to G2
+	// in the min-pk variant (proof-of-possession ciphersuite).
+	DSTMinPk = "BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_"
+	// DSTMinSig is the domain separation tag used to hash the messages to G1
+	// in the min-sig variant (proof-of-possession ciphersuite).
+	DSTMinSig = "BLS_SIG_BLS12381G1_XMD:SHA-256_SSWU_RO_POP_"
+	// DSTMinPkBN254 is the domain separation tag used to hash the messages to
+	// G2 of BN254 in the min-pk variant.
+	DSTMinPkBN254 = "BLS_SIG_BN254G2_XMD:SHA-256_SVDW_RO_POP_"
+	// DSTMinSigBN254 is the domain separation tag used to hash the messages to
+	// G1 of BN254 in the min-sig variant.
+	DSTMinSigBN254 = "BLS_SIG_BN254G1_XMD:SHA-256_SVDW_RO_POP_"
+)
+
+// Message converts the bytes of a message to the in-circuit representation
+// expected by the BLS12-381 verification functions.
+func Message(msg []byte) []uints.U8 {
+	return uints.NewU8Array(msg)
+}
+
+// Bitmap returns the in-circuit bitmap of n public keys where the keys at the
+// provided indexes are set as signers.
+func Bitmap(n int, signers ...int) []frontend.Variable {
+	bitmap := make([]frontend.Variable, n)
+	for i := range bitmap {
+		bitmap[i] = 0
+	}
+	for _, i := range signers {
+		bitmap[i] = 1
+	}
+	return bitmap
+}
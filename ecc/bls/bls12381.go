@@ -0,0 +1,173 @@
+package bls
+
+import (
+	"fmt"
+
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bls12381"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_emulated"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/std/math/uints"
+)
+
+// VerifyMinPk asserts that the signature sig (G2) is valid for the message
+// and the public key pk (G1) provided, in the BLS12-381 min-pk variant. The
+// message is hashed to G2 in-circuit using DSTMinPk.
+func VerifyMinPk(api frontend.API, pk *sw_bls12381.G1Affine, sig *sw_bls12381.G2Affine, msg []uints.U8) error {
+	g2, err := sw_bls12381.NewG2(api)
+	if err != nil {
+		return fmt.Errorf("error initializing G2: %w", err)
+	}
+	h, err := g2.HashToG2(msg, []byte(DSTMinPk))
+	if err != nil {
+		return fmt.Errorf("error hashing message to G2: %w", err)
+	}
+	return VerifyMinPkPrehashed(api, pk, sig, h)
+}
+
+// VerifyMinPkPrehashed asserts that the signature sig (G2) is valid for the
+// message already hashed to G2 and the public key pk (G1) provided, in the
+// BLS12-381 min-pk variant. It checks that e(-G1, sig) * e(pk, h) == 1.
+func VerifyMinPkPrehashed(api frontend.API, pk *sw_bls12381.G1Affine, sig, h *sw_bls12381.G2Affine) error {
+	pairing, err := sw_bls12381.NewPairing(api)
+	if err != nil {
+		return fmt.Errorf("error initializing pairing: %w", err)
+	}
+	fp, err := emulated.NewField[sw_bls12381.BaseField](api)
+	if err != nil {
+		return err
+	}
+	// the public key can not be the infinity point
+	api.AssertIsEqual(api.And(fp.IsZero(&pk.X), fp.IsZero(&pk.Y)), 0)
+	// prime order subgroup checks
+	pairing.AssertIsOnG1(pk)
+	pairing.AssertIsOnG2(sig)
+
+	_, _, g1Gen, _ := bls12381.Generators()
+	var g1GenNeg bls12381.G1Affine
+	g1GenNeg.Neg(&g1Gen)
+	g1GN := sw_bls12381.NewG1Affine(g1GenNeg)
+	if err := pairing.PairingCheck(
+		[]*sw_bls12381.G1Affine{&g1GN, pk},
+		[]*sw_bls12381.G2Affine{sig, h}); err != nil {
+		return fmt.Errorf("error checking pairing: %w", err)
+	}
+	return nil
+}
+
+// VerifyMinSig asserts that the signature sig (G1) is valid for the message
+// and the public key pk (G2) provided, in the BLS12-381 min-sig variant. The
+// message is hashed to G1 in-circuit using DSTMinSig.
+func VerifyMinSig(api frontend.API, pk *sw_bls12381.G2Affine, sig *sw_bls12381.G1Affine, msg []uints.U8) error {
+	g1, err := sw_bls12381.NewG1(api)
+	if err != nil {
+		return fmt.Errorf("error initializing G1: %w", err)
+	}
+	h, err := g1.HashToG1(msg, []byte(DSTMinSig))
+	if err != nil {
+		return fmt.Errorf("error hashing message to G1: %w", err)
+	}
+	return VerifyMinSigPrehashed(api, pk, sig, h)
+}
+
+// VerifyMinSigPrehashed asserts that the signature sig (G1) is valid for the
+// message already hashed to G1 and the public key pk (G2) provided, in the
+// BLS12-381 min-sig variant. It checks that e(sig, -G2) * e(h, pk) == 1.
+func VerifyMinSigPrehashed(api frontend.API, pk *sw_bls12381.G2Affine, sig, h *sw_bls12381.G1Affine) error {
+	pairing, err := sw_bls12381.NewPairing(api)
+	if err != nil {
+		return fmt.Errorf("error initializing pairing: %w", err)
+	}
+	g2, err := sw_bls12381.NewG2(api)
+	if err != nil {
+		return fmt.Errorf("error initializing G2: %w", err)
+	}
+	// the public key can not be the infinity point
+	infinity := sw_bls12381.NewG2Affine(bls12381.G2Affine{})
+	api.AssertIsEqual(g2.IsEqual(pk, &infinity), 0)
+	// prime order subgroup checks
+	pairing.AssertIsOnG2(pk)
+	pairing.AssertIsOnG1(sig)
+
+	_, _, _, g2Gen := bls12381.Generators()
+	var g2GenNeg bls12381.G2Affine
+	g2GenNeg.Neg(&g2Gen)
+	g2GN := sw_bls12381.NewG2Affine(g2GenNeg)
+	if err := pairing.PairingCheck(
+		[]*sw_bls12381.G1Affine{sig, h},
+		[]*sw_bls12381.G2Affine{&g2GN, pk}); err != nil {
+		return fmt.Errorf("error checking pairing: %w", err)
+	}
+	return nil
+}
+
+// AggregateMinPk returns the sum of the public keys (G1) selected by the
+// bitmap provided, in the BLS12-381 min-pk variant. The bitmap must have the
+// same length as the public keys and every value is asserted to be boolean.
+// If no key is selected, the result is the infinity point (0, 0), which is
+// rejected by the verification.
+func AggregateMinPk(api frontend.API, pks []sw_bls12381.G1Affine, bitmap []frontend.Variable) (*sw_bls12381.G1Affine, error) {
+	if len(pks) != len(bitmap) {
+		return nil, fmt.Errorf("bitmap length mismatch: %d public keys, %d bits", len(pks), len(bitmap))
+	}
+	curve, err := sw_emulated.New[sw_bls12381.BaseField, sw_bls12381.ScalarField](api, sw_emulated.GetBLS12381Params())
+	if err != nil {
+		return nil, fmt.Errorf("error initializing G1: %w", err)
+	}
+	infinity := sw_bls12381.NewG1Affine(bls12381.G1Affine{})
+	agg := &infinity
+	for i := range pks {
+		api.AssertIsBoolean(bitmap[i])
+		agg = curve.AddUnified(agg, curve.Select(bitmap[i], &pks[i], &infinity))
+	}
+	return agg, nil
+}
+
+// AggregateMinSig returns the sum of the public keys (G2) selected by the
+// bitmap provided, in the BLS12-381 min-sig variant. The bitmap must have the
+// same length as the public keys and every value is asserted to be boolean.
+// If no key is selected, the result is the infinity point (0, 0), which is
+// rejected by the verification.
+func AggregateMinSig(api frontend.API, pks []sw_bls12381.G2Affine, bitmap []frontend.Variable) (*sw_bls12381.G2Affine, error) {
+	if len(pks) != len(bitmap) {
+		return nil, fmt.Errorf("bitmap length mismatch: %d public keys, %d bits", len(pks), len(bitmap))
+	}
+	g2, err := sw_bls12381.NewG2(api)
+	if err != nil {
+		return nil, fmt.Errorf("error initializing G2: %w", err)
+	}
+	infinity := sw_bls12381.NewG2Affine(bls12381.G2Affine{})
+	agg := &infinity
+	for i := range pks {
+		api.AssertIsBoolean(bitmap[i])
+		agg = g2.AddUnified(agg, g2.Select(bitmap[i], &pks[i], &infinity))
+	}
+	return agg, nil
+}
+
+// VerifyAggregatedMinPk asserts that the aggregated signature sig (G2) is
+// valid for the message and the public keys (G1) selected by the bitmap, in
+// the BLS12-381 min-pk variant.
+func VerifyAggregatedMinPk(api frontend.API, pks []sw_bls12381.G1Affine, bitmap []frontend.Variable,
+	sig *sw_bls12381.G2Affine, msg []uints.U8,
+) error {
+	aggPk, err := AggregateMinPk(api, pks, bitmap)
+	if err != nil {
+		return err
+	}
+	return VerifyMinPk(api, aggPk, sig, msg)
+}
+
+// VerifyAggregatedMinSig asserts that the aggregated signature sig (G1) is
+// valid for the message and the public keys (G2) selected by the bitmap, in
+// the BLS12-381 min-sig variant.
+func VerifyAggregatedMinSig(api frontend.API, pks []sw_bls12381.G2Affine, bitmap []frontend.Variable,
+	sig *sw_bls12381.G1Affine, msg []uints.U8,
+) error {
+	aggPk, err := AggregateMinSig(api, pks, bitmap)
+	if err != nil {
+		return err
+	}
+	return VerifyMinSig(api, aggPk, sig, msg)
+}
@@ -0,0 +1,201 @@
+package bls
+
+import (
+	"encoding/hex"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/profile"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bls12381"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bn254"
+	"github.com/consensys/gnark/std/math/uints"
+	"github.com/consensys/gnark/test"
+	qt "github.com/frankban/quicktest"
+)
+
+type testMinPkCircuit struct {
+	PublicKey sw_bls12381.G1Affine
+	Signature sw_bls12381.G2Affine
+	Message   []uints.U8
+}
+
+func (c *testMinPkCircuit) Define(api frontend.API) error {
+	return VerifyMinPk(api, &c.PublicKey, &c.Signature, c.Message)
+}
+
+func TestVerifyMinPk(t *testing.T) {
+	c := qt.New(t)
+	// test vector from https://github.com/ethereum/bls12-381-tests (verify)
+	pkBytes, _ := hex.DecodeString("a491d1b0ecd9bb917989f0e74f0dea0422eac4a873e5e2644f368dffb9a6e20fd6e10c1b77654d067c0618f6e5a7f79a")
+	msg, _ := hex.DecodeString("5656565656565656565656565656565656565656565656565656565656565656")
+	sigBytes, _ := hex.DecodeString("882730e5d03f6b42c3abc26d3372625034e1d871b65a8a6b900a56dae22da98abbe1b68f85e49fe7652a55ec3d0591c20767677e33e5cbb1207315c41a9ac03be39c2e7668edc043d6cb1d9fd93033caa8a1c5b0e84bedaeb6c64972503a43eb")
+	var pk bls12381.G1Affine
+	_, err := pk.SetBytes(pkBytes)
+	c.Assert(err, qt.IsNil)
+	var sig bls12381.G2Affine
+	_, err = sig.SetBytes(sigBytes)
+	c.Assert(err, qt.IsNil)
+
+	p := profile.Start()
+	now := time.Now()
+	_, _ = frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &testMinPkCircuit{
+		Message: make([]uints.U8, len(msg)),
+	})
+	fmt.Println("elapsed", time.Since(now))
+	p.Stop()
+	fmt.Println("constrains", p.NbConstraints())
+
+	witness := &testMinPkCircuit{
+		PublicKey: sw_bls12381.NewG1Affine(pk),
+		Signature: sw_bls12381.NewG2Affine(sig),
+		Message:   Message(msg),
+	}
+	// a different message must fail
+	wrongMsg := append([]byte{}, msg...)
+	wrongMsg[0] ^= 1
+	invalid := *witness
+	invalid.Message = Message(wrongMsg)
+
+	placeholder := &testMinPkCircuit{Message: make([]uints.U8, len(msg))}
+	c.Assert(test.IsSolved(placeholder, witness, ecc.BN254.ScalarField()), qt.IsNil)
+	c.Assert(test.IsSolved(placeholder, &invalid, ecc.BN254.ScalarField()), qt.IsNotNil)
+}
+
+type testAggregatedMinPkCircuit struct {
+	PublicKeys [4]sw_bls12381.G1Affine
+	Bitmap     [4]frontend.Variable
+	Signature  sw_bls12381.G2Affine
+	Message    [32]uints.U8
+}
+
+func (c *testAggregatedMinPkCircuit) Define(api frontend.API) error {
+	return VerifyAggregatedMinPk(api, c.PublicKeys[:], c.Bitmap[:], &c.Signature, c.Message[:])
+}
+
+func TestVerifyAggregatedMinPk(t *testing.T) {
+	c := qt.New(t)
+	msg := []byte("batch root of the sequencer 0001")
+	var witness testAggregatedMinPkCircuit
+	var sigs []bls12381.G2Affine
+	for i := range witness.PublicKeys {
+		sk, err := GenerateKey()
+		c.Assert(err, qt.IsNil)
+		witness.PublicKeys[i] = sw_bls12381.NewG1Affine(PublicKeyMinPk(sk))
+		// the second key does not sign
+		if i == 1 {
+			continue
+		}
+		sig, err := SignMinPk(sk, msg)
+		c.Assert(err, qt.IsNil)
+		sigs = append(sigs, sig)
+	}
+	copy(witness.Bitmap[:], Bitmap(4, 0, 2, 3))
+	witness.Signature = sw_bls12381.NewG2Affine(AggregateSignaturesMinPk(sigs...))
+	copy(witness.Message[:], Message(msg))
+	// the bitmap must match the signers
+	invalid := witness
+	copy(invalid.Bitmap[:], Bitmap(4, 0, 1, 2, 3))
+
+	c.Assert(test.IsSolved(&testAggregatedMinPkCircuit{}, &witness, ecc.BN254.ScalarField()), qt.IsNil)
+	c.Assert(test.IsSolved(&testAggregatedMinPkCircuit{}, &invalid, ecc.BN254.ScalarField()), qt.IsNotNil)
+}
+
+type testAggregatedMinSigCircuit struct {
+	PublicKeys [3]sw_bls12381.G2Affine
+	Bitmap     [3]frontend.Variable
+	Signature  sw_bls12381.G1Affine
+	Message    [32]uints.U8
+}
+
+func (c *testAggregatedMinSigCircuit) Define(api frontend.API) error {
+	return VerifyAggregatedMinSig(api, c.PublicKeys[:], c.Bitmap[:], &c.Signature, c.Message[:])
+}
+
+func TestVerifyAggregatedMinSig(t *testing.T) {
+	c := qt.New(t)
+	msg := []byte("batch root of the sequencer 0002")
+	var witness testAggregatedMinSigCircuit
+	var sigs []bls12381.G1Affine
+	for i := range witness.PublicKeys {
+		sk, err := GenerateKey()
+		c.Assert(err, qt.IsNil)
+		witness.PublicKeys[i] = sw_bls12381.NewG2Affine(PublicKeyMinSig(sk))
+		// the last key does not sign
+		if i == 2 {
+			continue
+		}
+		sig, err := SignMinSig(sk, msg)
+		c.Assert(err, qt.IsNil)
+		sigs = append(sigs, sig)
+	}
+	copy(witness.Bitmap[:], Bitmap(3, 0, 1))
+	witness.Signature = sw_bls12381.NewG1Affine(AggregateSignaturesMinSig(sigs...))
+	copy(witness.Message[:], Message(msg))
+	// no signers must fail
+	invalid := witness
+	copy(invalid.Bitmap[:], Bitmap(3))
+
+	c.Assert(test.IsSolved(&testAggregatedMinSigCircuit{}, &witness, ecc.BN254.ScalarField()), qt.IsNil)
+	c.Assert(test.IsSolved(&testAggregatedMinSigCircuit{}, &invalid, ecc.BN254.ScalarField()), qt.IsNotNil)
+}
+
+type testBN254Circuit struct {
+	PublicKeys   [3]sw_bn254.G1Affine
+	Bitmap       [3]frontend.Variable
+	Signature    sw_bn254.G2Affine
+	Hash         sw_bn254.G2Affine
+	MinSigPubKey sw_bn254.G2Affine
+	MinSigSig    sw_bn254.G1Affine
+	MinSigHash   sw_bn254.G1Affine
+}
+
+func (c *testBN254Circuit) Define(api frontend.API) error {
+	if err := VerifyAggregatedMinPkBN254(api, c.PublicKeys[:], c.Bitmap[:], &c.Signature, &c.Hash); err != nil {
+		return err
+	}
+	return VerifyMinSigBN254(api, &c.MinSigPubKey, &c.MinSigSig, &c.MinSigHash)
+}
+
+func TestVerifyBN254(t *testing.T) {
+	c := qt.New(t)
+	msg := []byte("batch root of the sequencer 0003")
+	var witness testBN254Circuit
+	var sigs []bn254.G2Affine
+	for i := range witness.PublicKeys {
+		sk, err := GenerateKeyBN254()
+		c.Assert(err, qt.IsNil)
+		witness.PublicKeys[i] = sw_bn254.NewG1Affine(PublicKeyMinPkBN254(sk))
+		sig, err := SignMinPkBN254(sk, msg)
+		c.Assert(err, qt.IsNil)
+		sigs = append(sigs, sig)
+	}
+	copy(witness.Bitmap[:], Bitmap(3, 0, 1, 2))
+	witness.Signature = sw_bn254.NewG2Affine(AggregateSignaturesMinPkBN254(sigs...))
+	h, err := HashMinPkBN254(msg)
+	c.Assert(err, qt.IsNil)
+	witness.Hash = sw_bn254.NewG2Affine(h)
+	// min-sig
+	sk, err := GenerateKeyBN254()
+	c.Assert(err, qt.IsNil)
+	witness.MinSigPubKey = sw_bn254.NewG2Affine(PublicKeyMinSigBN254(sk))
+	minSig, err := SignMinSigBN254(sk, msg)
+	c.Assert(err, qt.IsNil)
+	witness.MinSigSig = sw_bn254.NewG1Affine(minSig)
+	hG1, err := HashMinSigBN254(msg)
+	c.Assert(err, qt.IsNil)
+	witness.MinSigHash = sw_bn254.NewG1Affine(hG1)
+	// a different hashed message must fail
+	invalid := witness
+	otherHash, err := HashMinPkBN254([]byte("other message"))
+	c.Assert(err, qt.IsNil)
+	invalid.Hash = sw_bn254.NewG2Affine(otherHash)
+
+	c.Assert(test.IsSolved(&testBN254Circuit{}, &witness, ecc.BN254.ScalarField()), qt.IsNil)
+	c.Assert(test.IsSolved(&testBN254Circuit{}, &invalid, ecc.BN254.ScalarField()), qt.IsNotNil)
+}
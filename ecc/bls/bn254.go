@@ -0,0 +1,108 @@
+package bls
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bn254"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_emulated"
+	"github.com/consensys/gnark/std/math/emulated"
+)
+
+// VerifyMinPkBN254 asserts that the signature sig (G2) is valid for the
+// message already hashed to G2 (see HashMinPkBN254) and the public key pk
+// (G1) provided, in the BN254 min-pk variant. It checks that
+// e(-G1, sig) * e(pk, h) == 1.
+func VerifyMinPkBN254(api frontend.API, pk *sw_bn254.G1Affine, sig, h *sw_bn254.G2Affine) error {
+	pairing, err := sw_bn254.NewPairing(api)
+	if err != nil {
+		return fmt.Errorf("error initializing pairing: %w", err)
+	}
+	fp, err := emulated.NewField[sw_bn254.BaseField](api)
+	if err != nil {
+		return err
+	}
+	// the public key can not be the infinity point
+	api.AssertIsEqual(api.And(fp.IsZero(&pk.X), fp.IsZero(&pk.Y)), 0)
+	// G1 has prime order, the G2 subgroup is checked by the pairing check
+	pairing.AssertIsOnG1(pk)
+
+	_, _, g1Gen, _ := bn254.Generators()
+	var g1GenNeg bn254.G1Affine
+	g1GenNeg.Neg(&g1Gen)
+	g1GN := sw_bn254.NewG1Affine(g1GenNeg)
+	if err := pairing.PairingCheck(
+		[]*sw_bn254.G1Affine{&g1GN, pk},
+		[]*sw_bn254.G2Affine{sig, h}); err != nil {
+		return fmt.Errorf("error checking pairing: %w", err)
+	}
+	return nil
+}
+
+// VerifyMinSigBN254 asserts that the signature sig (G1) is valid for the
+// message already hashed to G1 (see HashMinSigBN254) and the public key pk
+// (G2) provided, in the BN254 min-sig variant. It checks that
+// e(sig, -G2) * e(h, pk) == 1.
+func VerifyMinSigBN254(api frontend.API, pk *sw_bn254.G2Affine, sig, h *sw_bn254.G1Affine) error {
+	pairing, err := sw_bn254.NewPairing(api)
+	if err != nil {
+		return fmt.Errorf("error initializing pairing: %w", err)
+	}
+	// the public key can not be the infinity point, the G2 subgroup is
+	// checked by the pairing check
+	g2, err := sw_bn254.NewG2(api)
+	if err != nil {
+		return fmt.Errorf("error initializing G2: %w", err)
+	}
+	infinity := sw_bn254.NewG2Affine(bn254.G2Affine{})
+	api.AssertIsEqual(g2.IsEqual(pk, &infinity), 0)
+	pairing.AssertIsOnG1(sig)
+
+	_, _, _, g2Gen := bn254.Generators()
+	var g2GenNeg bn254.G2Affine
+	g2GenNeg.Neg(&g2Gen)
+	g2GN := sw_bn254.NewG2Affine(g2GenNeg)
+	if err := pairing.PairingCheck(
+		[]*sw_bn254.G1Affine{sig, h},
+		[]*sw_bn254.G2Affine{&g2GN, pk}); err != nil {
+		return fmt.Errorf("error checking pairing: %w", err)
+	}
+	return nil
+}
+
+// AggregateMinPkBN254 returns the sum of the public keys (G1) selected by the
+// bitmap provided, in the BN254 min-pk variant. The bitmap must have the same
+// length as the public keys and every value is asserted to be boolean. If no
+// key is selected, the result is the infinity point (0, 0), which is rejected
+// by the verification. The aggregation of G2 public keys (min-sig) is not
+// supported for BN254 because gnark does not expose the G2 addition.
+func AggregateMinPkBN254(api frontend.API, pks []sw_bn254.G1Affine, bitmap []frontend.Variable) (*sw_bn254.G1Affine, error) {
+	if len(pks) != len(bitmap) {
+		return nil, fmt.Errorf("bitmap length mismatch: %d public keys, %d bits", len(pks), len(bitmap))
+	}
+	curve, err := sw_emulated.New[sw_bn254.BaseField, sw_bn254.ScalarField](api, sw_emulated.GetBN254Params())
+	if err != nil {
+		return nil, fmt.Errorf("error initializing G1: %w", err)
+	}
+	infinity := sw_bn254.NewG1Affine(bn254.G1Affine{})
+	agg := &infinity
+	for i := range pks {
+		api.AssertIsBoolean(bitmap[i])
+		agg = curve.AddUnified(agg, curve.Select(bitmap[i], &pks[i], &infinity))
+	}
+	return agg, nil
+}
+
+// VerifyAggregatedMinPkBN254 asserts that the aggregated signature sig (G2)
+// is valid for the message already hashed to G2 and the public keys (G1)
+// selected by the bitmap, in the BN254 min-pk variant.
+func VerifyAggregatedMinPkBN254(api frontend.API, pks []sw_bn254.G1Affine, bitmap []frontend.Variable,
+	sig, h *sw_bn254.G2Affine,
+) error {
+	aggPk, err := AggregateMinPkBN254(api, pks, bitmap)
+	if err != nil {
+		return err
+	}
+	return VerifyMinPkBN254(api, aggPk, sig, h)
+}
@@ -0,0 +1,162 @@
+package bls
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	fr_bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	fr_bn254 "github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+// GenerateKey returns a new random BLS12-381 private key.
+func GenerateKey() (*big.Int, error) {
+	return randomScalar(fr_bls12381.Modulus())
+}
+
+// GenerateKeyBN254 returns a new random BN254 private key.
+func GenerateKeyBN254() (*big.Int, error) {
+	return randomScalar(fr_bn254.Modulus())
+}
+
+func randomScalar(order *big.Int) (*big.Int, error) {
+	for {
+		k, err := rand.Int(rand.Reader, order)
+		if err != nil {
+			return nil, fmt.Errorf("error generating random scalar: %w", err)
+		}
+		if k.Sign() != 0 {
+			return k, nil
+		}
+	}
+}
+
+// PublicKeyMinPk returns the BLS12-381 min-pk public key (G1) of the private
+// key provided.
+func PublicKeyMinPk(sk *big.Int) bls12381.G1Affine {
+	var pk bls12381.G1Affine
+	pk.ScalarMultiplicationBase(sk)
+	return pk
+}
+
+// SignMinPk returns the BLS12-381 min-pk signature (G2) of the message with
+// the private key provided, hashing the message to G2 with DSTMinPk.
+func SignMinPk(sk *big.Int, msg []byte) (bls12381.G2Affine, error) {
+	h, err := bls12381.HashToG2(msg, []byte(DSTMinPk))
+	if err != nil {
+		return bls12381.G2Affine{}, err
+	}
+	var sig bls12381.G2Affine
+	sig.ScalarMultiplication(&h, sk)
+	return sig, nil
+}
+
+// AggregateSignaturesMinPk returns the sum of the BLS12-381 min-pk signatures
+// provided.
+func AggregateSignaturesMinPk(sigs ...bls12381.G2Affine) bls12381.G2Affine {
+	var agg bls12381.G2Jac
+	for i := range sigs {
+		agg.AddMixed(&sigs[i])
+	}
+	var res bls12381.G2Affine
+	res.FromJacobian(&agg)
+	return res
+}
+
+// PublicKeyMinSig returns the BLS12-381 min-sig public key (G2) of the
+// private key provided.
+func PublicKeyMinSig(sk *big.Int) bls12381.G2Affine {
+	var pk bls12381.G2Affine
+	pk.ScalarMultiplicationBase(sk)
+	return pk
+}
+
+// SignMinSig returns the BLS12-381 min-sig signature (G1) of the message with
+// the private key provided, hashing the message to G1 with DSTMinSig.
+func SignMinSig(sk *big.Int, msg []byte) (bls12381.G1Affine, error) {
+	h, err := bls12381.HashToG1(msg, []byte(DSTMinSig))
+	if err != nil {
+		return bls12381.G1Affine{}, err
+	}
+	var sig bls12381.G1Affine
+	sig.ScalarMultiplication(&h, sk)
+	return sig, nil
+}
+
+// AggregateSignaturesMinSig returns the sum of the BLS12-381 min-sig
+// signatures provided.
+func AggregateSignaturesMinSig(sigs ...bls12381.G1Affine) bls12381.G1Affine {
+	var agg bls12381.G1Jac
+	for i := range sigs {
+		agg.AddMixed(&sigs[i])
+	}
+	var res bls12381.G1Affine
+	res.FromJacobian(&agg)
+	return res
+}
+
+// HashMinPkBN254 hashes the message to G2 of BN254 with DSTMinPkBN254, to be
+// used as the prehashed message of the BN254 min-pk verification.
+func HashMinPkBN254(msg []byte) (bn254.G2Affine, error) {
+	return bn254.HashToG2(msg, []byte(DSTMinPkBN254))
+}
+
+// PublicKeyMinPkBN254 returns the BN254 min-pk public key (G1) of the private
+// key provided.
+func PublicKeyMinPkBN254(sk *big.Int) bn254.G1Affine {
+	var pk bn254.G1Affine
+	pk.ScalarMultiplicationBase(sk)
+	return pk
+}
+
+// SignMinPkBN254 returns the BN254 min-pk signature (G2) of the message with
+// the private key provided.
+func SignMinPkBN254(sk *big.Int, msg []byte) (bn254.G2Affine, error) {
+	h, err := HashMinPkBN254(msg)
+	if err != nil {
+		return bn254.G2Affine{}, err
+	}
+	var sig bn254.G2Affine
+	sig.ScalarMultiplication(&h, sk)
+	return sig, nil
+}
+
+// AggregateSignaturesMinPkBN254 returns the sum of the BN254 min-pk
+// signatures provided.
+func AggregateSignaturesMinPkBN254(sigs ...bn254.G2Affine) bn254.G2Affine {
+	var agg bn254.G2Jac
+	for i := range sigs {
+		agg.AddMixed(&sigs[i])
+	}
+	var res bn254.G2Affine
+	res.FromJacobian(&agg)
+	return res
+}
+
+// HashMinSigBN254 hashes the message to G1 of BN254 with DSTMinSigBN254, to
+// be used as the prehashed message of the BN254 min-sig verification.
+func HashMinSigBN254(msg []byte) (bn254.G1Affine, error) {
+	return bn254.HashToG1(msg, []byte(DSTMinSigBN254))
+}
+
+// PublicKeyMinSigBN254 returns the BN254 min-sig public key (G2) of the
+// private key provided.
+func PublicKeyMinSigBN254(sk *big.Int) bn254.G2Affine {
+	var pk bn254.G2Affine
+	pk.ScalarMultiplicationBase(sk)
+	return pk
+}
+
+// SignMinSigBN254 returns the BN254 min-sig signature (G1) of the message
+// with the private key provided.
+func SignMinSigBN254(sk *big.Int, msg []byte) (bn254.G1Affine, error) {
+	h, err := HashMinSigBN254(msg)
+	if err != nil {
+		return bn254.G1Affine{}, err
+	}
+	var sig bn254.G1Affine
+	sig.ScalarMultiplication(&h, sk)
+	return sig, nil
+}
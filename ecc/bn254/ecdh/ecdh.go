@@ -0,0 +1,69 @@
+// ecdh package contains the implementation of the Elliptic Curve
+// Diffie-Hellman key agreement over BabyJubJub in Gnark, and a stealth
+// address scheme built on top of it.
+//
+// The in-circuit functions receive and return points in Reduced
+// TwistedEdwards format (Gnark format), like the elgamal package, while the
+// native functions work with Iden3 points in TwistedEdwards format. The base
+// point is B8 in both cases, and the private keys are scalars (for Iden3 keys,
+// the output of babyjub.PrivateKey.Scalar).
+package ecdh
+
+import (
+	"math/big"
+
+	ecc_tweds "github.com/consensys/gnark-crypto/ecc/twistededwards"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/native/twistededwards"
+	"github.com/iden3/go-iden3-crypto/babyjub"
+	"github.com/vocdoni/gnark-crypto-primitives/ecc/format"
+	"github.com/vocdoni/gnark-crypto-primitives/elgamal"
+)
+
+// SharedSecret computes the shared secret [privKey] * pubKey, with the
+// public key and the result in Reduced TwistedEdwards format. It asserts that
+// the public key is on the curve.
+func SharedSecret(api frontend.API, privKey frontend.Variable, pubKey twistededwards.Point) (twistededwards.Point, error) {
+	curve, err := twistededwards.NewEdCurve(api, ecc_tweds.BN254)
+	if err != nil {
+		return twistededwards.Point{}, err
+	}
+	curve.AssertIsOnCurve(pubKey)
+	return curve.ScalarMul(pubKey, privKey), nil
+}
+
+// SharedSecretTE computes the shared secret [privKey] * pubKey, with the
+// public key and the result in TwistedEdwards format (Iden3 format).
+func SharedSecretTE(api frontend.API, privKey frontend.Variable, pubKey twistededwards.Point) (twistededwards.Point, error) {
+	x, y := format.FromTEtoRTE(api, pubKey.X, pubKey.Y)
+	shared, err := SharedSecret(api, privKey, twistededwards.Point{X: x, Y: y})
+	if err != nil {
+		return twistededwards.Point{}, err
+	}
+	x, y = format.FromRTEtoTE(api, shared.X, shared.Y)
+	return twistededwards.Point{X: x, Y: y}, nil
+}
+
+// AssertSharedSecret proves the knowledge of the shared secret between the
+// key pair (privKey, pubKey) and the peer public key, that is, it asserts
+// that pubKey = [privKey] * G and shared = [privKey] * peerPubKey. All the
+// points are in Reduced TwistedEdwards format.
+func AssertSharedSecret(api frontend.API, privKey frontend.Variable, pubKey, peerPubKey, shared twistededwards.Point) error {
+	expected, err := SharedSecret(api, privKey, peerPubKey)
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(expected.X, shared.X)
+	api.AssertIsEqual(expected.Y, shared.Y)
+	// pubKey = [privKey] * G
+	derived := elgamal.FixedBaseScalarMulBN254(api, privKey)
+	api.AssertIsEqual(derived.X, pubKey.X)
+	api.AssertIsEqual(derived.Y, pubKey.Y)
+	return nil
+}
+
+// ComputeSharedSecret computes natively the shared secret [privKey] * pubKey,
+// which is the value that SharedSecretTE returns in the circuit.
+func ComputeSharedSecret(privKey *big.Int, pubKey *babyjub.PublicKey) *babyjub.Point {
+	return babyjub.NewPoint().Mul(privKey, pubKey.Point())
+}
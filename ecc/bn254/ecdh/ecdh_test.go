@@ -0,0 +1,105 @@
+package ecdh
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/profile"
+	"github.com/consensys/gnark/std/algebra/native/twistededwards"
+	"github.com/consensys/gnark/test"
+	qt "github.com/frankban/quicktest"
+	"github.com/iden3/go-iden3-crypto/babyjub"
+	"github.com/vocdoni/davinci-node/crypto/ecc/format"
+)
+
+type testECDHCircuit struct {
+	PrivKey    frontend.Variable
+	PubKey     twistededwards.Point `gnark:",public"`
+	PeerPubKey twistededwards.Point `gnark:",public"`
+	Shared     twistededwards.Point
+	// the peer public key and the shared secret in TwistedEdwards format
+	PeerPubKeyTE twistededwards.Point
+	SharedTE     twistededwards.Point
+}
+
+func (c *testECDHCircuit) Define(api frontend.API) error {
+	if err := AssertSharedSecret(api, c.PrivKey, c.PubKey, c.PeerPubKey, c.Shared); err != nil {
+		return err
+	}
+	sharedTE, err := SharedSecretTE(api, c.PrivKey, c.PeerPubKeyTE)
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(sharedTE.X, c.SharedTE.X)
+	api.AssertIsEqual(sharedTE.Y, c.SharedTE.Y)
+	return nil
+}
+
+// generateKeyPair returns a random BabyJubJub private scalar and its public
+// key.
+func generateKeyPair() (*big.Int, *babyjub.PublicKey, error) {
+	privKey, err := rand.Int(rand.Reader, babyjub.SubOrder)
+	if err != nil {
+		return nil, nil, err
+	}
+	return privKey, (*babyjub.PublicKey)(babyjub.NewPoint().Mul(privKey, babyjub.B8)), nil
+}
+
+// toRTE converts a point in TwistedEdwards format to a circuit point in
+// Reduced TwistedEdwards format.
+func toRTE(p *babyjub.Point) twistededwards.Point {
+	x, y := format.FromTEtoRTE(p.X, p.Y)
+	return twistededwards.Point{X: x, Y: y}
+}
+
+func TestSharedSecret(t *testing.T) {
+	c := qt.New(t)
+	// both parties compute the same shared secret
+	privKey, pubKey, err := generateKeyPair()
+	c.Assert(err, qt.IsNil)
+	peerPrivKey, peerPubKey, err := generateKeyPair()
+	c.Assert(err, qt.IsNil)
+	shared := ComputeSharedSecret(privKey, peerPubKey)
+	c.Assert(shared.Compress(), qt.Equals, ComputeSharedSecret(peerPrivKey, pubKey).Compress())
+	// profiling the circuit compilation
+	p := profile.Start()
+	now := time.Now()
+	_, _ = frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &testECDHCircuit{})
+	fmt.Println("elapsed", time.Since(now))
+	p.Stop()
+	fmt.Println("constrains", p.NbConstraints())
+
+	witness := &testECDHCircuit{
+		PrivKey:      privKey,
+		PubKey:       toRTE(pubKey.Point()),
+		PeerPubKey:   toRTE(peerPubKey.Point()),
+		Shared:       toRTE(shared),
+		PeerPubKeyTE: twistededwards.Point{X: peerPubKey.X, Y: peerPubKey.Y},
+		SharedTE:     twistededwards.Point{X: shared.X, Y: shared.Y},
+	}
+	// the shared secret of other key pair must fail
+	otherPrivKey, otherPubKey, err := generateKeyPair()
+	c.Assert(err, qt.IsNil)
+	otherShared := ComputeSharedSecret(otherPrivKey, peerPubKey)
+	invalid := *witness
+	invalid.Shared = toRTE(otherShared)
+	// the private key of other public key must fail
+	invalidKey := *witness
+	invalidKey.PubKey = toRTE(otherPubKey.Point())
+
+	assert := test.NewAssert(t)
+	assert.CheckCircuit(&testECDHCircuit{},
+		test.WithValidAssignment(witness),
+		test.WithInvalidAssignment(&invalid),
+		test.WithInvalidAssignment(&invalidKey),
+		test.WithCurves(ecc.BN254),
+		test.WithBackends(backend.GROTH16),
+	)
+}
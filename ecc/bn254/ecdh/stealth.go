@@ -0,0 +1,117 @@
+package ecdh
+
+import (
+	"math/big"
+
+	ecc_tweds "github.com/consensys/gnark-crypto/ecc/twistededwards"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/native/twistededwards"
+	"github.com/iden3/go-iden3-crypto/babyjub"
+	"github.com/vocdoni/gnark-crypto-primitives/ecc/format"
+	"github.com/vocdoni/gnark-crypto-primitives/elgamal"
+	"github.com/vocdoni/gnark-crypto-primitives/utils"
+)
+
+// The stealth address scheme derives one-time public keys for a recipient
+// that only the recipient can link to its public key and spend:
+//
+//   - The sender picks a random r and publishes the ephemeral key R = [r] * G.
+//   - Both parties compute the shared secret S = [r] * PK = [sk] * R.
+//   - The one-time public key is P' = PK + [H(S)] * G.
+//   - The one-time private key is sk' = sk + H(S) mod l, so P' = [sk'] * G.
+//
+// H is applied to the coordinates of S in TwistedEdwards format (Iden3
+// format), so the tweak does not depend on the representation used by the
+// circuit.
+
+// StealthPublicKey derives the one-time public key for the recipient public
+// key pubKey and the random r, and returns it together with the ephemeral
+// public key that the sender must publish. The points are in Reduced
+// TwistedEdwards format.
+func StealthPublicKey(api frontend.API, hFn utils.Hasher, pubKey twistededwards.Point, r frontend.Variable) (twistededwards.Point, twistededwards.Point, error) {
+	curve, err := twistededwards.NewEdCurve(api, ecc_tweds.BN254)
+	if err != nil {
+		return twistededwards.Point{}, twistededwards.Point{}, err
+	}
+	// R = [r] * G
+	ephemeral := elgamal.FixedBaseScalarMulBN254(api, r)
+	// S = [r] * PK
+	shared, err := SharedSecret(api, r, pubKey)
+	if err != nil {
+		return twistededwards.Point{}, twistededwards.Point{}, err
+	}
+	tweak, err := stealthTweak(api, hFn, shared)
+	if err != nil {
+		return twistededwards.Point{}, twistededwards.Point{}, err
+	}
+	// P' = PK + [H(S)] * G
+	oneTime := curve.Add(pubKey, elgamal.FixedBaseScalarMulBN254(api, tweak))
+	return oneTime, ephemeral, nil
+}
+
+// AssertStealthOwnership proves that the one-time public key oneTime was
+// derived for the public key of privKey with the ephemeral public key
+// provided, that is, it asserts that oneTime = [privKey] * G + [H(S)] * G
+// where S = [privKey] * ephemeral. The points are in Reduced TwistedEdwards
+// format.
+func AssertStealthOwnership(api frontend.API, hFn utils.Hasher, privKey frontend.Variable, ephemeral, oneTime twistededwards.Point) error {
+	curve, err := twistededwards.NewEdCurve(api, ecc_tweds.BN254)
+	if err != nil {
+		return err
+	}
+	curve.AssertIsOnCurve(oneTime)
+	// S = [sk] * R
+	shared, err := SharedSecret(api, privKey, ephemeral)
+	if err != nil {
+		return err
+	}
+	tweak, err := stealthTweak(api, hFn, shared)
+	if err != nil {
+		return err
+	}
+	// [sk] * G + [H(S)] * G
+	expected := curve.Add(
+		elgamal.FixedBaseScalarMulBN254(api, privKey),
+		elgamal.FixedBaseScalarMulBN254(api, tweak),
+	)
+	api.AssertIsEqual(expected.X, oneTime.X)
+	api.AssertIsEqual(expected.Y, oneTime.Y)
+	return nil
+}
+
+// ComputeStealthPublicKey derives natively the one-time public key for the
+// recipient public key pubKey and the random r, and returns it together with
+// the ephemeral public key. It is the value that StealthPublicKey returns in
+// the circuit, in TwistedEdwards format.
+func ComputeStealthPublicKey(hFn utils.NativeHasher, pubKey *babyjub.PublicKey, r *big.Int) (*babyjub.Point, *babyjub.Point, error) {
+	ephemeral := babyjub.NewPoint().Mul(r, babyjub.B8)
+	shared := ComputeSharedSecret(r, pubKey)
+	tweak, err := hFn([]*big.Int{shared.X, shared.Y})
+	if err != nil {
+		return nil, nil, err
+	}
+	tweakPoint := babyjub.NewPoint().Mul(tweak, babyjub.B8)
+	oneTime := babyjub.NewPointProjective().Add(pubKey.Point().Projective(), tweakPoint.Projective()).Affine()
+	return oneTime, ephemeral, nil
+}
+
+// ComputeStealthPrivateKey derives natively the one-time private key of the
+// recipient with private key privKey for the ephemeral public key provided,
+// that is, sk' = sk + H([sk] * R) mod l. The one-time public key is
+// [sk'] * B8.
+func ComputeStealthPrivateKey(hFn utils.NativeHasher, privKey *big.Int, ephemeral *babyjub.Point) (*big.Int, error) {
+	shared := babyjub.NewPoint().Mul(privKey, ephemeral)
+	tweak, err := hFn([]*big.Int{shared.X, shared.Y})
+	if err != nil {
+		return nil, err
+	}
+	oneTimeKey := new(big.Int).Add(privKey, tweak)
+	return oneTimeKey.Mod(oneTimeKey, babyjub.SubOrder), nil
+}
+
+// stealthTweak returns H(S) for the shared secret S in Reduced TwistedEdwards
+// format, hashing its coordinates in TwistedEdwards format.
+func stealthTweak(api frontend.API, hFn utils.Hasher, shared twistededwards.Point) (frontend.Variable, error) {
+	x, y := format.FromRTEtoTE(api, shared.X, shared.Y)
+	return hFn(api, x, y)
+}
@@ -0,0 +1,84 @@
+package ecdh
+
+import (
+	"crypto/rand"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/profile"
+	"github.com/consensys/gnark/std/algebra/native/twistededwards"
+	"github.com/consensys/gnark/test"
+	qt "github.com/frankban/quicktest"
+	"github.com/iden3/go-iden3-crypto/babyjub"
+	"github.com/iden3/go-iden3-crypto/poseidon"
+	"github.com/vocdoni/gnark-crypto-primitives/utils"
+)
+
+type testStealthCircuit struct {
+	PubKey    twistededwards.Point
+	R         frontend.Variable
+	PrivKey   frontend.Variable
+	Ephemeral twistededwards.Point `gnark:",public"`
+	OneTime   twistededwards.Point `gnark:",public"`
+}
+
+func (c *testStealthCircuit) Define(api frontend.API) error {
+	// the sender derives the one-time and ephemeral public keys
+	oneTime, ephemeral, err := StealthPublicKey(api, utils.PoseidonHasher, c.PubKey, c.R)
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(oneTime.X, c.OneTime.X)
+	api.AssertIsEqual(oneTime.Y, c.OneTime.Y)
+	api.AssertIsEqual(ephemeral.X, c.Ephemeral.X)
+	api.AssertIsEqual(ephemeral.Y, c.Ephemeral.Y)
+	// the recipient proves the ownership of the one-time public key
+	return AssertStealthOwnership(api, utils.PoseidonHasher, c.PrivKey, c.Ephemeral, c.OneTime)
+}
+
+func TestStealthAddress(t *testing.T) {
+	c := qt.New(t)
+	privKey, pubKey, err := generateKeyPair()
+	c.Assert(err, qt.IsNil)
+	r, err := rand.Int(rand.Reader, babyjub.SubOrder)
+	c.Assert(err, qt.IsNil)
+	oneTime, ephemeral, err := ComputeStealthPublicKey(poseidon.Hash, pubKey, r)
+	c.Assert(err, qt.IsNil)
+	// the one-time private key matches the one-time public key
+	oneTimeKey, err := ComputeStealthPrivateKey(poseidon.Hash, privKey, ephemeral)
+	c.Assert(err, qt.IsNil)
+	c.Assert(babyjub.NewPoint().Mul(oneTimeKey, babyjub.B8).Compress(), qt.Equals, oneTime.Compress())
+	// profiling the circuit compilation
+	p := profile.Start()
+	now := time.Now()
+	_, _ = frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &testStealthCircuit{})
+	fmt.Println("elapsed", time.Since(now))
+	p.Stop()
+	fmt.Println("constrains", p.NbConstraints())
+
+	witness := &testStealthCircuit{
+		PubKey:    toRTE(pubKey.Point()),
+		R:         r,
+		PrivKey:   privKey,
+		Ephemeral: toRTE(ephemeral),
+		OneTime:   toRTE(oneTime),
+	}
+	// other recipient can not prove the ownership
+	otherPrivKey, _, err := generateKeyPair()
+	c.Assert(err, qt.IsNil)
+	invalid := *witness
+	invalid.PrivKey = otherPrivKey
+
+	assert := test.NewAssert(t)
+	assert.CheckCircuit(&testStealthCircuit{},
+		test.WithValidAssignment(witness),
+		test.WithInvalidAssignment(&invalid),
+		test.WithCurves(ecc.BN254),
+		test.WithBackends(backend.GROTH16),
+	)
+}
@@ -0,0 +1,218 @@
+package eddsa
+
+import (
+	"crypto/sha512"
+	"crypto/subtle"
+	"fmt"
+	"hash"
+	"io"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/bandersnatch"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	"github.com/consensys/gnark-crypto/signature"
+)
+
+// The EdDSA implementation of gnark-crypto for Bandersnatch works over the
+// points of Jubjub (the other twisted edwards curve of BLS12-381), so its
+// signatures are not valid for the Bandersnatch curve of the gnark circuits.
+// The types below implement the same scheme over the Bandersnatch points:
+//
+//	A = [a] * Base, R = [r] * Base, S = r + H(R, A, M) * a mod order
+//
+// with the public key and R serialized in the compressed format of the
+// Bandersnatch points, and the signature as R || S (S in big endian).
+
+const (
+	bandersnatchSizeFr        = fr.Bytes
+	bandersnatchSizePublicKey = bandersnatchSizeFr
+	bandersnatchSizeSignature = 2 * bandersnatchSizeFr
+)
+
+// bandersnatchPublicKey is an EdDSA public key over Bandersnatch.
+type bandersnatchPublicKey struct {
+	A bandersnatch.PointAffine
+}
+
+// bandersnatchPrivateKey is an EdDSA private key over Bandersnatch.
+type bandersnatchPrivateKey struct {
+	PublicKey bandersnatchPublicKey
+	scalar    [bandersnatchSizeFr]byte // secret scalar, in big endian
+	randSrc   [32]byte                 // source of the nonces
+}
+
+// bandersnatchSignature is an EdDSA signature over Bandersnatch.
+type bandersnatchSignature struct {
+	R bandersnatch.PointAffine
+	S [bandersnatchSizeFr]byte
+}
+
+// generateBandersnatchKey generates a new EdDSA private key over Bandersnatch
+// using the randomness source provided.
+func generateBandersnatchKey(r io.Reader) (*bandersnatchPrivateKey, error) {
+	seed := make([]byte, 32)
+	if _, err := io.ReadFull(r, seed); err != nil {
+		return nil, err
+	}
+	h := sha512.Sum512(seed)
+	curve := bandersnatch.GetEdwardsCurve()
+	scalar := new(big.Int).SetBytes(h[:32])
+	scalar.Mod(scalar, &curve.Order)
+	if scalar.Sign() == 0 {
+		return nil, fmt.Errorf("invalid zero private key")
+	}
+	priv := &bandersnatchPrivateKey{}
+	scalar.FillBytes(priv.scalar[:])
+	copy(priv.randSrc[:], h[32:])
+	priv.PublicKey.A.ScalarMultiplication(&curve.Base, scalar)
+	return priv, nil
+}
+
+// Public returns the public key associated to the private key.
+func (priv *bandersnatchPrivateKey) Public() signature.PublicKey {
+	pub := priv.PublicKey
+	return &pub
+}
+
+// Sign signs the message provided, which must be a sequence of field
+// elements, using hFunc to compute H(R, A, M).
+func (priv *bandersnatchPrivateKey) Sign(message []byte, hFunc hash.Hash) ([]byte, error) {
+	if hFunc == nil {
+		return nil, fmt.Errorf("hash function required")
+	}
+	curve := bandersnatch.GetEdwardsCurve()
+	// r = H(randSrc || M) mod order
+	nonce := sha512.Sum512(append(priv.randSrc[:], message...))
+	r := new(big.Int).SetBytes(nonce[:])
+	r.Mod(r, &curve.Order)
+
+	var sig bandersnatchSignature
+	sig.R.ScalarMultiplication(&curve.Base, r)
+	h, err := bandersnatchChallenge(hFunc, &sig.R, &priv.PublicKey.A, message)
+	if err != nil {
+		return nil, err
+	}
+	// S = r + H(R, A, M) * a mod order
+	s := new(big.Int).SetBytes(priv.scalar[:])
+	s.Mul(s, h).Add(s, r).Mod(s, &curve.Order)
+	s.FillBytes(sig.S[:])
+	return sig.Bytes(), nil
+}
+
+// Bytes returns the private key as publicKey || scalar || randSrc.
+func (priv *bandersnatchPrivateKey) Bytes() []byte {
+	res := priv.PublicKey.Bytes()
+	res = append(res, priv.scalar[:]...)
+	return append(res, priv.randSrc[:]...)
+}
+
+// SetBytes sets the private key from publicKey || scalar || randSrc and
+// returns the number of bytes read.
+func (priv *bandersnatchPrivateKey) SetBytes(buf []byte) (int, error) {
+	size := bandersnatchSizePublicKey + bandersnatchSizeFr + 32
+	if len(buf) < size {
+		return 0, io.ErrShortBuffer
+	}
+	n, err := priv.PublicKey.SetBytes(buf)
+	if err != nil {
+		return 0, err
+	}
+	n += copy(priv.scalar[:], buf[n:])
+	n += copy(priv.randSrc[:], buf[n:])
+	return n, nil
+}
+
+// Verify checks natively that the signature is valid for the message
+// provided: cofactor * S * Base == cofactor * (R + H(R, A, M) * A).
+func (pub *bandersnatchPublicKey) Verify(sigBin, message []byte, hFunc hash.Hash) (bool, error) {
+	if hFunc == nil {
+		return false, fmt.Errorf("hash function required")
+	}
+	if !pub.A.IsOnCurve() {
+		return false, fmt.Errorf("public key not on the curve")
+	}
+	var sig bandersnatchSignature
+	if _, err := sig.SetBytes(sigBin); err != nil {
+		return false, err
+	}
+	curve := bandersnatch.GetEdwardsCurve()
+	s := new(big.Int).SetBytes(sig.S[:])
+	if s.Cmp(&curve.Order) >= 0 {
+		return false, nil
+	}
+	h, err := bandersnatchChallenge(hFunc, &sig.R, &pub.A, message)
+	if err != nil {
+		return false, err
+	}
+	cofactor := curve.Cofactor.BigInt(new(big.Int))
+	var lhs, rhs bandersnatch.PointAffine
+	lhs.ScalarMultiplication(&curve.Base, s).ScalarMultiplication(&lhs, cofactor)
+	rhs.ScalarMultiplication(&pub.A, h).Add(&rhs, &sig.R).ScalarMultiplication(&rhs, cofactor)
+	return lhs.Equal(&rhs), nil
+}
+
+// Bytes returns the compressed public key.
+func (pub *bandersnatchPublicKey) Bytes() []byte {
+	b := pub.A.Bytes()
+	return b[:]
+}
+
+// SetBytes sets the public key from its compressed form and returns the
+// number of bytes read.
+func (pub *bandersnatchPublicKey) SetBytes(buf []byte) (int, error) {
+	n, err := pub.A.SetBytes(buf)
+	if err != nil {
+		return 0, err
+	}
+	if !pub.A.IsOnCurve() {
+		return 0, fmt.Errorf("public key not on the curve")
+	}
+	return n, nil
+}
+
+// Equal compares two public keys.
+func (pub *bandersnatchPublicKey) Equal(x signature.PublicKey) bool {
+	other, ok := x.(*bandersnatchPublicKey)
+	if !ok {
+		return false
+	}
+	return subtle.ConstantTimeCompare(pub.Bytes(), other.Bytes()) == 1
+}
+
+// Bytes returns the signature as R || S.
+func (sig *bandersnatchSignature) Bytes() []byte {
+	r := sig.R.Bytes()
+	return append(r[:], sig.S[:]...)
+}
+
+// SetBytes sets the signature from R || S and returns the number of bytes
+// read.
+func (sig *bandersnatchSignature) SetBytes(buf []byte) (int, error) {
+	if len(buf) < bandersnatchSizeSignature {
+		return 0, io.ErrShortBuffer
+	}
+	n, err := sig.R.SetBytes(buf)
+	if err != nil {
+		return 0, err
+	}
+	if !sig.R.IsOnCurve() {
+		return 0, fmt.Errorf("signature R not on the curve")
+	}
+	n += copy(sig.S[:], buf[n:bandersnatchSizeSignature])
+	return n, nil
+}
+
+// bandersnatchChallenge returns H(R, A, M), hashing the coordinates of the
+// points as field elements in big endian, as the circuit does with the
+// native MiMC hash function.
+func bandersnatchChallenge(hFunc hash.Hash, r, a *bandersnatch.PointAffine, message []byte) (*big.Int, error) {
+	hFunc.Reset()
+	rx, ry := r.X.Bytes(), r.Y.Bytes()
+	ax, ay := a.X.Bytes(), a.Y.Bytes()
+	for _, b := range [][]byte{rx[:], ry[:], ax[:], ay[:], message} {
+		if _, err := hFunc.Write(b); err != nil {
+			return nil, err
+		}
+	}
+	return new(big.Int).SetBytes(hFunc.Sum(nil)), nil
+}
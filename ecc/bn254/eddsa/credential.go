@@ -0,0 +1,57 @@
+package eddsa
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/native/twistededwards"
+)
+
+// Credential contains a public key, a signature and a message to be verified
+// by a Verifier. It implements the signature.Verifier interface, so EdDSA
+// signatures can be verified together with other schemes.
+type Credential struct {
+	verifier *Verifier
+	pubKey   PublicKey
+	sig      Signature
+	msg      frontend.Variable
+}
+
+// Credential returns a new Credential to verify the signature of the message
+// provided with the public key, using the verifier.
+func (v *Verifier) Credential(pubKey PublicKey, sig Signature, msg frontend.Variable) *Credential {
+	return &Credential{
+		verifier: v,
+		pubKey:   pubKey,
+		sig:      sig,
+		msg:      msg,
+	}
+}
+
+// IsValid returns 1 if the signature of the credential is valid, 0
+// otherwise. See Verifier.IsValid.
+func (c *Credential) IsValid() (frontend.Variable, error) {
+	return c.verifier.IsValid(c.pubKey, c.sig, c.msg), nil
+}
+
+// Identity returns the hash of the public key coordinates, in the format
+// provided, using the hash function of the verifier.
+func (c *Credential) Identity() (frontend.Variable, error) {
+	hashFn := c.verifier.hashFn
+	hashFn.Reset()
+	hashFn.Write(c.pubKey.A.X, c.pubKey.A.Y)
+	if !hashFn.WriteSucceeded() {
+		return nil, fmt.Errorf("error hashing the public key")
+	}
+	return hashFn.Sum(), nil
+}
+
+// DummyInputs returns the public placeholder inputs of a Credential, to fill
+// the EdDSA inputs of a circuit when another scheme is selected (see
+// signature.Select): the identity point as the public key and R, S = 1 and
+// the message 0. The points are on the curve of any verifier, but they are
+// not a valid signature (with S = 0 they would be).
+func DummyInputs() (PublicKey, Signature, frontend.Variable) {
+	identity := twistededwards.Point{X: 0, Y: 1}
+	return PublicKey{A: identity}, Signature{R: identity, S: 1}, 0
+}
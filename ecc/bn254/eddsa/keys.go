@@ -0,0 +1,85 @@
+package eddsa
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/native/twistededwards"
+	"github.com/consensys/gnark/std/math/bits"
+	"github.com/iden3/go-iden3-crypto/babyjub"
+	"github.com/vocdoni/gnark-crypto-primitives/ecc/format"
+	"github.com/vocdoni/gnark-crypto-primitives/elgamal"
+	"github.com/vocdoni/gnark-crypto-primitives/hash/blake512"
+	"github.com/vocdoni/gnark-crypto-primitives/utils"
+)
+
+const (
+	// PrivateKeyLen is the length of an Iden3 private key in bytes.
+	PrivateKeyLen = 32
+	// scalarBits is the number of bits of the scalar derived from an Iden3
+	// private key, whose most significant bit is always set.
+	scalarBits = 252
+)
+
+// PrivateKeyScalar derives the scalar of the Iden3 private key provided, as
+// babyjub.PrivateKey.Scalar does: it hashes the private key with BLAKE-512,
+// prunes the first 32 bytes of the digest (clears the three lowest bits and
+// the highest bit, and sets the second highest bit) and divides the result,
+// read in little-endian, by 8.
+func PrivateKeyScalar(api frontend.API, privKey utils.Bytes) (frontend.Variable, error) {
+	if len(privKey) != PrivateKeyLen {
+		return nil, fmt.Errorf("invalid private key length: %d", len(privKey))
+	}
+	digest, err := blake512.Sum(api, privKey)
+	if err != nil {
+		return nil, err
+	}
+	// get the bits of the first 32 bytes in little-endian
+	digestBits := make([]frontend.Variable, 0, PrivateKeyLen*8)
+	for _, b := range digest[:PrivateKeyLen] {
+		digestBits = append(digestBits, bits.ToBinary(api, b.Val, bits.WithNbDigits(8))...)
+	}
+	// the pruning clears the bits 0-2 and 255 and sets the bit 254, so after
+	// the shift the scalar is composed of the bits 3-253 and a leading one
+	scalarBitsLE := append(digestBits[3:254:254], 1)
+	return bits.FromBinary(api, scalarBitsLE, bits.WithUnconstrainedInputs()), nil
+}
+
+// PublicKeyFromPrivateKey derives the public key of the Iden3 private key
+// provided, as babyjub.PrivateKey.Public does. The resulting public key is
+// in TwistedEdwards format (Iden3 format), like the ones that
+// PublicKeyFromIden3 returns.
+func PublicKeyFromPrivateKey(api frontend.API, privKey utils.Bytes) (PublicKey, error) {
+	scalar, err := PrivateKeyScalar(api, privKey)
+	if err != nil {
+		return PublicKey{}, err
+	}
+	return publicKeyFromScalar(api, scalar), nil
+}
+
+// PublicKeyFromScalar derives the public key of the scalar of an Iden3
+// private key (the value of babyjub.PrivateKey.Scalar), which is much
+// cheaper than PublicKeyFromPrivateKey since it skips the BLAKE-512 hash. It
+// asserts that the scalar has the format of the pruned scalars, that is, it
+// has 252 bits and the most significant one is set. The resulting public key
+// is in TwistedEdwards format (Iden3 format).
+func PublicKeyFromScalar(api frontend.API, scalar frontend.Variable) PublicKey {
+	scalarBitsLE := bits.ToBinary(api, scalar, bits.WithNbDigits(scalarBits))
+	api.AssertIsEqual(scalarBitsLE[scalarBits-1], 1)
+	return publicKeyFromScalar(api, scalar)
+}
+
+// publicKeyFromScalar returns [scalar] * B8 in TwistedEdwards format. The
+// fixed base of elgamal.FixedBaseScalarMulBN254 is B8 in Reduced
+// TwistedEdwards format, so the result is converted back.
+func publicKeyFromScalar(api frontend.API, scalar frontend.Variable) PublicKey {
+	point := elgamal.FixedBaseScalarMulBN254(api, scalar)
+	x, y := format.FromRTEtoTE(api, point.X, point.Y)
+	return PublicKey{A: twistededwards.Point{X: x, Y: y}}
+}
+
+// PrivateKeyFromIden3 converts a Iden3 private key to the circuit input
+// expected by PrivateKeyScalar and PublicKeyFromPrivateKey.
+func PrivateKeyFromIden3(privKey *babyjub.PrivateKey) utils.Bytes {
+	return utils.BytesFromSlice(privKey[:], PrivateKeyLen)
+}
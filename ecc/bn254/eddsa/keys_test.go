@@ -0,0 +1,109 @@
+package eddsa
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/profile"
+	"github.com/consensys/gnark/std/math/uints"
+	"github.com/consensys/gnark/test"
+	"github.com/iden3/go-iden3-crypto/babyjub"
+)
+
+type testPrivateKeyCircuit struct {
+	PrivateKey [PrivateKeyLen]uints.U8
+	Scalar     frontend.Variable
+	PublicKey  PublicKey `gnark:",public"`
+}
+
+func (c *testPrivateKeyCircuit) Define(api frontend.API) error {
+	scalar, err := PrivateKeyScalar(api, c.PrivateKey[:])
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(scalar, c.Scalar)
+	pubKey, err := PublicKeyFromPrivateKey(api, c.PrivateKey[:])
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(pubKey.A.X, c.PublicKey.A.X)
+	api.AssertIsEqual(pubKey.A.Y, c.PublicKey.A.Y)
+	return nil
+}
+
+type testScalarCircuit struct {
+	Scalar    frontend.Variable
+	PublicKey PublicKey `gnark:",public"`
+}
+
+func (c *testScalarCircuit) Define(api frontend.API) error {
+	pubKey := PublicKeyFromScalar(api, c.Scalar)
+	api.AssertIsEqual(pubKey.A.X, c.PublicKey.A.X)
+	api.AssertIsEqual(pubKey.A.Y, c.PublicKey.A.Y)
+	return nil
+}
+
+func TestPublicKeyFromPrivateKey(t *testing.T) {
+	// profiling the circuit compilation
+	p := profile.Start()
+	now := time.Now()
+	_, _ = frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &testPrivateKeyCircuit{})
+	fmt.Println("elapsed", time.Since(now))
+	p.Stop()
+	fmt.Println("constrains", p.NbConstraints())
+
+	privKey := babyjub.NewRandPrivKey()
+	witness := &testPrivateKeyCircuit{
+		PrivateKey: [PrivateKeyLen]uints.U8(PrivateKeyFromIden3(&privKey)),
+		Scalar:     privKey.Scalar().BigInt(),
+		PublicKey:  PublicKeyFromIden3(privKey.Public()),
+	}
+	// the public key of other private key must fail
+	otherPrivKey := babyjub.NewRandPrivKey()
+	invalid := *witness
+	invalid.PublicKey = PublicKeyFromIden3(otherPrivKey.Public())
+
+	assert := test.NewAssert(t)
+	assert.CheckCircuit(&testPrivateKeyCircuit{},
+		test.WithValidAssignment(witness),
+		test.WithInvalidAssignment(&invalid),
+		test.WithCurves(ecc.BN254),
+		test.WithBackends(backend.GROTH16),
+	)
+}
+
+func TestPublicKeyFromScalar(t *testing.T) {
+	// profiling the circuit compilation
+	p := profile.Start()
+	now := time.Now()
+	_, _ = frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &testScalarCircuit{})
+	fmt.Println("elapsed", time.Since(now))
+	p.Stop()
+	fmt.Println("constrains", p.NbConstraints())
+
+	privKey := babyjub.NewRandPrivKey()
+	witness := &testScalarCircuit{
+		Scalar:    privKey.Scalar().BigInt(),
+		PublicKey: PublicKeyFromIden3(privKey.Public()),
+	}
+	// a scalar that is not pruned must fail, even with the right public key
+	scalar := big.NewInt(12345)
+	notPruned := &testScalarCircuit{
+		Scalar:    scalar,
+		PublicKey: PublicKeyFromIden3((*babyjub.PublicKey)(babyjub.NewPoint().Mul(scalar, babyjub.B8))),
+	}
+
+	assert := test.NewAssert(t)
+	assert.CheckCircuit(&testScalarCircuit{},
+		test.WithValidAssignment(witness),
+		test.WithInvalidAssignment(notPruned),
+		test.WithCurves(ecc.BN254),
+		test.WithBackends(backend.GROTH16),
+	)
+}
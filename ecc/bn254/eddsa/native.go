@@ -0,0 +1,157 @@
+package eddsa
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	eddsa_bls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377/twistededwards/eddsa"
+	eddsa_bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381/twistededwards/eddsa"
+	eddsa_bw6761 "github.com/consensys/gnark-crypto/ecc/bw6-761/twistededwards/eddsa"
+	ecc_tw "github.com/consensys/gnark-crypto/ecc/twistededwards"
+	"github.com/consensys/gnark-crypto/hash"
+	"github.com/consensys/gnark-crypto/signature"
+	geddsa "github.com/consensys/gnark-crypto/signature/eddsa"
+	"github.com/consensys/gnark/std/algebra/native/twistededwards"
+)
+
+// nativeCurve contains the MiMC hash function and the native field of the
+// circuit that verifies the signatures of a twisted edwards curve.
+type nativeCurve struct {
+	hash  hash.Hash
+	field *big.Int
+}
+
+// nativeCurves contains the supported twisted edwards curves for the native
+// signing helpers. BN254 is not included because it uses the Iden3 scheme.
+// Bandersnatch keys are not generated by gnark-crypto, since its
+// implementation signs over the Jubjub points (see bandersnatch.go).
+var nativeCurves = map[ecc_tw.ID]nativeCurve{
+	ecc_tw.BLS12_381:              {hash.MIMC_BLS12_381, ecc.BLS12_381.ScalarField()},
+	ecc_tw.BLS12_381_BANDERSNATCH: {hash.MIMC_BLS12_381, ecc.BLS12_381.ScalarField()},
+	ecc_tw.BLS12_377:              {hash.MIMC_BLS12_377, ecc.BLS12_377.ScalarField()},
+	ecc_tw.BW6_761:                {hash.MIMC_BW6_761, ecc.BW6_761.ScalarField()},
+}
+
+func getNativeCurve(curveID ecc_tw.ID) (nativeCurve, error) {
+	nc, ok := nativeCurves[curveID]
+	if !ok {
+		return nativeCurve{}, fmt.Errorf("unsupported twistededwards curve %d, use Iden3 babyjub for BN254", curveID)
+	}
+	return nc, nil
+}
+
+// GenerateKey generates a new gnark-crypto EdDSA private key for the
+// twistededwards curve provided using the randomness source provided.
+func GenerateKey(curveID ecc_tw.ID, r io.Reader) (signature.Signer, error) {
+	if _, err := getNativeCurve(curveID); err != nil {
+		return nil, err
+	}
+	if curveID == ecc_tw.BLS12_381_BANDERSNATCH {
+		return generateBandersnatchKey(r)
+	}
+	return geddsa.New(curveID, r)
+}
+
+// Sign signs the message provided with the gnark-crypto EdDSA private key
+// provided, using the MiMC hash function of the native field of the circuit
+// that verifies it. The message is encoded as an element of that field, so
+// it must be lower than its modulus. The resulting signature can be verified
+// in-circuit with a Verifier created with NewVerifierForCurve and the MiMC
+// hash function.
+func Sign(curveID ecc_tw.ID, privKey signature.Signer, msg *big.Int) ([]byte, error) {
+	nc, err := getNativeCurve(curveID)
+	if err != nil {
+		return nil, err
+	}
+	if msg.Sign() < 0 || msg.Cmp(nc.field) >= 0 {
+		return nil, fmt.Errorf("message out of the native field")
+	}
+	bMsg := msg.FillBytes(make([]byte, (nc.field.BitLen()+7)/8))
+	return privKey.Sign(bMsg, nc.hash.New())
+}
+
+// PublicKeyFromNative converts a gnark-crypto EdDSA public key of the
+// twistededwards curve provided to a gnark public key.
+func PublicKeyFromNative(curveID ecc_tw.ID, pubKey signature.PublicKey) (PublicKey, error) {
+	var x, y big.Int
+	switch curveID {
+	case ecc_tw.BLS12_381:
+		var pk eddsa_bls12381.PublicKey
+		if _, err := pk.SetBytes(pubKey.Bytes()); err != nil {
+			return PublicKey{}, err
+		}
+		pk.A.X.BigInt(&x)
+		pk.A.Y.BigInt(&y)
+	case ecc_tw.BLS12_377:
+		var pk eddsa_bls12377.PublicKey
+		if _, err := pk.SetBytes(pubKey.Bytes()); err != nil {
+			return PublicKey{}, err
+		}
+		pk.A.X.BigInt(&x)
+		pk.A.Y.BigInt(&y)
+	case ecc_tw.BLS12_381_BANDERSNATCH:
+		var pk bandersnatchPublicKey
+		if _, err := pk.SetBytes(pubKey.Bytes()); err != nil {
+			return PublicKey{}, err
+		}
+		pk.A.X.BigInt(&x)
+		pk.A.Y.BigInt(&y)
+	case ecc_tw.BW6_761:
+		var pk eddsa_bw6761.PublicKey
+		if _, err := pk.SetBytes(pubKey.Bytes()); err != nil {
+			return PublicKey{}, err
+		}
+		pk.A.X.BigInt(&x)
+		pk.A.Y.BigInt(&y)
+	default:
+		_, err := getNativeCurve(curveID)
+		return PublicKey{}, err
+	}
+	return PublicKey{A: twistededwards.Point{X: &x, Y: &y}}, nil
+}
+
+// SignatureFromNative converts a gnark-crypto EdDSA signature of the
+// twistededwards curve provided to a gnark signature.
+func SignatureFromNative(curveID ecc_tw.ID, sig []byte) (Signature, error) {
+	var x, y, s big.Int
+	switch curveID {
+	case ecc_tw.BLS12_381:
+		var signature eddsa_bls12381.Signature
+		if _, err := signature.SetBytes(sig); err != nil {
+			return Signature{}, err
+		}
+		signature.R.X.BigInt(&x)
+		signature.R.Y.BigInt(&y)
+		s.SetBytes(signature.S[:])
+	case ecc_tw.BLS12_377:
+		var signature eddsa_bls12377.Signature
+		if _, err := signature.SetBytes(sig); err != nil {
+			return Signature{}, err
+		}
+		signature.R.X.BigInt(&x)
+		signature.R.Y.BigInt(&y)
+		s.SetBytes(signature.S[:])
+	case ecc_tw.BLS12_381_BANDERSNATCH:
+		var signature bandersnatchSignature
+		if _, err := signature.SetBytes(sig); err != nil {
+			return Signature{}, err
+		}
+		signature.R.X.BigInt(&x)
+		signature.R.Y.BigInt(&y)
+		s.SetBytes(signature.S[:])
+	case ecc_tw.BW6_761:
+		var signature eddsa_bw6761.Signature
+		if _, err := signature.SetBytes(sig); err != nil {
+			return Signature{}, err
+		}
+		signature.R.X.BigInt(&x)
+		signature.R.Y.BigInt(&y)
+		s.SetBytes(signature.S[:])
+	default:
+		_, err := getNativeCurve(curveID)
+		return Signature{}, err
+	}
+	return Signature{R: twistededwards.Point{X: &x, Y: &y}, S: &s}, nil
+}
@@ -1,5 +1,8 @@
 // eddsa package contains the implementation of a EdDSA signature verifier
-// compatible with Iden3 and Circomlib scheme in Gnark.
+// compatible with Iden3 and Circomlib scheme in Gnark. The verifier can also
+// be initialized for other twisted edwards curves supported by gnark (Jubjub,
+// Bandersnatch, the BLS12-377 and BW6-761 Edwards curves), verifying the
+// signatures generated by gnark-crypto (see Sign).
 package eddsa
 
 import (
@@ -9,29 +12,58 @@ import (
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/std/algebra/native/twistededwards"
 	"github.com/vocdoni/gnark-crypto-primitives/ecc/format"
+	"github.com/vocdoni/gnark-crypto-primitives/ecc/msm"
 	"github.com/vocdoni/gnark-crypto-primitives/hash"
 )
 
 // Verifier implements a EdDSA signature verifier compatible with Iden3 and
 // Circomlib scheme in Gnark.
 type Verifier struct {
-	api    frontend.API
-	curve  twistededwards.Curve
-	hashFn hash.Hash[frontend.Variable]
+	api      frontend.API
+	curveID  ecc_tw.ID
+	curve    twistededwards.Curve
+	base     twistededwards.Point
+	cofactor uint64
+	hashFn   hash.Hash[frontend.Variable]
 }
 
 // NewVerifier returns a new instance of the Verifier using the in-ciruit API
 // to initialize the twistededwards curve and the desired hash function. It
 // works with Mimc7 and Poseidon hash functions.
 func NewVerifier(api frontend.API, hashFn hash.Hash[frontend.Variable]) (*Verifier, error) {
-	curve, err := twistededwards.NewEdCurve(api, ecc_tw.BN254)
+	return NewVerifierForCurve(api, ecc_tw.BN254, hashFn)
+}
+
+// NewVerifierForCurve returns a new instance of the Verifier for the
+// twistededwards curve provided, which must be defined over the native field
+// of the circuit. For BN254 the verifier is the same returned by NewVerifier
+// (Iden3 scheme, points in TE format). For any other curve, points are
+// expected in the gnark format and signatures are verified following the
+// gnark-crypto scheme, using the generator and cofactor of the curve.
+func NewVerifierForCurve(api frontend.API, curveID ecc_tw.ID, hashFn hash.Hash[frontend.Variable]) (*Verifier, error) {
+	curve, err := twistededwards.NewEdCurve(api, curveID)
 	if err != nil {
-		return nil, fmt.Errorf("error initializing bn254 twistededwards curve: %w", err)
+		return nil, fmt.Errorf("error initializing twistededwards curve %d: %w", curveID, err)
+	}
+	params := curve.Params()
+	if !params.Cofactor.IsUint64() {
+		return nil, fmt.Errorf("unsupported cofactor for twistededwards curve %d", curveID)
+	}
+	cofactor := params.Cofactor.Uint64()
+	if cofactor&(cofactor-1) != 0 {
+		return nil, fmt.Errorf("cofactor of twistededwards curve %d is not a power of two", curveID)
+	}
+	base := twistededwards.Point{X: params.Base[0], Y: params.Base[1]}
+	if curveID == ecc_tw.BN254 {
+		base = rteB8
 	}
 	return &Verifier{
-		api:    api,
-		curve:  curve,
-		hashFn: hashFn,
+		api:      api,
+		curveID:  curveID,
+		curve:    curve,
+		base:     base,
+		cofactor: cofactor,
+		hashFn:   hashFn,
 	}, nil
 }
 
@@ -51,8 +83,13 @@ func (v *Verifier) PointToRTE(p twistededwards.Point) twistededwards.Point {
 // public key, the signature and the message and works. It calculates the
 // hash of the signature R, public key A and message using the original points
 // format. Then it converts the public key A and signature R to the RTE format.
-// Finally it performs the verification.
+// Finally it performs the verification. If the verifier is not initialized
+// for BN254, the signature is verified using the gnark-crypto scheme (see
+// isValidGnark).
 func (v *Verifier) IsValid(pubKey PublicKey, sig Signature, msg frontend.Variable) frontend.Variable {
+	if v.curveID != ecc_tw.BN254 {
+		return v.isValidGnark(pubKey, sig, msg)
+	}
 	// Calculate the hash of the signature R, public key A and message using
 	// original points format
 	v.hashFn.Reset()
@@ -67,26 +104,68 @@ func (v *Verifier) IsValid(pubKey PublicKey, sig Signature, msg frontend.Variabl
 	rtePubKeyA := v.PointToRTE(pubKey.A)
 	rteSigR := v.PointToRTE(sig.R)
 
-	// left := sig.S * rteB8
-	left := v.curve.ScalarMul(rteB8, sig.S)
-
-	// R1 := rtePubKeyA * h * 8
-	R1 := v.curve.ScalarMul(rtePubKeyA, v.hashFn.Sum()) // rtePubKeyA * h
-	R1 = v.curve.Double(R1)                             // R1 * 2
-	R1 = v.curve.Double(R1)                             // (R1 * 2) * 2
-	R1 = v.curve.Double(R1)                             // ((R1 * 2) * 2) * 2
-
-	// right := R1 + rteSigR
-	right := v.curve.Add(R1, rteSigR)
+	// Q := S * rteB8 - h * (8 * rtePubKeyA), where 8 * rtePubKeyA is in the
+	// prime order subgroup, which must be equal to rteSigR
+	A8 := v.curve.Double(v.curve.Double(v.curve.Double(rtePubKeyA)))
+	Q, err := msm.MultiScalarMul(v.curve,
+		[]twistededwards.Point{v.base, v.curve.Neg(A8)},
+		[]frontend.Variable{sig.S, v.hashFn.Sum()})
+	if err != nil {
+		// This point should never be reached, the number of points and
+		// scalars is the same.
+		return 0
+	}
 
-	// Check if left == right
-	xValid := v.api.IsZero(v.api.Sub(left.X, right.X))
-	yValid := v.api.IsZero(v.api.Sub(left.Y, right.Y))
+	// Check if Q == rteSigR
+	xValid := v.api.IsZero(v.api.Sub(Q.X, rteSigR.X))
+	yValid := v.api.IsZero(v.api.Sub(Q.Y, rteSigR.Y))
 
 	// Return if both sides are valid
 	return v.api.And(xValid, yValid)
 }
 
+// isValidGnark returns 1 if the signature is valid following the
+// gnark-crypto EdDSA scheme, 0 otherwise. The points are used as provided,
+// the hash of the signature R, public key A and message is calculated and
+// then it checks that cofactor * (S * base - h * A - R) is the identity. The
+// signature S must also be lower than the curve order to prevent
+// malleability.
+func (v *Verifier) isValidGnark(pubKey PublicKey, sig Signature, msg frontend.Variable) frontend.Variable {
+	v.hashFn.Reset()
+	v.hashFn.Write(sig.R.X, sig.R.Y, pubKey.A.X, pubKey.A.Y, msg)
+	if !v.hashFn.WriteSucceeded() {
+		return 0
+	}
+	v.curve.AssertIsOnCurve(pubKey.A)
+	v.curve.AssertIsOnCurve(sig.R)
+	// S < order
+	sValid := v.api.IsZero(v.api.Add(v.api.Cmp(sig.S, v.curve.Params().Order), 1))
+
+	// cofactor * (S * base - h * A - R) is the identity if S * (cofactor *
+	// base) - h * (cofactor * A) == cofactor * R, where the points
+	// multiplied by the cofactor are in the prime order subgroup, and the
+	// base is a constant, so it is doubled at compile time
+	cBase, cA, cR := v.base, pubKey.A, sig.R
+	for c := v.cofactor; c > 1; c >>= 1 {
+		cBase = v.curve.Double(cBase)
+		cA = v.curve.Double(cA)
+		cR = v.curve.Double(cR)
+	}
+	Q, err := msm.MultiScalarMul(v.curve,
+		[]twistededwards.Point{cBase, v.curve.Neg(cA)},
+		[]frontend.Variable{sig.S, v.hashFn.Sum()})
+	if err != nil {
+		// This point should never be reached, the number of points and
+		// scalars is the same.
+		return 0
+	}
+
+	// Check if Q == cofactor * R
+	xValid := v.api.IsZero(v.api.Sub(Q.X, cR.X))
+	yValid := v.api.IsZero(v.api.Sub(Q.Y, cR.Y))
+	return v.api.And(sValid, v.api.And(xValid, yValid))
+}
+
 // Verify method asserts that the public key verifies the signature for the
 // message provided.
 func (v *Verifier) Verify(pubKey PublicKey, sig Signature, msg frontend.Variable) {
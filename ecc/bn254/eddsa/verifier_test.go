@@ -1,17 +1,20 @@
 package eddsa
 
 import (
+	"crypto/rand"
 	"fmt"
 	"math/big"
 	"testing"
 	"time"
 
 	"github.com/consensys/gnark-crypto/ecc"
+	ecc_tw "github.com/consensys/gnark-crypto/ecc/twistededwards"
 	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/frontend/cs/r1cs"
 	"github.com/consensys/gnark/profile"
 	"github.com/consensys/gnark/test"
+	qt "github.com/frankban/quicktest"
 	"github.com/iden3/go-iden3-crypto/babyjub"
 	"github.com/vocdoni/davinci-node/util"
 	"github.com/vocdoni/gnark-crypto-primitives/hash/native"
@@ -68,3 +71,125 @@ func TestVerifier(t *testing.T) {
 	p.Stop()
 	fmt.Println("constrains", p.NbConstraints())
 }
+
+// testEdDSANaiveVerifierCircuit verifies the signature computing the scalar
+// multiplications of the verification equation independently, to compare
+// the number of constraints with the multi-scalar multiplication.
+type testEdDSANaiveVerifierCircuit struct {
+	PublicKey PublicKey `gnark:"public"`
+	Signature Signature `gnark:"public"`
+	Message   frontend.Variable
+}
+
+func (c *testEdDSANaiveVerifierCircuit) Define(api frontend.API) error {
+	hashFn, err := native.Poseidon(api)
+	if err != nil {
+		return err
+	}
+	v, err := NewVerifier(api, hashFn)
+	if err != nil {
+		return err
+	}
+	hashFn.Write(c.Signature.R.X, c.Signature.R.Y, c.PublicKey.A.X, c.PublicKey.A.Y, c.Message)
+	rtePubKeyA := v.PointToRTE(c.PublicKey.A)
+	rteSigR := v.PointToRTE(c.Signature.R)
+	// S * B8 == 8 * h * A + R
+	left := v.curve.ScalarMul(v.base, c.Signature.S)
+	right := v.curve.ScalarMul(rtePubKeyA, hashFn.Sum())
+	right = v.curve.Add(v.curve.Double(v.curve.Double(v.curve.Double(right))), rteSigR)
+	api.AssertIsEqual(left.X, right.X)
+	api.AssertIsEqual(left.Y, right.Y)
+	return nil
+}
+
+func TestVerifierConstraints(t *testing.T) {
+	c := qt.New(t)
+	msm, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &testEdDSAVerifierCircuit{})
+	c.Assert(err, qt.IsNil)
+	naive, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &testEdDSANaiveVerifierCircuit{})
+	c.Assert(err, qt.IsNil)
+	fmt.Println("constrains msm", msm.GetNbConstraints(), "naive", naive.GetNbConstraints())
+	c.Assert(msm.GetNbConstraints() < naive.GetNbConstraints(), qt.IsTrue)
+}
+
+type testEdDSACurveVerifierCircuit struct {
+	curveID   ecc_tw.ID
+	PublicKey PublicKey `gnark:"public"`
+	Signature Signature `gnark:"public"`
+	Message   frontend.Variable
+}
+
+func (c *testEdDSACurveVerifierCircuit) Define(api frontend.API) error {
+	hashFn, err := native.MiMC(api)
+	if err != nil {
+		return err
+	}
+	verifier, err := NewVerifierForCurve(api, c.curveID, hashFn)
+	if err != nil {
+		return err
+	}
+	verifier.Verify(c.PublicKey, c.Signature, c.Message)
+	return nil
+}
+
+func TestVerifierForCurve(t *testing.T) {
+	c := qt.New(t)
+	for _, tc := range []struct {
+		name    string
+		curveID ecc_tw.ID
+		field   ecc.ID
+	}{
+		{"bls12-377", ecc_tw.BLS12_377, ecc.BLS12_377},
+		{"jubjub", ecc_tw.BLS12_381, ecc.BLS12_381},
+		{"bw6-761", ecc_tw.BW6_761, ecc.BW6_761},
+		{"bandersnatch", ecc_tw.BLS12_381_BANDERSNATCH, ecc.BLS12_381},
+	} {
+		// generate a key pair and sign a random message
+		privKey, err := GenerateKey(tc.curveID, rand.Reader)
+		c.Assert(err, qt.IsNil)
+		msg := new(big.Int).SetBytes(util.RandomBytes(31))
+		sig, err := Sign(tc.curveID, privKey, msg)
+		c.Assert(err, qt.IsNil)
+		// the signature is valid natively too
+		bMsg := msg.FillBytes(make([]byte, (tc.field.ScalarField().BitLen()+7)/8))
+		valid, err := privKey.Public().Verify(sig, bMsg, nativeCurves[tc.curveID].hash.New())
+		c.Assert(err, qt.IsNil)
+		c.Assert(valid, qt.IsTrue)
+		publicKey, err := PublicKeyFromNative(tc.curveID, privKey.Public())
+		c.Assert(err, qt.IsNil)
+		signature, err := SignatureFromNative(tc.curveID, sig)
+		c.Assert(err, qt.IsNil)
+
+		p := profile.Start()
+		now := time.Now()
+		_, err = frontend.Compile(tc.field.ScalarField(), r1cs.NewBuilder, &testEdDSACurveVerifierCircuit{curveID: tc.curveID})
+		c.Assert(err, qt.IsNil)
+		fmt.Println(tc.name, "elapsed", time.Since(now))
+		p.Stop()
+		fmt.Println(tc.name, "constrains", p.NbConstraints())
+
+		// a different message must fail
+		invalid := &testEdDSACurveVerifierCircuit{
+			PublicKey: publicKey,
+			Signature: signature,
+			Message:   new(big.Int).Add(msg, big.NewInt(1)),
+		}
+		assert := test.NewAssert(t)
+		assert.CheckCircuit(&testEdDSACurveVerifierCircuit{curveID: tc.curveID},
+			test.WithValidAssignment(&testEdDSACurveVerifierCircuit{
+				PublicKey: publicKey,
+				Signature: signature,
+				Message:   msg,
+			}),
+			test.WithInvalidAssignment(invalid),
+			test.WithCurves(tc.field),
+			test.WithBackends(backend.GROTH16),
+		)
+	}
+}
+
+func TestVerifierForCurveUnsupported(t *testing.T) {
+	c := qt.New(t)
+	_, err := GenerateKey(ecc_tw.BN254, rand.Reader)
+	c.Assert(err, qt.IsNotNil)
+}
@@ -0,0 +1,115 @@
+package ringsig
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/native/twistededwards"
+	"github.com/consensys/gnark/std/math/bits"
+	"github.com/iden3/go-iden3-crypto/babyjub"
+	"github.com/vocdoni/gnark-crypto-primitives/ecc/format"
+	"github.com/vocdoni/gnark-crypto-primitives/utils"
+)
+
+// The key images are computed over H(PK), a hash of the public key to a
+// point of the prime order subgroup whose discrete logarithm is unknown. The
+// public key coordinates (in TwistedEdwards format) are hashed to a field
+// element r, which is mapped to the Montgomery form of BabyJubJub
+// (v² = u³ + A·u² + u) with Elligator 2:
+//
+//	u1 = -A / (1 + Z·r²),  u2 = -u1 - A
+//	(u, v) = (u1, sqrt(g(u1))) if g(u1) is a square, or (u2, sqrt(g(u2)))
+//
+// where Z = 5 is a non-square of the field, g(u) = u³ + A·u² + u, and the
+// even square root is taken. Exactly one of g(u1) and g(u2) is a square, so
+// the map is deterministic. Finally, the point is converted to TwistedEdwards
+// format and multiplied by the cofactor.
+var (
+	elligatorZ = big.NewInt(5)
+	montA      = big.NewInt(168698)
+)
+
+// hashToPoint hashes the public key (in TwistedEdwards format) to a point of
+// the prime order subgroup, in Reduced TwistedEdwards format.
+func hashToPoint(api frontend.API, curve twistededwards.Curve, hFn utils.Hasher, pubKey twistededwards.Point) (twistededwards.Point, error) {
+	r, err := hFn(api, pubKey.X, pubKey.Y)
+	if err != nil {
+		return twistededwards.Point{}, err
+	}
+	// u1 = -A / (1 + Z·r²), the denominator is never zero because -1/Z is
+	// not a square
+	u1 := api.Div(api.Neg(montA), api.Add(1, api.Mul(elligatorZ, r, r)))
+	u2 := api.Sub(api.Neg(u1), montA)
+	// the hint returns which one of g(u1) and g(u2) is a square and its even
+	// square root
+	g1, g2 := montgomeryRHS(api, u1), montgomeryRHS(api, u2)
+	res, err := api.NewHint(HashToPointHint, 2, g1, g2)
+	if err != nil {
+		return twistededwards.Point{}, err
+	}
+	isSquare, v := res[0], res[1]
+	api.AssertIsBoolean(isSquare)
+	u := api.Select(isSquare, u1, u2)
+	api.AssertIsEqual(api.Mul(v, v), api.Select(isSquare, g1, g2))
+	// the root must be the even one
+	vBits := bits.ToBinary(api, v)
+	api.AssertIsEqual(vBits[0], 0)
+	// convert to Reduced TwistedEdwards and clear the cofactor
+	x, y := format.FromMontgomerytoRTE(api, u, v)
+	point := twistededwards.Point{X: x, Y: y}
+	curve.AssertIsOnCurve(point)
+	return curve.Double(curve.Double(curve.Double(point))), nil
+}
+
+// montgomeryRHS returns u³ + A·u² + u.
+func montgomeryRHS(api frontend.API, u frontend.Variable) frontend.Variable {
+	u2 := api.Mul(u, u)
+	return api.Add(api.Mul(u2, u), api.Mul(montA, u2), u)
+}
+
+// HashToPoint hashes natively a public key to a point of the prime order
+// subgroup, in TwistedEdwards format. It is the base of the key image of
+// the public key.
+func HashToPoint(hFn utils.NativeHasher, pubKey *babyjub.PublicKey) (*babyjub.Point, error) {
+	p := ecc.BN254.ScalarField()
+	r, err := hFn([]*big.Int{pubKey.X, pubKey.Y})
+	if err != nil {
+		return nil, err
+	}
+	// u1 = -A / (1 + Z·r²), u2 = -u1 - A
+	den := new(big.Int).Mul(r, r)
+	den.Mul(den, elligatorZ).Add(den, big.NewInt(1)).Mod(den, p)
+	u1 := new(big.Int).ModInverse(den, p)
+	u1.Mul(u1, new(big.Int).Neg(montA)).Mod(u1, p)
+	u2 := new(big.Int).Neg(u1)
+	u2.Sub(u2, montA).Mod(u2, p)
+	rhs := func(u *big.Int) *big.Int {
+		u2 := new(big.Int).Mul(u, u)
+		res := new(big.Int).Mul(u2, u)
+		res.Add(res, u2.Mul(u2, montA)).Add(res, u)
+		return res.Mod(res, p)
+	}
+	isSquare, v, err := evenSqrt(rhs(u1), rhs(u2), p)
+	if err != nil {
+		return nil, err
+	}
+	u := u2
+	if isSquare {
+		u = u1
+	}
+	if v.Sign() == 0 {
+		return nil, fmt.Errorf("point of order two")
+	}
+	// x = u / v, y = (u - 1) / (u + 1)
+	x := new(big.Int).ModInverse(v, p)
+	x.Mul(x, u).Mod(x, p)
+	y := new(big.Int).ModInverse(new(big.Int).Add(u, big.NewInt(1)), p)
+	y.Mul(y, new(big.Int).Sub(u, big.NewInt(1))).Mod(y, p)
+	point := &babyjub.Point{X: x, Y: y}
+	if !point.InCurve() {
+		return nil, fmt.Errorf("the point is not on the curve")
+	}
+	return babyjub.NewPoint().Mul(big.NewInt(8), point), nil
+}
@@ -0,0 +1,65 @@
+package ringsig
+
+import (
+	"fmt"
+	"math/big"
+
+	edbn254 "github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+	"github.com/consensys/gnark/constraint/solver"
+)
+
+func init() { solver.RegisterHint(HashToPointHint, CofactorDivHint) }
+
+// HashToPointHint receives the values g(u1) and g(u2) of the Elligator 2 map
+// and returns 1 and the even square root of g(u1) if it is a square, or 0
+// and the even square root of g(u2) otherwise.
+var HashToPointHint solver.Hint = func(field *big.Int, in, out []*big.Int) error {
+	isSquare, root, err := evenSqrt(in[0], in[1], field)
+	if err != nil {
+		return err
+	}
+	if isSquare {
+		out[0].SetUint64(1)
+	} else {
+		out[0].SetUint64(0)
+	}
+	out[1].Set(root)
+	return nil
+}
+
+// CofactorDivHint receives a point (x, y) in Reduced TwistedEdwards format
+// of the prime order subgroup and returns the point Q such that [8] * Q is
+// the point provided, that is [8^-1 mod l] * (x, y).
+var CofactorDivHint solver.Hint = func(_ *big.Int, in, out []*big.Int) error {
+	var p edbn254.PointAffine
+	p.X.SetBigInt(in[0])
+	p.Y.SetBigInt(in[1])
+	if !p.IsOnCurve() {
+		return fmt.Errorf("the point is not on the curve")
+	}
+	order := edbn254.GetEdwardsCurve().Order
+	inv := new(big.Int).ModInverse(big.NewInt(8), &order)
+	var q edbn254.PointAffine
+	q.ScalarMultiplication(&p, inv)
+	q.X.BigInt(out[0])
+	q.Y.BigInt(out[1])
+	return nil
+}
+
+// evenSqrt returns true and the even square root of g1 if it is a square, or
+// false and the even square root of g2 otherwise, in the field provided.
+func evenSqrt(g1, g2, field *big.Int) (bool, *big.Int, error) {
+	isSquare := big.Jacobi(new(big.Int).Mod(g1, field), field) >= 0
+	g := g2
+	if isSquare {
+		g = g1
+	}
+	root := new(big.Int).ModSqrt(new(big.Int).Mod(g, field), field)
+	if root == nil {
+		return false, nil, fmt.Errorf("no square root")
+	}
+	if root.Bit(0) == 1 {
+		root.Sub(field, root)
+	}
+	return isSquare, root, nil
+}
@@ -0,0 +1,162 @@
+package ringsig
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/native/twistededwards"
+	"github.com/iden3/go-iden3-crypto/babyjub"
+	"github.com/vocdoni/gnark-crypto-primitives/utils"
+)
+
+// RingSignature is a linkable ring signature computed natively, with the
+// points in TwistedEdwards format.
+type RingSignature struct {
+	C0       *big.Int
+	S        []*big.Int
+	KeyImage *babyjub.Point
+}
+
+// KeyImage returns the key image of the private key provided, that is
+// [privKey] * H(PK), where PK = [privKey] * B8.
+func KeyImage(hFn utils.NativeHasher, privKey *big.Int) (*babyjub.Point, error) {
+	pubKey := babyjub.NewPoint().Mul(privKey, babyjub.B8)
+	hp, err := HashToPoint(hFn, (*babyjub.PublicKey)(pubKey))
+	if err != nil {
+		return nil, err
+	}
+	return babyjub.NewPoint().Mul(privKey, hp), nil
+}
+
+// Sign signs the message with the private key provided, whose public key
+// must be the public key of the ring at the index provided.
+func Sign(hFn utils.NativeHasher, ring []*babyjub.PublicKey, index int, privKey, msg *big.Int) (*RingSignature, error) {
+	if index < 0 || index >= len(ring) {
+		return nil, fmt.Errorf("invalid signer index: %d", index)
+	}
+	if babyjub.NewPoint().Mul(privKey, babyjub.B8).Compress() != ring[index].Compress() {
+		return nil, fmt.Errorf("the private key does not match the public key of the ring")
+	}
+	ringDigest, err := hashRingNative(hFn, ring)
+	if err != nil {
+		return nil, err
+	}
+	keyImage, err := KeyImage(hFn, privKey)
+	if err != nil {
+		return nil, err
+	}
+	hp, err := HashToPoint(hFn, ring[index])
+	if err != nil {
+		return nil, err
+	}
+	n := len(ring)
+	sig := &RingSignature{S: make([]*big.Int, n), KeyImage: keyImage}
+	// start the ring after the signer with L = [alpha] * G and
+	// R = [alpha] * H(PK)
+	alpha, err := rand.Int(rand.Reader, babyjub.SubOrder)
+	if err != nil {
+		return nil, err
+	}
+	c, err := challenge(hFn, ringDigest, msg, keyImage,
+		babyjub.NewPoint().Mul(alpha, babyjub.B8), babyjub.NewPoint().Mul(alpha, hp))
+	if err != nil {
+		return nil, err
+	}
+	for j := 1; j < n; j++ {
+		i := (index + j) % n
+		if i == 0 {
+			sig.C0 = c
+		}
+		if sig.S[i], err = rand.Int(rand.Reader, babyjub.SubOrder); err != nil {
+			return nil, err
+		}
+		if c, err = nextChallenge(hFn, ringDigest, msg, keyImage, ring[i], sig.S[i], c); err != nil {
+			return nil, err
+		}
+	}
+	if index == 0 {
+		sig.C0 = c
+	}
+	// close the ring: s = alpha - c * sk mod l
+	s := new(big.Int).Mul(c, privKey)
+	s.Sub(alpha, s).Mod(s, babyjub.SubOrder)
+	sig.S[index] = s
+	return sig, nil
+}
+
+// Verify checks natively that the signature is a valid signature of the
+// message by one of the public keys of the ring. It returns an error if it
+// is not.
+func (sig *RingSignature) Verify(hFn utils.NativeHasher, ring []*babyjub.PublicKey, msg *big.Int) error {
+	if len(ring) == 0 || len(ring) != len(sig.S) {
+		return fmt.Errorf("invalid ring size: %d public keys and %d responses", len(ring), len(sig.S))
+	}
+	if !sig.KeyImage.InSubGroup() {
+		return fmt.Errorf("the key image is not in the prime order subgroup")
+	}
+	ringDigest, err := hashRingNative(hFn, ring)
+	if err != nil {
+		return err
+	}
+	c := sig.C0
+	for i, pubKey := range ring {
+		if c, err = nextChallenge(hFn, ringDigest, msg, sig.KeyImage, pubKey, sig.S[i], c); err != nil {
+			return err
+		}
+	}
+	if c.Cmp(sig.C0) != 0 {
+		return fmt.Errorf("invalid ring signature")
+	}
+	return nil
+}
+
+// SignatureFromNative converts a native ring signature to a circuit
+// signature.
+func SignatureFromNative(sig *RingSignature) Signature {
+	s := make([]frontend.Variable, len(sig.S))
+	for i := range sig.S {
+		s[i] = sig.S[i]
+	}
+	return Signature{
+		C0:       sig.C0,
+		S:        s,
+		KeyImage: twistededwards.Point{X: sig.KeyImage.X, Y: sig.KeyImage.Y},
+	}
+}
+
+// nextChallenge computes L = [s] * G + [c] * PK and R = [s] * H(PK) + [c] * I
+// and returns the challenge of the next member of the ring.
+func nextChallenge(hFn utils.NativeHasher, ringDigest, msg *big.Int, keyImage *babyjub.Point, pubKey *babyjub.PublicKey, s, c *big.Int) (*big.Int, error) {
+	hp, err := HashToPoint(hFn, pubKey)
+	if err != nil {
+		return nil, err
+	}
+	l := addPoints(babyjub.NewPoint().Mul(s, babyjub.B8), babyjub.NewPoint().Mul(c, pubKey.Point()))
+	r := addPoints(babyjub.NewPoint().Mul(s, hp), babyjub.NewPoint().Mul(c, keyImage))
+	return challenge(hFn, ringDigest, msg, keyImage, l, r)
+}
+
+// challenge returns H(ring, msg, I, L, R).
+func challenge(hFn utils.NativeHasher, ringDigest, msg *big.Int, keyImage, l, r *babyjub.Point) (*big.Int, error) {
+	return hFn([]*big.Int{ringDigest, msg, keyImage.X, keyImage.Y, l.X, l.Y, r.X, r.Y})
+}
+
+// hashRingNative returns the digest of the public keys of the ring, as
+// hashRing does in the circuit.
+func hashRingNative(hFn utils.NativeHasher, ring []*babyjub.PublicKey) (*big.Int, error) {
+	digest := big.NewInt(0)
+	for _, pubKey := range ring {
+		var err error
+		if digest, err = hFn([]*big.Int{digest, pubKey.X, pubKey.Y}); err != nil {
+			return nil, err
+		}
+	}
+	return digest, nil
+}
+
+// addPoints returns a + b.
+func addPoints(a, b *babyjub.Point) *babyjub.Point {
+	return babyjub.NewPointProjective().Add(a.Projective(), b.Projective()).Affine()
+}
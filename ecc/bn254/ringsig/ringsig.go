@@ -0,0 +1,125 @@
+// ringsig package contains the implementation of a linkable ring signature
+// (LSAG) verifier over BabyJubJub in Gnark, and its native signer and
+// verifier.
+//
+// A ring signature proves that the message was signed by one of the public
+// keys of the ring without revealing which one. The signature includes the
+// key image I = [sk] * H(PK) of the signer, which is the same for every
+// signature of the same key, regardless of the ring and the message, so it
+// allows to detect double votes without a census tree.
+//
+// For a ring PK_0, ..., PK_{n-1}, the signature (c_0, s_0, ..., s_{n-1}, I)
+// is valid if, computing for every i:
+//
+//	L_i = [s_i] * G + [c_i] * PK_i
+//	R_i = [s_i] * H(PK_i) + [c_i] * I
+//	c_{i+1} = H(ring, msg, I, L_i, R_i)
+//
+// the last challenge c_n is equal to c_0. The ring size is fixed at circuit
+// compile time. The points are in TwistedEdwards format (Iden3 format), and
+// the private keys are scalars (for Iden3 keys, the output of
+// babyjub.PrivateKey.Scalar).
+//
+// Read more about this here: https://eprint.iacr.org/2004/027
+package ringsig
+
+import (
+	"fmt"
+
+	ecc_tweds "github.com/consensys/gnark-crypto/ecc/twistededwards"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/native/twistededwards"
+	"github.com/vocdoni/gnark-crypto-primitives/ecc/bn254/eddsa"
+	"github.com/vocdoni/gnark-crypto-primitives/ecc/format"
+	"github.com/vocdoni/gnark-crypto-primitives/elgamal"
+	"github.com/vocdoni/gnark-crypto-primitives/utils"
+)
+
+// Signature is the in-circuit representation of a linkable ring signature:
+// the first challenge, one response per member of the ring and the key image
+// of the signer, in TwistedEdwards format.
+type Signature struct {
+	C0       frontend.Variable
+	S        []frontend.Variable
+	KeyImage twistededwards.Point
+}
+
+// NewSignature returns an empty signature for a ring of ringSize public keys,
+// to be used in the definition of a circuit.
+func NewSignature(ringSize int) Signature {
+	return Signature{S: make([]frontend.Variable, ringSize)}
+}
+
+// Verify asserts that the signature is a valid signature of the message by
+// one of the public keys of the ring. It also asserts that the key image is
+// in the prime order subgroup, so every key has a single key image. The
+// hash function is used to compute the challenges and the base points of the
+// key images, and it must be the same that was used to sign.
+func Verify(api frontend.API, hFn utils.Hasher, ring []eddsa.PublicKey, msg frontend.Variable, sig Signature) error {
+	if len(ring) == 0 || len(ring) != len(sig.S) {
+		return fmt.Errorf("invalid ring size: %d public keys and %d responses", len(ring), len(sig.S))
+	}
+	curve, err := twistededwards.NewEdCurve(api, ecc_tweds.BN254)
+	if err != nil {
+		return err
+	}
+	ringDigest, err := hashRing(api, hFn, ring)
+	if err != nil {
+		return err
+	}
+	// the key image must be on the curve and, to be unique, in the prime
+	// order subgroup, which is ensured by dividing it by the cofactor
+	keyImage := toRTE(api, sig.KeyImage)
+	curve.AssertIsOnCurve(keyImage)
+	res, err := api.NewHint(CofactorDivHint, 2, keyImage.X, keyImage.Y)
+	if err != nil {
+		return err
+	}
+	q := twistededwards.Point{X: res[0], Y: res[1]}
+	curve.AssertIsOnCurve(q)
+	q = curve.Double(curve.Double(curve.Double(q)))
+	api.AssertIsEqual(q.X, keyImage.X)
+	api.AssertIsEqual(q.Y, keyImage.Y)
+	// go through the ring computing the challenges
+	c := sig.C0
+	for i, pubKey := range ring {
+		pk := toRTE(api, pubKey.A)
+		curve.AssertIsOnCurve(pk)
+		hp, err := hashToPoint(api, curve, hFn, pubKey.A)
+		if err != nil {
+			return err
+		}
+		// L = [s] * G + [c] * PK
+		l := curve.Add(elgamal.FixedBaseScalarMulBN254(api, sig.S[i]), curve.ScalarMul(pk, c))
+		// R = [s] * H(PK) + [c] * I
+		r := curve.DoubleBaseScalarMul(hp, keyImage, sig.S[i], c)
+		// c = H(ring, msg, I, L, R)
+		lx, ly := format.FromRTEtoTE(api, l.X, l.Y)
+		rx, ry := format.FromRTEtoTE(api, r.X, r.Y)
+		if c, err = hFn(api, ringDigest, msg, sig.KeyImage.X, sig.KeyImage.Y, lx, ly, rx, ry); err != nil {
+			return err
+		}
+	}
+	api.AssertIsEqual(c, sig.C0)
+	return nil
+}
+
+// hashRing returns the digest of the public keys of the ring, chaining the
+// hashes of the coordinates of every key.
+func hashRing(api frontend.API, hFn utils.Hasher, ring []eddsa.PublicKey) (frontend.Variable, error) {
+	var digest frontend.Variable = 0
+	for _, pubKey := range ring {
+		var err error
+		if digest, err = hFn(api, digest, pubKey.A.X, pubKey.A.Y); err != nil {
+			return nil, err
+		}
+	}
+	return digest, nil
+}
+
+// toRTE converts a point in TwistedEdwards format to Reduced TwistedEdwards
+// format.
+func toRTE(api frontend.API, p twistededwards.Point) twistededwards.Point {
+	x, y := format.FromTEtoRTE(api, p.X, p.Y)
+	return twistededwards.Point{X: x, Y: y}
+}
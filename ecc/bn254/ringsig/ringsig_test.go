@@ -0,0 +1,116 @@
+package ringsig
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/profile"
+	"github.com/consensys/gnark/std/algebra/native/twistededwards"
+	"github.com/consensys/gnark/test"
+	qt "github.com/frankban/quicktest"
+	"github.com/iden3/go-iden3-crypto/babyjub"
+	"github.com/iden3/go-iden3-crypto/poseidon"
+	"github.com/vocdoni/gnark-crypto-primitives/ecc/bn254/eddsa"
+	"github.com/vocdoni/gnark-crypto-primitives/utils"
+)
+
+const testRingSize = 4
+
+type testRingSignatureCircuit struct {
+	Ring      [testRingSize]eddsa.PublicKey `gnark:",public"`
+	Message   frontend.Variable             `gnark:",public"`
+	Signature Signature
+}
+
+func (c *testRingSignatureCircuit) Define(api frontend.API) error {
+	return Verify(api, utils.PoseidonHasher, c.Ring[:], c.Message, c.Signature)
+}
+
+// testRing generates a ring of random key pairs and returns the private keys
+// and the public keys.
+func testRing(c *qt.C, size int) ([]*big.Int, []*babyjub.PublicKey) {
+	privKeys := make([]*big.Int, size)
+	ring := make([]*babyjub.PublicKey, size)
+	for i := range ring {
+		var err error
+		privKeys[i], err = rand.Int(rand.Reader, babyjub.SubOrder)
+		c.Assert(err, qt.IsNil)
+		ring[i] = (*babyjub.PublicKey)(babyjub.NewPoint().Mul(privKeys[i], babyjub.B8))
+	}
+	return privKeys, ring
+}
+
+func TestRingSignatureNative(t *testing.T) {
+	c := qt.New(t)
+	privKeys, ring := testRing(c, testRingSize)
+	msg := big.NewInt(42)
+	for index := range ring {
+		sig, err := Sign(poseidon.Hash, ring, index, privKeys[index], msg)
+		c.Assert(err, qt.IsNil)
+		c.Assert(sig.Verify(poseidon.Hash, ring, msg), qt.IsNil)
+		c.Assert(sig.Verify(poseidon.Hash, ring, big.NewInt(43)), qt.IsNotNil)
+	}
+	// the private key must match the signer public key
+	_, err := Sign(poseidon.Hash, ring, 0, privKeys[1], msg)
+	c.Assert(err, qt.IsNotNil)
+	// the key image does not depend on the ring nor the message
+	_, otherRing := testRing(c, 2)
+	otherRing[1] = ring[0]
+	sig1, err := Sign(poseidon.Hash, ring, 0, privKeys[0], msg)
+	c.Assert(err, qt.IsNil)
+	sig2, err := Sign(poseidon.Hash, otherRing, 1, privKeys[0], big.NewInt(7))
+	c.Assert(err, qt.IsNil)
+	c.Assert(sig1.KeyImage.Compress(), qt.Equals, sig2.KeyImage.Compress())
+	// the base of the key images is in the prime order subgroup
+	hp, err := HashToPoint(poseidon.Hash, ring[0])
+	c.Assert(err, qt.IsNil)
+	c.Assert(hp.InSubGroup(), qt.IsTrue)
+}
+
+func TestRingSignature(t *testing.T) {
+	c := qt.New(t)
+	// profiling the circuit compilation
+	circuit := &testRingSignatureCircuit{Signature: NewSignature(testRingSize)}
+	p := profile.Start()
+	now := time.Now()
+	_, _ = frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, circuit)
+	fmt.Println("elapsed", time.Since(now))
+	p.Stop()
+	fmt.Println("constrains", p.NbConstraints())
+
+	privKeys, ring := testRing(c, testRingSize)
+	msg := big.NewInt(42)
+	sig, err := Sign(poseidon.Hash, ring, 2, privKeys[2], msg)
+	c.Assert(err, qt.IsNil)
+	witness := &testRingSignatureCircuit{Message: msg, Signature: SignatureFromNative(sig)}
+	for i, pubKey := range ring {
+		witness.Ring[i] = eddsa.PublicKeyFromIden3(pubKey)
+	}
+	// other message must fail
+	invalidMsg := *witness
+	invalidMsg.Message = big.NewInt(43)
+	// a key image outside of the prime order subgroup must fail: add the
+	// point of order two (0, -1), which is (-x, -y)
+	invalidKeyImage := *witness
+	invalidKeyImage.Signature = SignatureFromNative(sig)
+	invalidKeyImage.Signature.KeyImage = twistededwards.Point{
+		X: new(big.Int).Sub(ecc.BN254.ScalarField(), sig.KeyImage.X),
+		Y: new(big.Int).Sub(ecc.BN254.ScalarField(), sig.KeyImage.Y),
+	}
+
+	assert := test.NewAssert(t)
+	assert.CheckCircuit(circuit,
+		test.WithValidAssignment(witness),
+		test.WithInvalidAssignment(&invalidMsg),
+		test.WithInvalidAssignment(&invalidKeyImage),
+		test.WithCurves(ecc.BN254),
+		test.WithBackends(backend.GROTH16),
+	)
+}
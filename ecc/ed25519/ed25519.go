@@ -0,0 +1,124 @@
+// ed25519 package implements the verification of Ed25519 signatures in gnark
+// using emulated arithmetic over the 2^255-19 field, with the same semantics
+// of crypto/ed25519.Verify:
+//
+//   - the public key is decoded accepting non-canonical Y encodings, like
+//     the Go implementation does.
+//   - the S component of the signature must be canonical (S < l).
+//   - the challenge is k = SHA-512(R ‖ A ‖ M) mod l.
+//   - the cofactorless equation is checked by encoding [S]B - [k]A and
+//     comparing it with the R bytes of the signature.
+package ed25519
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/bits"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/vocdoni/gnark-crypto-primitives/hash/sha512"
+	"github.com/vocdoni/gnark-crypto-primitives/utils"
+)
+
+const (
+	// PublicKeySize is the size of an Ed25519 public key in bytes.
+	PublicKeySize = ed25519.PublicKeySize
+	// SignatureSize is the size of an Ed25519 signature in bytes.
+	SignatureSize = ed25519.SignatureSize
+	// scalarBits is the number of bits of the scalars (l < 2^253).
+	scalarBits = 253
+)
+
+// Verify asserts that sig is a valid Ed25519 signature of msg by pubKey. The
+// public key must be 32 bytes and the signature 64 bytes, as encoded by
+// crypto/ed25519.
+func Verify(api frontend.API, pubKey, sig, msg utils.Bytes) error {
+	if len(pubKey) != PublicKeySize {
+		return fmt.Errorf("invalid public key length: %d", len(pubKey))
+	}
+	if len(sig) != SignatureSize {
+		return fmt.Errorf("invalid signature length: %d", len(sig))
+	}
+	c, err := newCurve(api)
+	if err != nil {
+		return fmt.Errorf("error initializing the curve: %w", err)
+	}
+	fr, err := emulated.NewField[Fr](api)
+	if err != nil {
+		return fmt.Errorf("error initializing the scalar field: %w", err)
+	}
+	// decode the public key A
+	A, err := decodePoint(c, bytesToBits(api, pubKey))
+	if err != nil {
+		return err
+	}
+	rBits := bytesToBits(api, sig[:32])
+	sBits := bytesToBits(api, sig[32:])
+	// S must be canonical, which also ensures that the bits over scalarBits
+	// are zero
+	fr.AssertIsInRange(fr.FromBits(sBits...))
+	// k = SHA-512(R ‖ A ‖ M) mod l, the digest is a little-endian integer
+	data := append(append(append(utils.Bytes{}, sig[:32]...), pubKey...), msg...)
+	digest, err := sha512.Sum(api, data)
+	if err != nil {
+		return err
+	}
+	digestBits := bytesToBits(api, digest)
+	lo := fr.FromBits(digestBits[:256]...)
+	hi := fr.FromBits(digestBits[256:]...)
+	k := fr.Add(lo, fr.Mul(hi, fr.NewElement(new(big.Int).Lsh(big.NewInt(1), 256))))
+	kBits := fr.ToBitsCanonical(k)
+	// R' = [S]B - [k]A
+	R := c.doubleBaseScalarMul(c.base(), c.neg(A), sBits[:scalarBits], kBits[:scalarBits])
+	// encode R' and compare it with the R bytes of the signature
+	yBits := c.fp.ToBitsCanonical(R.Y)
+	for i := range 255 {
+		api.AssertIsEqual(rBits[i], yBits[i])
+	}
+	api.AssertIsEqual(rBits[255], c.fp.ToBitsCanonical(R.X)[0])
+	return nil
+}
+
+// decodePoint decodes the point encoded in the 256 little-endian bits
+// provided: the Y coordinate in the first 255 bits (non-canonical values are
+// accepted) and the sign of X in the last one. The X coordinate is provided
+// by a hint and constrained to satisfy the curve equation
+// x²·(d·y² + 1) = y² - 1.
+func decodePoint(c *curve, encoded []frontend.Variable) (*point, error) {
+	y := c.fp.FromBits(encoded[:255]...)
+	res, err := c.fp.NewHint(DecompressXHint, 1, y)
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing the point: %w", err)
+	}
+	x := res[0]
+	y2 := c.fp.Mul(y, y)
+	lhs := c.fp.Mul(c.fp.Mul(x, x), c.fp.Add(c.fp.Mul(c.d, y2), c.fp.One()))
+	c.fp.AssertIsEqual(lhs, c.fp.Sub(y2, c.fp.One()))
+	// the hint returns the even root, negate it if the sign bit is set
+	c.api.AssertIsEqual(c.fp.ToBitsCanonical(x)[0], 0)
+	return &point{X: c.fp.Select(encoded[255], c.fp.Neg(x), x), Y: y}, nil
+}
+
+// bytesToBits returns the little-endian bits of the bytes provided, which
+// also constrains every byte to 8 bits.
+func bytesToBits(api frontend.API, b utils.Bytes) []frontend.Variable {
+	res := make([]frontend.Variable, 0, len(b)*8)
+	for _, v := range b {
+		res = append(res, bits.ToBinary(api, v.Val, bits.WithNbDigits(8))...)
+	}
+	return res
+}
+
+// PublicKeyFromNative converts a crypto/ed25519 public key to the in-circuit
+// bytes expected by Verify.
+func PublicKeyFromNative(pubKey ed25519.PublicKey) utils.Bytes {
+	return utils.BytesFromSlice(pubKey, PublicKeySize)
+}
+
+// SignatureFromNative converts a crypto/ed25519 signature to the in-circuit
+// bytes expected by Verify.
+func SignatureFromNative(sig []byte) utils.Bytes {
+	return utils.BytesFromSlice(sig, SignatureSize)
+}
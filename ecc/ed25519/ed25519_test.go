@@ -0,0 +1,83 @@
+package ed25519
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/profile"
+	"github.com/consensys/gnark/std/math/uints"
+	"github.com/consensys/gnark/test"
+	qt "github.com/frankban/quicktest"
+	"github.com/vocdoni/gnark-crypto-primitives/utils"
+)
+
+type testEd25519Circuit struct {
+	PublicKey [PublicKeySize]uints.U8
+	Signature [SignatureSize]uints.U8
+	Message   [32]uints.U8
+}
+
+func (c *testEd25519Circuit) Define(api frontend.API) error {
+	return Verify(api, c.PublicKey[:], c.Signature[:], c.Message[:])
+}
+
+// reverse returns a copy of b in reverse order, to convert between the
+// little-endian encoding of Ed25519 and big.Int.
+func reverse(b []byte) []byte {
+	res := make([]byte, len(b))
+	for i := range b {
+		res[len(b)-1-i] = b[i]
+	}
+	return res
+}
+
+func TestVerify(t *testing.T) {
+	c := qt.New(t)
+	pubKey, privKey, err := ed25519.GenerateKey(rand.Reader)
+	c.Assert(err, qt.IsNil)
+	msg := make([]byte, 32)
+	_, err = rand.Read(msg)
+	c.Assert(err, qt.IsNil)
+	sig := ed25519.Sign(privKey, msg)
+	c.Assert(ed25519.Verify(pubKey, msg, sig), qt.IsTrue)
+
+	p := profile.Start()
+	now := time.Now()
+	_, _ = frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &testEd25519Circuit{})
+	fmt.Println("elapsed", time.Since(now))
+	p.Stop()
+	fmt.Println("constrains", p.NbConstraints())
+
+	witness := &testEd25519Circuit{
+		PublicKey: [PublicKeySize]uints.U8(PublicKeyFromNative(pubKey)),
+		Signature: [SignatureSize]uints.U8(SignatureFromNative(sig)),
+		Message:   [32]uints.U8(utils.BytesFromSlice(msg, 32)),
+	}
+	// a different message must fail
+	invalidMsg := *witness
+	invalidMsg.Message[0] = uints.NewU8(msg[0] ^ 0xff)
+	// a non-canonical S (S + l) must fail, as crypto/ed25519 rejects it
+	s := new(big.Int).SetBytes(reverse(sig[32:]))
+	s.Add(s, frModulus)
+	malleable := append(append([]byte{}, sig[:32]...), reverse(s.FillBytes(make([]byte, 32)))...)
+	c.Assert(ed25519.Verify(pubKey, msg, malleable), qt.IsFalse)
+	invalidS := *witness
+	invalidS.Signature = [SignatureSize]uints.U8(SignatureFromNative(malleable))
+
+	assert := test.NewAssert(t)
+	assert.CheckCircuit(&testEd25519Circuit{},
+		test.WithValidAssignment(witness),
+		test.WithInvalidAssignment(&invalidMsg),
+		test.WithInvalidAssignment(&invalidS),
+		test.WithCurves(ecc.BN254),
+		test.WithBackends(backend.GROTH16),
+	)
+}
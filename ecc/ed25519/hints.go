@@ -0,0 +1,37 @@
+package ed25519
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark/constraint/solver"
+	"github.com/consensys/gnark/std/math/emulated"
+)
+
+func init() { solver.RegisterHint(DecompressXHint) }
+
+// DecompressXHint returns the even square root of (y² - 1) / (d·y² + 1) in
+// the emulated field, which is the X coordinate of the Ed25519 point with Y
+// coordinate in[0] and the sign bit unset.
+var DecompressXHint solver.Hint = func(nativeMod *big.Int, in, out []*big.Int) error {
+	return emulated.UnwrapHint(in, out, func(mod *big.Int, in, out []*big.Int) error {
+		y2 := new(big.Int).Mul(in[0], in[0])
+		u := new(big.Int).Sub(y2, big.NewInt(1))
+		v := new(big.Int).Mul(curveD, y2)
+		v.Add(v, big.NewInt(1)).Mod(v, mod)
+		if v.ModInverse(v, mod) == nil {
+			return fmt.Errorf("invalid y coordinate")
+		}
+		x2 := u.Mul(u, v)
+		x2.Mod(x2, mod)
+		x := new(big.Int).ModSqrt(x2, mod)
+		if x == nil {
+			return fmt.Errorf("no square root, the point is not on the curve")
+		}
+		if x.Bit(0) == 1 {
+			x.Sub(mod, x)
+		}
+		out[0].Set(x)
+		return nil
+	})
+}
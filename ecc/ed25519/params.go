@@ -0,0 +1,34 @@
+package ed25519
+
+import "math/big"
+
+var (
+	// fpModulus is the prime 2^255 - 19.
+	fpModulus, _ = new(big.Int).SetString("7fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffed", 16)
+	// frModulus is the order of the prime subgroup,
+	// 2^252 + 27742317777372353535851937790883648493.
+	frModulus, _ = new(big.Int).SetString("1000000000000000000000000000000014def9dea2f79cd65812631a5cf5d3ed", 16)
+	// curveD is the d parameter of the curve, -121665/121666 mod p.
+	curveD, _ = new(big.Int).SetString("52036cee2b6ffe738cc740797779e89800700a4d4141d8ab75eb4dca135978a3", 16)
+	// baseX and baseY are the coordinates of the base point B.
+	baseX, _ = new(big.Int).SetString("216936d3cd6e53fec0a4e231fdd6dc5c692cc7609525a7b2c9562d608f25d51a", 16)
+	baseY, _ = new(big.Int).SetString("6666666666666666666666666666666666666666666666666666666666666658", 16)
+)
+
+// Fp provides type parametrization for the emulation of the base field of
+// Ed25519, with 4 limbs of 64 bits.
+type Fp struct{}
+
+func (Fp) NbLimbs() uint     { return 4 }
+func (Fp) BitsPerLimb() uint { return 64 }
+func (Fp) IsPrime() bool     { return true }
+func (Fp) Modulus() *big.Int { return fpModulus }
+
+// Fr provides type parametrization for the emulation of the scalar field of
+// Ed25519 (the order of the prime subgroup), with 4 limbs of 64 bits.
+type Fr struct{}
+
+func (Fr) NbLimbs() uint     { return 4 }
+func (Fr) BitsPerLimb() uint { return 64 }
+func (Fr) IsPrime() bool     { return true }
+func (Fr) Modulus() *big.Int { return frModulus }
@@ -0,0 +1,93 @@
+package ed25519
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/emulated"
+)
+
+// point is an Ed25519 point in affine twisted Edwards coordinates.
+type point struct {
+	X, Y *emulated.Element[Fp]
+}
+
+// curve implements the Ed25519 point arithmetic (a = -1) over the emulated
+// base field. The addition formulas are complete because d is not a square,
+// so they work for any pair of points, including the identity (0, 1).
+type curve struct {
+	api frontend.API
+	fp  *emulated.Field[Fp]
+	d   *emulated.Element[Fp]
+}
+
+func newCurve(api frontend.API) (*curve, error) {
+	fp, err := emulated.NewField[Fp](api)
+	if err != nil {
+		return nil, err
+	}
+	return &curve{api: api, fp: fp, d: fp.NewElement(curveD)}, nil
+}
+
+// identity returns the neutral point (0, 1).
+func (c *curve) identity() *point {
+	return &point{X: c.fp.Zero(), Y: c.fp.One()}
+}
+
+// base returns the base point B.
+func (c *curve) base() *point {
+	return &point{X: c.fp.NewElement(baseX), Y: c.fp.NewElement(baseY)}
+}
+
+// neg returns -p = (-x, y).
+func (c *curve) neg(p *point) *point {
+	return &point{X: c.fp.Neg(p.X), Y: p.Y}
+}
+
+// add returns p + q:
+//
+//	x = (x1·y2 + y1·x2) / (1 + d·x1·x2·y1·y2)
+//	y = (y1·y2 + x1·x2) / (1 - d·x1·x2·y1·y2)
+func (c *curve) add(p, q *point) *point {
+	x1y2 := c.fp.Mul(p.X, q.Y)
+	y1x2 := c.fp.Mul(p.Y, q.X)
+	x1x2 := c.fp.Mul(p.X, q.X)
+	y1y2 := c.fp.Mul(p.Y, q.Y)
+	dxy := c.fp.Mul(c.d, c.fp.Mul(x1x2, y1y2))
+	x := c.fp.Div(c.fp.Add(x1y2, y1x2), c.fp.Add(c.fp.One(), dxy))
+	y := c.fp.Div(c.fp.Add(y1y2, x1x2), c.fp.Sub(c.fp.One(), dxy))
+	return &point{X: x, Y: y}
+}
+
+// double returns 2p, using the curve equation to remove d from the addition
+// formulas:
+//
+//	x = 2·x·y / (y² - x²)
+//	y = (y² + x²) / (2 - y² + x²)
+func (c *curve) double(p *point) *point {
+	xy := c.fp.Mul(p.X, p.Y)
+	x2 := c.fp.Mul(p.X, p.X)
+	y2 := c.fp.Mul(p.Y, p.Y)
+	x := c.fp.Div(c.fp.Add(xy, xy), c.fp.Sub(y2, x2))
+	y := c.fp.Div(c.fp.Add(y2, x2), c.fp.Sub(c.fp.NewElement(2), c.fp.Sub(y2, x2)))
+	return &point{X: x, Y: y}
+}
+
+// lookup2 returns p0 if b0 = b1 = 0, p1 if b0 = 1 and b1 = 0, p2 if b0 = 0
+// and b1 = 1 or p3 if b0 = b1 = 1.
+func (c *curve) lookup2(b0, b1 frontend.Variable, p0, p1, p2, p3 *point) *point {
+	return &point{
+		X: c.fp.Lookup2(b0, b1, p0.X, p1.X, p2.X, p3.X),
+		Y: c.fp.Lookup2(b0, b1, p0.Y, p1.Y, p2.Y, p3.Y),
+	}
+}
+
+// doubleBaseScalarMul returns [s1]p1 + [s2]p2 using the Straus-Shamir trick,
+// with the scalars provided as little-endian bits of the same length.
+func (c *curve) doubleBaseScalarMul(p1, p2 *point, s1, s2 []frontend.Variable) *point {
+	p12 := c.add(p1, p2)
+	res := c.identity()
+	for i := len(s1) - 1; i >= 0; i-- {
+		res = c.double(res)
+		res = c.add(res, c.lookup2(s1[i], s2[i], c.identity(), p1, p2, p12))
+	}
+	return res
+}
@@ -0,0 +1,37 @@
+package format
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark/constraint/solver"
+	"github.com/iden3/go-iden3-crypto/babyjub"
+)
+
+func init() { solver.RegisterHint(UnpackPointXHint) }
+
+// UnpackPointXHint returns the X coordinate of the BabyJubJub point in
+// TwistedEdwards format with Y coordinate in[0] and the sign in[1], which is
+// the square root of (1 - y²) / (a - d·y²) that is greater than (p-1)/2 if
+// the sign is 1, or the other one otherwise.
+var UnpackPointXHint solver.Hint = func(field *big.Int, in, out []*big.Int) error {
+	y2 := new(big.Int).Mul(in[0], in[0])
+	num := new(big.Int).Sub(big.NewInt(1), y2)
+	den := new(big.Int).Sub(babyjub.A, new(big.Int).Mul(babyjub.D, y2))
+	den.Mod(den, field)
+	if den.ModInverse(den, field) == nil {
+		return fmt.Errorf("invalid y coordinate")
+	}
+	x2 := num.Mul(num, den)
+	x2.Mod(x2, field)
+	x := new(big.Int).ModSqrt(x2, field)
+	if x == nil {
+		return fmt.Errorf("the point is not on the curve")
+	}
+	half := new(big.Int).Rsh(field, 1)
+	if (x.Cmp(half) > 0) != (in[1].Sign() != 0) {
+		x.Sub(field, x).Mod(x, field)
+	}
+	out[0].Set(x)
+	return nil
+}
@@ -0,0 +1,234 @@
+package format
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bn254"
+	"github.com/consensys/gnark/std/math/emulated"
+)
+
+// BabyJubJub in Montgomery format is B·v² = u³ + A·u² + u, with A = 168698
+// and B = 1, and in short Weierstrass format is y² = x³ + a·x + b, with
+// a = (3 - A²) / 3 and b = (2·A³ - 9·A) / 27. The maps between the formats
+// are:
+//
+//	TE -> Montgomery:         (u, v) = ((1 + y) / (1 - y), u / x)
+//	Montgomery -> TE:         (x, y) = (u / v, (u - 1) / (u + 1))
+//	Montgomery -> Weierstrass: (x, y) = (u + A/3, v)
+//	Weierstrass -> Montgomery: (u, v) = (x - A/3, y)
+//
+// The identity (0, 1) of the TwistedEdwards formats has no affine
+// representation in the other formats, so it is encoded as (0, 1) in
+// Montgomery format, which is not on the curve, and as (0, 0) in Weierstrass
+// format, which is the convention of Gnark. The point of order two (0, -1)
+// is mapped to (0, 0) in Montgomery format and (A/3, 0) in Weierstrass
+// format. The conversions do not check that the points are on the curve.
+var (
+	montgomeryA      = big.NewInt(168698)
+	montgomeryAThird *big.Int
+)
+
+func init() {
+	modulus := ecc.BN254.ScalarField()
+	montgomeryAThird = new(big.Int).ModInverse(big.NewInt(3), modulus)
+	montgomeryAThird.Mul(montgomeryAThird, montgomeryA).Mod(montgomeryAThird, modulus)
+}
+
+// FromTEtoMontgomery transforms a point (x, y) in TwistedEdwards format (Iden3
+// format) to Montgomery format, using native arithmetic.
+func FromTEtoMontgomery(api frontend.API, x, y frontend.Variable) (frontend.Variable, frontend.Variable) {
+	isIdentity := api.IsZero(api.Sub(1, y))
+	// avoid the divisions by zero of the identity and the point (0, -1)
+	u := api.Div(api.Add(1, y), api.Select(isIdentity, 1, api.Sub(1, y)))
+	v := api.Div(u, api.Select(api.IsZero(x), 1, x))
+	return api.Select(isIdentity, 0, u), api.Select(isIdentity, 1, v)
+}
+
+// FromMontgomerytoTE transforms a point (u, v) in Montgomery format to
+// TwistedEdwards format (Iden3 format), using native arithmetic.
+func FromMontgomerytoTE(api frontend.API, u, v frontend.Variable) (frontend.Variable, frontend.Variable) {
+	isInfinity := api.Mul(api.IsZero(u), api.IsZero(api.Sub(v, 1)))
+	// avoid the division by zero of the point (0, 0)
+	x := api.Div(u, api.Select(api.IsZero(v), 1, v))
+	y := api.Div(api.Sub(u, 1), api.Add(u, 1))
+	return x, api.Select(isInfinity, 1, y)
+}
+
+// FromMontgomerytoWeierstrass transforms a point (u, v) in Montgomery format
+// to short Weierstrass format, using native arithmetic.
+func FromMontgomerytoWeierstrass(api frontend.API, u, v frontend.Variable) (frontend.Variable, frontend.Variable) {
+	isInfinity := api.Mul(api.IsZero(u), api.IsZero(api.Sub(v, 1)))
+	x := api.Add(u, montgomeryAThird)
+	return api.Select(isInfinity, 0, x), api.Select(isInfinity, 0, v)
+}
+
+// FromWeierstrasstoMontgomery transforms a point (x, y) in short Weierstrass
+// format to Montgomery format, using native arithmetic.
+func FromWeierstrasstoMontgomery(api frontend.API, x, y frontend.Variable) (frontend.Variable, frontend.Variable) {
+	isInfinity := api.Mul(api.IsZero(x), api.IsZero(y))
+	u := api.Sub(x, montgomeryAThird)
+	return api.Select(isInfinity, 0, u), api.Select(isInfinity, 1, y)
+}
+
+// FromTEtoWeierstrass transforms a point (x, y) in TwistedEdwards format
+// (Iden3 format) to short Weierstrass format, using native arithmetic.
+func FromTEtoWeierstrass(api frontend.API, x, y frontend.Variable) (frontend.Variable, frontend.Variable) {
+	u, v := FromTEtoMontgomery(api, x, y)
+	return FromMontgomerytoWeierstrass(api, u, v)
+}
+
+// FromWeierstrasstoTE transforms a point (x, y) in short Weierstrass format
+// to TwistedEdwards format (Iden3 format), using native arithmetic.
+func FromWeierstrasstoTE(api frontend.API, x, y frontend.Variable) (frontend.Variable, frontend.Variable) {
+	u, v := FromWeierstrasstoMontgomery(api, x, y)
+	return FromMontgomerytoTE(api, u, v)
+}
+
+// FromRTEtoMontgomery transforms a point (x, y) in Reduced TwistedEdwards
+// format (Gnark format) to Montgomery format, using native arithmetic.
+func FromRTEtoMontgomery(api frontend.API, x, y frontend.Variable) (frontend.Variable, frontend.Variable) {
+	x, y = FromRTEtoTE(api, x, y)
+	return FromTEtoMontgomery(api, x, y)
+}
+
+// FromMontgomerytoRTE transforms a point (u, v) in Montgomery format to
+// Reduced TwistedEdwards format (Gnark format), using native arithmetic.
+func FromMontgomerytoRTE(api frontend.API, u, v frontend.Variable) (frontend.Variable, frontend.Variable) {
+	x, y := FromMontgomerytoTE(api, u, v)
+	return FromTEtoRTE(api, x, y)
+}
+
+// FromRTEtoWeierstrass transforms a point (x, y) in Reduced TwistedEdwards
+// format (Gnark format) to short Weierstrass format, using native arithmetic.
+func FromRTEtoWeierstrass(api frontend.API, x, y frontend.Variable) (frontend.Variable, frontend.Variable) {
+	u, v := FromRTEtoMontgomery(api, x, y)
+	return FromMontgomerytoWeierstrass(api, u, v)
+}
+
+// FromWeierstrasstoRTE transforms a point (x, y) in short Weierstrass format
+// to Reduced TwistedEdwards format (Gnark format), using native arithmetic.
+func FromWeierstrasstoRTE(api frontend.API, x, y frontend.Variable) (frontend.Variable, frontend.Variable) {
+	x, y = FromWeierstrasstoTE(api, x, y)
+	return FromTEtoRTE(api, x, y)
+}
+
+// FromEmulatedTEtoMontgomery transforms a point (x, y) in TwistedEdwards
+// format (Iden3 format) to Montgomery format, using emulated arithmetic.
+func FromEmulatedTEtoMontgomery(api frontend.API, x, y emulated.Element[sw_bn254.ScalarField]) (emulated.Element[sw_bn254.ScalarField], emulated.Element[sw_bn254.ScalarField], error) {
+	field, err := emulated.NewField[sw_bn254.ScalarField](api)
+	if err != nil {
+		return emulated.Element[sw_bn254.ScalarField]{}, emulated.Element[sw_bn254.ScalarField]{}, err
+	}
+	one := field.One()
+	den := field.Sub(one, &y)
+	isIdentity := field.IsZero(den)
+	// avoid the divisions by zero of the identity and the point (0, -1)
+	u := field.Div(field.Add(one, &y), field.Select(isIdentity, one, den))
+	v := field.Div(u, field.Select(field.IsZero(&x), one, &x))
+	return *field.Select(isIdentity, field.Zero(), u), *field.Select(isIdentity, one, v), nil
+}
+
+// FromEmulatedMontgomerytoTE transforms a point (u, v) in Montgomery format
+// to TwistedEdwards format (Iden3 format), using emulated arithmetic.
+func FromEmulatedMontgomerytoTE(api frontend.API, u, v emulated.Element[sw_bn254.ScalarField]) (emulated.Element[sw_bn254.ScalarField], emulated.Element[sw_bn254.ScalarField], error) {
+	field, err := emulated.NewField[sw_bn254.ScalarField](api)
+	if err != nil {
+		return emulated.Element[sw_bn254.ScalarField]{}, emulated.Element[sw_bn254.ScalarField]{}, err
+	}
+	one := field.One()
+	isInfinity := api.Mul(field.IsZero(&u), field.IsZero(field.Sub(&v, one)))
+	// avoid the division by zero of the point (0, 0)
+	x := field.Div(&u, field.Select(field.IsZero(&v), one, &v))
+	y := field.Div(field.Sub(&u, one), field.Add(&u, one))
+	return *x, *field.Select(isInfinity, one, y), nil
+}
+
+// FromEmulatedMontgomerytoWeierstrass transforms a point (u, v) in Montgomery
+// format to short Weierstrass format, using emulated arithmetic.
+func FromEmulatedMontgomerytoWeierstrass(api frontend.API, u, v emulated.Element[sw_bn254.ScalarField]) (emulated.Element[sw_bn254.ScalarField], emulated.Element[sw_bn254.ScalarField], error) {
+	field, err := emulated.NewField[sw_bn254.ScalarField](api)
+	if err != nil {
+		return emulated.Element[sw_bn254.ScalarField]{}, emulated.Element[sw_bn254.ScalarField]{}, err
+	}
+	isInfinity := api.Mul(field.IsZero(&u), field.IsZero(field.Sub(&v, field.One())))
+	x := field.Add(&u, field.NewElement(montgomeryAThird))
+	return *field.Select(isInfinity, field.Zero(), x), *field.Select(isInfinity, field.Zero(), &v), nil
+}
+
+// FromEmulatedWeierstrasstoMontgomery transforms a point (x, y) in short
+// Weierstrass format to Montgomery format, using emulated arithmetic.
+func FromEmulatedWeierstrasstoMontgomery(api frontend.API, x, y emulated.Element[sw_bn254.ScalarField]) (emulated.Element[sw_bn254.ScalarField], emulated.Element[sw_bn254.ScalarField], error) {
+	field, err := emulated.NewField[sw_bn254.ScalarField](api)
+	if err != nil {
+		return emulated.Element[sw_bn254.ScalarField]{}, emulated.Element[sw_bn254.ScalarField]{}, err
+	}
+	isInfinity := api.Mul(field.IsZero(&x), field.IsZero(&y))
+	u := field.Sub(&x, field.NewElement(montgomeryAThird))
+	return *field.Select(isInfinity, field.Zero(), u), *field.Select(isInfinity, field.One(), &y), nil
+}
+
+// FromEmulatedTEtoWeierstrass transforms a point (x, y) in TwistedEdwards
+// format (Iden3 format) to short Weierstrass format, using emulated
+// arithmetic.
+func FromEmulatedTEtoWeierstrass(api frontend.API, x, y emulated.Element[sw_bn254.ScalarField]) (emulated.Element[sw_bn254.ScalarField], emulated.Element[sw_bn254.ScalarField], error) {
+	u, v, err := FromEmulatedTEtoMontgomery(api, x, y)
+	if err != nil {
+		return emulated.Element[sw_bn254.ScalarField]{}, emulated.Element[sw_bn254.ScalarField]{}, err
+	}
+	return FromEmulatedMontgomerytoWeierstrass(api, u, v)
+}
+
+// FromEmulatedWeierstrasstoTE transforms a point (x, y) in short Weierstrass
+// format to TwistedEdwards format (Iden3 format), using emulated arithmetic.
+func FromEmulatedWeierstrasstoTE(api frontend.API, x, y emulated.Element[sw_bn254.ScalarField]) (emulated.Element[sw_bn254.ScalarField], emulated.Element[sw_bn254.ScalarField], error) {
+	u, v, err := FromEmulatedWeierstrasstoMontgomery(api, x, y)
+	if err != nil {
+		return emulated.Element[sw_bn254.ScalarField]{}, emulated.Element[sw_bn254.ScalarField]{}, err
+	}
+	return FromEmulatedMontgomerytoTE(api, u, v)
+}
+
+// FromEmulatedRTEtoMontgomery transforms a point (x, y) in Reduced
+// TwistedEdwards format (Gnark format) to Montgomery format, using emulated
+// arithmetic.
+func FromEmulatedRTEtoMontgomery(api frontend.API, x, y emulated.Element[sw_bn254.ScalarField]) (emulated.Element[sw_bn254.ScalarField], emulated.Element[sw_bn254.ScalarField], error) {
+	x, y, err := FromEmulatedRTEtoTE(api, x, y)
+	if err != nil {
+		return emulated.Element[sw_bn254.ScalarField]{}, emulated.Element[sw_bn254.ScalarField]{}, err
+	}
+	return FromEmulatedTEtoMontgomery(api, x, y)
+}
+
+// FromEmulatedMontgomerytoRTE transforms a point (u, v) in Montgomery format
+// to Reduced TwistedEdwards format (Gnark format), using emulated arithmetic.
+func FromEmulatedMontgomerytoRTE(api frontend.API, u, v emulated.Element[sw_bn254.ScalarField]) (emulated.Element[sw_bn254.ScalarField], emulated.Element[sw_bn254.ScalarField], error) {
+	x, y, err := FromEmulatedMontgomerytoTE(api, u, v)
+	if err != nil {
+		return emulated.Element[sw_bn254.ScalarField]{}, emulated.Element[sw_bn254.ScalarField]{}, err
+	}
+	return FromEmulatedTEtoRTE(api, x, y)
+}
+
+// FromEmulatedRTEtoWeierstrass transforms a point (x, y) in Reduced
+// TwistedEdwards format (Gnark format) to short Weierstrass format, using
+// emulated arithmetic.
+func FromEmulatedRTEtoWeierstrass(api frontend.API, x, y emulated.Element[sw_bn254.ScalarField]) (emulated.Element[sw_bn254.ScalarField], emulated.Element[sw_bn254.ScalarField], error) {
+	x, y, err := FromEmulatedRTEtoTE(api, x, y)
+	if err != nil {
+		return emulated.Element[sw_bn254.ScalarField]{}, emulated.Element[sw_bn254.ScalarField]{}, err
+	}
+	return FromEmulatedTEtoWeierstrass(api, x, y)
+}
+
+// FromEmulatedWeierstrasstoRTE transforms a point (x, y) in short
+// Weierstrass format to Reduced TwistedEdwards format (Gnark format), using
+// emulated arithmetic.
+func FromEmulatedWeierstrasstoRTE(api frontend.API, x, y emulated.Element[sw_bn254.ScalarField]) (emulated.Element[sw_bn254.ScalarField], emulated.Element[sw_bn254.ScalarField], error) {
+	x, y, err := FromEmulatedWeierstrasstoTE(api, x, y)
+	if err != nil {
+		return emulated.Element[sw_bn254.ScalarField]{}, emulated.Element[sw_bn254.ScalarField]{}, err
+	}
+	return FromEmulatedTEtoRTE(api, x, y)
+}
@@ -0,0 +1,153 @@
+package format
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/profile"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bn254"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/test"
+	qt "github.com/frankban/quicktest"
+	"github.com/iden3/go-iden3-crypto/babyjub"
+	"github.com/vocdoni/davinci-node/crypto/ecc/format"
+)
+
+type testMontgomeryWeierstrass struct {
+	X, Y, RX, RY, U, V, WX, WY frontend.Variable
+
+	EX, EY, ERX, ERY, EU, EV, EWX, EWY emulated.Element[sw_bn254.ScalarField]
+}
+
+func (c *testMontgomeryWeierstrass) Define(api frontend.API) error {
+	// assertPoint returns a function that asserts that the point (x, y) is
+	// equal to the point provided
+	assertPoint := func(x, y frontend.Variable) func(frontend.Variable, frontend.Variable) {
+		return func(expectedX, expectedY frontend.Variable) {
+			api.AssertIsEqual(x, expectedX)
+			api.AssertIsEqual(y, expectedY)
+		}
+	}
+	// native arithmetic
+	assertPoint(FromTEtoMontgomery(api, c.X, c.Y))(c.U, c.V)
+	assertPoint(FromMontgomerytoTE(api, c.U, c.V))(c.X, c.Y)
+	assertPoint(FromMontgomerytoWeierstrass(api, c.U, c.V))(c.WX, c.WY)
+	assertPoint(FromWeierstrasstoMontgomery(api, c.WX, c.WY))(c.U, c.V)
+	assertPoint(FromTEtoWeierstrass(api, c.X, c.Y))(c.WX, c.WY)
+	assertPoint(FromWeierstrasstoTE(api, c.WX, c.WY))(c.X, c.Y)
+	assertPoint(FromRTEtoMontgomery(api, c.RX, c.RY))(c.U, c.V)
+	assertPoint(FromMontgomerytoRTE(api, c.U, c.V))(c.RX, c.RY)
+	assertPoint(FromRTEtoWeierstrass(api, c.RX, c.RY))(c.WX, c.WY)
+	assertPoint(FromWeierstrasstoRTE(api, c.WX, c.WY))(c.RX, c.RY)
+	// emulated arithmetic
+	field, err := emulated.NewField[sw_bn254.ScalarField](api)
+	if err != nil {
+		return err
+	}
+	type conversion func(frontend.API, emulated.Element[sw_bn254.ScalarField], emulated.Element[sw_bn254.ScalarField]) (emulated.Element[sw_bn254.ScalarField], emulated.Element[sw_bn254.ScalarField], error)
+	for _, tc := range []struct {
+		fn               conversion
+		x, y, expX, expY *emulated.Element[sw_bn254.ScalarField]
+	}{
+		{FromEmulatedTEtoMontgomery, &c.EX, &c.EY, &c.EU, &c.EV},
+		{FromEmulatedMontgomerytoTE, &c.EU, &c.EV, &c.EX, &c.EY},
+		{FromEmulatedMontgomerytoWeierstrass, &c.EU, &c.EV, &c.EWX, &c.EWY},
+		{FromEmulatedWeierstrasstoMontgomery, &c.EWX, &c.EWY, &c.EU, &c.EV},
+		{FromEmulatedTEtoWeierstrass, &c.EX, &c.EY, &c.EWX, &c.EWY},
+		{FromEmulatedWeierstrasstoTE, &c.EWX, &c.EWY, &c.EX, &c.EY},
+		{FromEmulatedRTEtoMontgomery, &c.ERX, &c.ERY, &c.EU, &c.EV},
+		{FromEmulatedMontgomerytoRTE, &c.EU, &c.EV, &c.ERX, &c.ERY},
+		{FromEmulatedRTEtoWeierstrass, &c.ERX, &c.ERY, &c.EWX, &c.EWY},
+		{FromEmulatedWeierstrasstoRTE, &c.EWX, &c.EWY, &c.ERX, &c.ERY},
+	} {
+		x, y, err := tc.fn(api, *tc.x, *tc.y)
+		if err != nil {
+			return err
+		}
+		field.AssertIsEqual(&x, tc.expX)
+		field.AssertIsEqual(&y, tc.expY)
+	}
+	return nil
+}
+
+// teToMontgomeryWeierstrass returns the Montgomery and short Weierstrass
+// representations of a BabyJubJub point in TwistedEdwards format, calculated
+// outside of the circuit with the maps of the montgomery.go documentation.
+func teToMontgomeryWeierstrass(x, y *big.Int) (*big.Int, *big.Int, *big.Int, *big.Int) {
+	p := ecc.BN254.ScalarField()
+	if x.Sign() == 0 && y.Cmp(big.NewInt(1)) == 0 {
+		return big.NewInt(0), big.NewInt(1), big.NewInt(0), big.NewInt(0)
+	}
+	// u = (1 + y) / (1 - y), v = u / x
+	u := new(big.Int).Sub(big.NewInt(1), y)
+	u.ModInverse(u.Mod(u, p), p)
+	u.Mul(u, new(big.Int).Add(big.NewInt(1), y)).Mod(u, p)
+	v := big.NewInt(0)
+	if x.Sign() != 0 {
+		v.ModInverse(x, p).Mul(v, u).Mod(v, p)
+	}
+	// wx = u + A/3, wy = v
+	wx := new(big.Int).ModInverse(big.NewInt(3), p)
+	wx.Mul(wx, big.NewInt(168698)).Add(wx, u).Mod(wx, p)
+	return u, v, wx, new(big.Int).Set(v)
+}
+
+func TestMontgomeryWeierstrass(t *testing.T) {
+	c := qt.New(t)
+	p := ecc.BN254.ScalarField()
+	x, _ := new(big.Int).SetString("20284931487578954787250358776722960153090567235942462656834196519767860852891", 10)
+	y, _ := new(big.Int).SetString("21185575020764391300398134415668786804224896114060668011215204645513129497221", 10)
+	points := [][2]*big.Int{
+		{x, y},
+		{babyjub.B8.X, babyjub.B8.Y},
+		// identity
+		{big.NewInt(0), big.NewInt(1)},
+		// point of order two
+		{big.NewInt(0), new(big.Int).Sub(p, big.NewInt(1))},
+	}
+	// profiling the circuit compilation
+	prof := profile.Start()
+	now := time.Now()
+	_, _ = frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &testMontgomeryWeierstrass{})
+	fmt.Println("elapsed", time.Since(now))
+	prof.Stop()
+	fmt.Println("constrains", prof.NbConstraints())
+
+	montA := big.NewInt(168698)
+	// a = (3 - A²) / 3, b = (2·A³ - 9·A) / 27
+	weierstrassA := new(big.Int).Sub(big.NewInt(3), new(big.Int).Mul(montA, montA))
+	weierstrassA.Mul(weierstrassA, new(big.Int).ModInverse(big.NewInt(3), p)).Mod(weierstrassA, p)
+	weierstrassB := new(big.Int).Exp(montA, big.NewInt(3), nil)
+	weierstrassB.Mul(weierstrassB, big.NewInt(2)).Sub(weierstrassB, new(big.Int).Mul(montA, big.NewInt(9)))
+	weierstrassB.Mul(weierstrassB, new(big.Int).ModInverse(big.NewInt(27), p)).Mod(weierstrassB, p)
+	opts := []test.TestingOption{test.WithCurves(ecc.BN254), test.WithBackends(backend.GROTH16)}
+	for i, point := range points {
+		u, v, wx, wy := teToMontgomeryWeierstrass(point[0], point[1])
+		if i < 2 {
+			// check the Montgomery equation v² = u³ + A·u² + u
+			lhs := new(big.Int).Mul(v, v)
+			rhs := new(big.Int).Exp(u, big.NewInt(3), p)
+			rhs.Add(rhs, new(big.Int).Mul(montA, new(big.Int).Mul(u, u))).Add(rhs, u)
+			c.Assert(lhs.Mod(lhs, p).Cmp(rhs.Mod(rhs, p)), qt.Equals, 0)
+			// check the Weierstrass equation wy² = wx³ + a·wx + b
+			lhs.Mul(wy, wy).Mod(lhs, p)
+			rhs.Exp(wx, big.NewInt(3), p).Add(rhs, new(big.Int).Mul(weierstrassA, wx)).Add(rhs, weierstrassB)
+			c.Assert(lhs.Cmp(rhs.Mod(rhs, p)), qt.Equals, 0)
+		}
+		rx, ry := format.FromTEtoRTE(point[0], point[1])
+		val := emulated.ValueOf[sw_bn254.ScalarField]
+		opts = append(opts, test.WithValidAssignment(&testMontgomeryWeierstrass{
+			X: point[0], Y: point[1], RX: rx, RY: ry, U: u, V: v, WX: wx, WY: wy,
+			EX: val(point[0]), EY: val(point[1]), ERX: val(rx), ERY: val(ry),
+			EU: val(u), EV: val(v), EWX: val(wx), EWY: val(wy),
+		}))
+	}
+	assert := test.NewAssert(t)
+	assert.CheckCircuit(&testMontgomeryWeierstrass{}, opts...)
+}
@@ -0,0 +1,107 @@
+package format
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/bits"
+	"github.com/consensys/gnark/std/math/uints"
+	"github.com/iden3/go-iden3-crypto/babyjub"
+	"github.com/vocdoni/gnark-crypto-primitives/utils"
+)
+
+// PackedPointLen is the length of a packed BabyJubJub point: the 32 bytes
+// of the Y coordinate in little-endian, with the sign of the X coordinate in
+// the most significant bit of the last byte.
+const PackedPointLen = 32
+
+var (
+	// teA and teD are the coefficients of the BabyJubJub curve equation in
+	// TwistedEdwards format: a·x² + y² = 1 + d·x²·y²
+	teA = frontend.Variable(168700)
+	teD = frontend.Variable(168696)
+)
+
+// PackPoint packs a BabyJubJub point (x, y) in TwistedEdwards format (Iden3
+// format) into 32 bytes, as circomlibjs babyJub.packPoint and Iden3
+// Point.Compress do. The Y coordinate is encoded in little-endian and the
+// most significant bit of the last byte is set if X is greater than (p-1)/2.
+func PackPoint(api frontend.API, x, y frontend.Variable) utils.Bytes {
+	// the canonical bits of y, the field has 254 bits so the last two bits
+	// of the last byte are free
+	yBits := bits.ToBinary(api, y)
+	yBits = append(yBits, 0, pointCoordSign(api, x))
+	packed := make(utils.Bytes, PackedPointLen)
+	for i := range packed {
+		packed[i] = uints.U8{Val: bits.FromBinary(api, yBits[i*8:(i+1)*8], bits.WithUnconstrainedInputs())}
+	}
+	return packed
+}
+
+// UnpackPoint unpacks a BabyJubJub point packed by PackPoint or circomlibjs
+// babyJub.packPoint, and returns its coordinates (x, y) in TwistedEdwards
+// format (Iden3 format). The X coordinate is provided by a hint as the square
+// root of (1 - y²) / (a - d·y²) with the packed sign, and the circuit checks
+// that the point is on the curve and that the sign of X matches. It fails if
+// the Y coordinate is not canonical. Use FromTEtoRTE to get the point in the
+// Gnark format.
+func UnpackPoint(api frontend.API, packed utils.Bytes) (frontend.Variable, frontend.Variable, error) {
+	if len(packed) != PackedPointLen {
+		return nil, nil, fmt.Errorf("invalid packed point length: %d", len(packed))
+	}
+	// decompose the bytes into bits, which also ensures that they are bytes
+	packedBits := make([]frontend.Variable, 0, PackedPointLen*8)
+	for _, b := range packed {
+		packedBits = append(packedBits, bits.ToBinary(api, b.Val, bits.WithNbDigits(8))...)
+	}
+	sign := packedBits[len(packedBits)-1]
+	yBits := packedBits[:len(packedBits)-1]
+	// y must be canonical, so its bits must be the same that the canonical
+	// decomposition returns, and the unused bit must be zero
+	y := bits.FromBinary(api, yBits, bits.WithUnconstrainedInputs())
+	canonical := bits.ToBinary(api, y, bits.WithNbDigits(len(yBits)))
+	for i := range yBits {
+		api.AssertIsEqual(canonical[i], yBits[i])
+	}
+	// compute x with the hint and check that the point is on the curve:
+	// x² · (a - d·y²) == 1 - y²
+	res, err := api.NewHint(UnpackPointXHint, 1, y, sign)
+	if err != nil {
+		return nil, nil, err
+	}
+	x := res[0]
+	y2 := api.Mul(y, y)
+	api.AssertIsEqual(api.Mul(x, x, api.Sub(teA, api.Mul(teD, y2))), api.Sub(1, y2))
+	api.AssertIsEqual(pointCoordSign(api, x), sign)
+	return x, y, nil
+}
+
+// PointToPacked packs a BabyJubJub point (x, y) in TwistedEdwards format
+// (Iden3 format) natively, which is the value that PackPoint returns in the
+// circuit.
+func PointToPacked(x, y *big.Int) []byte {
+	packed := babyjub.PackSignY(babyjub.PointCoordSign(x), y)
+	return packed[:]
+}
+
+// PointFromPacked unpacks a BabyJubJub point natively and returns its
+// coordinates (x, y) in TwistedEdwards format (Iden3 format). It returns an
+// error if the packed point is not valid.
+func PointFromPacked(packed []byte) (*big.Int, *big.Int, error) {
+	if len(packed) != PackedPointLen {
+		return nil, nil, fmt.Errorf("invalid packed point length: %d", len(packed))
+	}
+	point, err := babyjub.NewPoint().Decompress([PackedPointLen]byte(packed))
+	if err != nil {
+		return nil, nil, err
+	}
+	return point.X, point.Y, nil
+}
+
+// pointCoordSign returns 1 if the coordinate c is greater than (p-1)/2, which
+// is the sign convention of circomlibjs and Iden3, or 0 otherwise.
+func pointCoordSign(api frontend.API, c frontend.Variable) frontend.Variable {
+	half := new(big.Int).Rsh(api.Compiler().Field(), 1)
+	return api.IsZero(api.Sub(api.Cmp(c, half), 1))
+}
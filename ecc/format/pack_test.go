@@ -0,0 +1,101 @@
+package format
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/profile"
+	"github.com/consensys/gnark/std/math/uints"
+	"github.com/consensys/gnark/test"
+	qt "github.com/frankban/quicktest"
+	"github.com/iden3/go-iden3-crypto/babyjub"
+	"github.com/vocdoni/gnark-crypto-primitives/utils"
+)
+
+type testPackPoint struct {
+	X, Y   frontend.Variable
+	Packed [PackedPointLen]uints.U8 `gnark:",public"`
+}
+
+func (c *testPackPoint) Define(api frontend.API) error {
+	PackPoint(api, c.X, c.Y).AssertIsEqual(api, c.Packed[:])
+	x, y, err := UnpackPoint(api, c.Packed[:])
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(x, c.X)
+	api.AssertIsEqual(y, c.Y)
+	return nil
+}
+
+// randomPoint returns the public key of a new random BabyJubJub key pair.
+func randomPoint() *babyjub.Point {
+	privKey := babyjub.NewRandPrivKey()
+	return privKey.Public().Point()
+}
+
+func TestPackPointNative(t *testing.T) {
+	c := qt.New(t)
+	for range 8 {
+		point := randomPoint()
+		packed := PointToPacked(point.X, point.Y)
+		compressed := point.Compress()
+		c.Assert(packed, qt.DeepEquals, compressed[:])
+		x, y, err := PointFromPacked(packed)
+		c.Assert(err, qt.IsNil)
+		c.Assert(x.Cmp(point.X), qt.Equals, 0)
+		c.Assert(y.Cmp(point.Y), qt.Equals, 0)
+	}
+	_, _, err := PointFromPacked(make([]byte, PackedPointLen-1))
+	c.Assert(err, qt.IsNotNil)
+}
+
+func TestPackPoint(t *testing.T) {
+	// profiling the circuit compilation
+	p := profile.Start()
+	now := time.Now()
+	_, _ = frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &testPackPoint{})
+	fmt.Println("elapsed", time.Since(now))
+	p.Stop()
+	fmt.Println("constrains", p.NbConstraints())
+	// generate points until there is one with each sign of X
+	witnesses := map[bool]*testPackPoint{}
+	for len(witnesses) < 2 {
+		point := randomPoint()
+		witnesses[babyjub.PointCoordSign(point.X)] = &testPackPoint{
+			X:      point.X,
+			Y:      point.Y,
+			Packed: [PackedPointLen]uints.U8(utils.BytesFromSlice(PointToPacked(point.X, point.Y), PackedPointLen)),
+		}
+	}
+	// flipping the sign unpacks the point with the negated X
+	wrongSign := *witnesses[false]
+	wrongSign.Packed[PackedPointLen-1] = uints.NewU8(wrongSign.Packed[PackedPointLen-1].Val.(uint8) | 0x80)
+	// a Y coordinate without X on the curve
+	y := big.NewInt(2)
+	for {
+		if _, _, err := PointFromPacked(PointToPacked(big.NewInt(0), y)); err != nil {
+			break
+		}
+		y.Add(y, big.NewInt(1))
+	}
+	notOnCurve := *witnesses[false]
+	notOnCurve.Y = y
+	notOnCurve.Packed = [PackedPointLen]uints.U8(utils.BytesFromSlice(PointToPacked(big.NewInt(0), y), PackedPointLen))
+
+	assert := test.NewAssert(t)
+	assert.CheckCircuit(&testPackPoint{},
+		test.WithValidAssignment(witnesses[false]),
+		test.WithValidAssignment(witnesses[true]),
+		test.WithInvalidAssignment(&wrongSign),
+		test.WithInvalidAssignment(&notOnCurve),
+		test.WithCurves(ecc.BN254),
+		test.WithBackends(backend.GROTH16),
+	)
+}
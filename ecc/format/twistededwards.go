@@ -2,7 +2,9 @@
 // (x, y) from the TwistedEdwards format to Reduced TwistedEdwards format and
 // vice versa, over BabyJubJub curve. These functions are required because
 // Gnark uses the Reduced TwistedEdwards formula while Iden3 uses the standard
-// TwistedEdwards formula.
+// TwistedEdwards formula. It also includes the conversions to the Montgomery
+// and short Weierstrass formats, and the packing of BabyJubJub points
+// into 32 bytes compatible with Circomlib and Iden3.
 //
 // Read more about this here: https://github.com/bellesmarta/baby_jubjub
 package format
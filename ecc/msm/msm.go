@@ -0,0 +1,262 @@
+// msm package implements a multi-scalar multiplication gadget for the native
+// twisted edwards curves of gnark (BabyJubJub over BN254, Jubjub...), to
+// evaluate verification equations like S·B - h·A with fewer constraints than
+// computing every scalar multiplication independently:
+//
+//   - constant points (like the curve generator) use precomputed tables of
+//     windows of 3 bits, so they need no doublings and the selection of the
+//     table entries is linear on the products of the window bits.
+//   - variable points share the doublings of a single accumulator (Straus),
+//     using signed digits (±1) and joint tables of ±(P1 ± P2) for each pair
+//     of points (Shamir's trick).
+//   - a single variable point uses the scalar multiplication of the curve
+//     (fake GLV), which is cheaper when there are no doublings to share.
+//
+// The variable points must be in the prime order subgroup of the curve,
+// which is the case of the points multiplied by the cofactor, so the result
+// is the same for the scalars and their values modulo the order.
+package msm
+
+import (
+	"fmt"
+	"math/big"
+	"math/bits"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/native/twistededwards"
+)
+
+// windowSize is the number of bits of the windows of the fixed-base tables.
+const windowSize = 3
+
+// MultiScalarMul returns Σ scalars[i]·points[i]. The scalars can be any
+// native field element. See the package documentation for the requirements
+// on the points.
+func MultiScalarMul(curve twistededwards.Curve, points []twistededwards.Point, scalars []frontend.Variable) (twistededwards.Point, error) {
+	if len(points) != len(scalars) {
+		return twistededwards.Point{}, fmt.Errorf("mismatched number of points (%d) and scalars (%d)", len(points), len(scalars))
+	}
+	if len(points) == 0 {
+		return twistededwards.Point{}, fmt.Errorf("no points provided")
+	}
+	api := curve.API()
+	var varPoints []twistededwards.Point
+	var varScalars []frontend.Variable
+	var res *twistededwards.Point
+	accumulate := func(p twistededwards.Point) {
+		if res == nil {
+			res = &p
+			return
+		}
+		sum := curve.Add(*res, p)
+		res = &sum
+	}
+	for i, p := range points {
+		x, xConst := api.Compiler().ConstantValue(p.X)
+		y, yConst := api.Compiler().ConstantValue(p.Y)
+		if xConst && yConst {
+			accumulate(fixedBaseScalarMul(curve, x, y, scalars[i]))
+			continue
+		}
+		varPoints = append(varPoints, p)
+		varScalars = append(varScalars, scalars[i])
+	}
+	switch len(varPoints) {
+	case 0:
+	case 1:
+		accumulate(curve.ScalarMul(varPoints[0], varScalars[0]))
+	default:
+		accumulate(strausScalarMul(curve, varPoints, varScalars))
+	}
+	return *res, nil
+}
+
+// fixedBaseScalarMul returns [scalar](x, y) for a constant point, adding the
+// entries of the precomputed tables of every window of the scalar.
+func fixedBaseScalarMul(curve twistededwards.Curve, x, y *big.Int, scalar frontend.Variable) twistededwards.Point {
+	return ScalarMul(curve, scalar, NewTable(curve, twistededwards.Point{X: x, Y: y}))[0]
+}
+
+// ConstantScalarMul returns [scalar]p for a scalar known at compile time,
+// with fixed windows of the scalar: the multiples of p used by the windows
+// are computed once and there are no selections, so it costs a doubling per
+// bit of the scalar and an addition per non-zero window, which is much
+// cheaper than a variable scalar multiplication for small scalars. Unlike
+// the scalar multiplication of the curve, the point does not need to be in
+// the prime order subgroup.
+func ConstantScalarMul(curve twistededwards.Curve, p twistededwards.Point, scalar *big.Int) twistededwards.Point {
+	api := curve.API()
+	s := new(big.Int).Mod(scalar, api.Compiler().Field())
+	// multiples[d] = [d]p, computed on demand for the windows of the scalar
+	multiples := map[uint]twistededwards.Point{1: p}
+	var multiple func(d uint) twistededwards.Point
+	multiple = func(d uint) twistededwards.Point {
+		if m, ok := multiples[d]; ok {
+			return m
+		}
+		var m twistededwards.Point
+		if d%2 == 0 {
+			m = curve.Double(multiple(d / 2))
+		} else {
+			m = curve.Add(multiple(d-1), p)
+		}
+		multiples[d] = m
+		return m
+	}
+	res := twistededwards.Point{X: 0, Y: 1}
+	started := false
+	for i := (s.BitLen() + windowSize - 1) / windowSize; i > 0; i-- {
+		if started {
+			for range windowSize {
+				res = curve.Double(res)
+			}
+		}
+		var digit uint
+		for j := windowSize - 1; j >= 0; j-- {
+			digit = digit<<1 | s.Bit((i-1)*windowSize+j)
+		}
+		switch {
+		case digit == 0:
+		case !started:
+			res, started = multiple(digit), true
+		default:
+			res = curve.Add(res, multiple(digit))
+		}
+	}
+	return res
+}
+
+// windowTable returns the coefficients of the coordinates of [v]base, for
+// every value v of a window of nbBits bits, as multilinear polynomials on the
+// bits of the window, in the order of the monomials returned by bitMonomials.
+func windowTable(curve twistededwards.Curve, base [2]*big.Int, nbBits int) [][2]*big.Int {
+	field := curve.API().Compiler().Field()
+	// entries[v] = [v]base
+	entries := make([][2]*big.Int, 1<<nbBits)
+	entries[0] = [2]*big.Int{big.NewInt(0), big.NewInt(1)}
+	for v := 1; v < len(entries); v++ {
+		entries[v] = nativeAdd(curve, entries[v-1], base)
+	}
+	// the coefficient of the monomial of the bits in the mask m is the sum
+	// of the entries of the submasks of m with the sign of the parity of
+	// the removed bits (Möbius transform)
+	coeffs := make([][2]*big.Int, len(entries))
+	for m := range coeffs {
+		coeffs[m] = [2]*big.Int{new(big.Int), new(big.Int)}
+		for s := m; ; s = (s - 1) & m {
+			sign := 1
+			if bits.OnesCount(uint(m^s))%2 == 1 {
+				sign = -1
+			}
+			for c := range 2 {
+				term := new(big.Int).Mul(big.NewInt(int64(sign)), entries[s][c])
+				coeffs[m][c].Add(coeffs[m][c], term).Mod(coeffs[m][c], field)
+			}
+			if s == 0 {
+				break
+			}
+		}
+	}
+	return coeffs
+}
+
+// bitMonomials returns the products of every subset of the bits of the window,
+// where the position i of the result contains the product of the bits in
+// the mask i.
+func bitMonomials(api frontend.API, window []frontend.Variable) []frontend.Variable {
+	monomials := make([]frontend.Variable, 1<<len(window))
+	monomials[0] = 1
+	for i, b := range window {
+		for m := range 1 << i {
+			monomials[m|1<<i] = api.Mul(monomials[m], b)
+		}
+	}
+	return monomials
+}
+
+// strausScalarMul returns Σ scalars[i]·points[i] for variable points, using
+// signed digits and sharing the doublings of the accumulator. For an odd
+// scalar k of n bits, k = 2^(n-1) + Σ (2·k[i+1] - 1)·2^i for i < n-1, so
+// even scalars are computed as k+1 and the point is subtracted at the end.
+func strausScalarMul(curve twistededwards.Curve, points []twistededwards.Point, scalars []frontend.Variable) twistededwards.Point {
+	api := curve.API()
+	scalarBits := make([][]frontend.Variable, len(scalars))
+	for i, s := range scalars {
+		scalarBits[i] = api.ToBinary(s)
+	}
+	n := len(scalarBits[0])
+	// group the points in pairs and precompute P1 + P2 and P1 - P2, the
+	// last point is alone if the number of points is odd
+	type group struct {
+		bits1, bits2 []frontend.Variable
+		sum, diff    twistededwards.Point
+		single       bool
+	}
+	groups := make([]group, 0, (len(points)+1)/2)
+	for i := 0; i < len(points); i += 2 {
+		if i+1 == len(points) {
+			groups = append(groups, group{bits1: scalarBits[i], sum: points[i], single: true})
+			continue
+		}
+		groups = append(groups, group{
+			bits1: scalarBits[i],
+			bits2: scalarBits[i+1],
+			sum:   curve.Add(points[i], points[i+1]),
+			diff:  curve.Add(points[i], curve.Neg(points[i+1])),
+		})
+	}
+	// the most significant digits are 1
+	res := groups[0].sum
+	for _, g := range groups[1:] {
+		res = curve.Add(res, g.sum)
+	}
+	for i := n - 2; i >= 0; i-- {
+		res = curve.Double(res)
+		for _, g := range groups {
+			// d1·P1 + d2·P2 is d1·(P1 + P2) if d1 == d2 or d1·(P1 - P2)
+			// otherwise, and the negation only changes the X coordinate
+			entry := g.sum
+			if !g.single {
+				diffDigits := api.Xor(g.bits1[i+1], g.bits2[i+1])
+				entry.X = api.Select(diffDigits, g.diff.X, g.sum.X)
+				entry.Y = api.Select(diffDigits, g.diff.Y, g.sum.Y)
+			}
+			entry.X = api.Select(g.bits1[i+1], entry.X, api.Neg(entry.X))
+			res = curve.Add(res, entry)
+		}
+	}
+	// subtract the points of the even scalars
+	for i, p := range points {
+		isEven := api.Sub(1, scalarBits[i][0])
+		neg := twistededwards.Point{
+			X: api.Mul(isEven, api.Neg(p.X)),
+			Y: api.Select(isEven, p.Y, 1),
+		}
+		res = curve.Add(res, neg)
+	}
+	return res
+}
+
+// nativeAdd returns p + q using the parameters of the curve and the native
+// field of the circuit.
+func nativeAdd(curve twistededwards.Curve, p, q [2]*big.Int) [2]*big.Int {
+	field := curve.API().Compiler().Field()
+	params := curve.Params()
+	x1y2 := new(big.Int).Mul(p[0], q[1])
+	y1x2 := new(big.Int).Mul(p[1], q[0])
+	x1x2 := new(big.Int).Mul(p[0], q[0])
+	y1y2 := new(big.Int).Mul(p[1], q[1])
+	dxy := new(big.Int).Mul(params.D, x1x2)
+	dxy.Mul(dxy, y1y2).Mod(dxy, field)
+	// x = (x1·y2 + y1·x2) / (1 + d·x1·x2·y1·y2)
+	x := new(big.Int).Add(x1y2, y1x2)
+	den := new(big.Int).Add(big.NewInt(1), dxy)
+	x.Mul(x, den.ModInverse(den, field)).Mod(x, field)
+	// y = (y1·y2 - a·x1·x2) / (1 - d·x1·x2·y1·y2)
+	y := new(big.Int).Mul(params.A, x1x2)
+	y.Sub(y1y2, y)
+	den = new(big.Int).Sub(big.NewInt(1), dxy)
+	den.Mod(den, field)
+	y.Mul(y, den.ModInverse(den, field)).Mod(y, field)
+	return [2]*big.Int{x, y}
+}
@@ -0,0 +1,247 @@
+package msm
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	edbn254 "github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+	ecc_tw "github.com/consensys/gnark-crypto/ecc/twistededwards"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/std/algebra/native/twistededwards"
+	"github.com/consensys/gnark/test"
+	qt "github.com/frankban/quicktest"
+)
+
+type testMSMCircuit struct {
+	// the first scalar multiplies the base point of the curve, a constant
+	Points  []twistededwards.Point
+	Scalars []frontend.Variable
+	Result  twistededwards.Point `gnark:",public"`
+	// naive uses independent scalar multiplications to compare the number
+	// of constraints
+	naive bool
+}
+
+func newTestMSMCircuit(nbPoints int, naive bool) *testMSMCircuit {
+	return &testMSMCircuit{
+		Points:  make([]twistededwards.Point, nbPoints),
+		Scalars: make([]frontend.Variable, nbPoints+1),
+		naive:   naive,
+	}
+}
+
+func (c *testMSMCircuit) Define(api frontend.API) error {
+	curve, err := twistededwards.NewEdCurve(api, ecc_tw.BN254)
+	if err != nil {
+		return err
+	}
+	// empty and mismatched inputs
+	if _, err := MultiScalarMul(curve, nil, nil); err == nil {
+		return fmt.Errorf("expected error for empty inputs")
+	}
+	if _, err := MultiScalarMul(curve, c.Points, c.Scalars); err == nil {
+		return fmt.Errorf("expected error for mismatched inputs")
+	}
+	base := curve.Params().Base
+	points := append([]twistededwards.Point{{X: base[0], Y: base[1]}}, c.Points...)
+	var res twistededwards.Point
+	if c.naive {
+		res = curve.ScalarMul(points[0], c.Scalars[0])
+		for i := 1; i < len(points); i++ {
+			res = curve.Add(res, curve.ScalarMul(points[i], c.Scalars[i]))
+		}
+	} else if res, err = MultiScalarMul(curve, points, c.Scalars); err != nil {
+		return err
+	}
+	api.AssertIsEqual(res.X, c.Result.X)
+	api.AssertIsEqual(res.Y, c.Result.Y)
+	return nil
+}
+
+func TestMultiScalarMul(t *testing.T) {
+	c := qt.New(t)
+	params := edbn254.GetEdwardsCurve()
+	for nbPoints := range 4 {
+		c.Run(fmt.Sprintf("%d variable points", nbPoints), func(c *qt.C) {
+			witness := newTestMSMCircuit(nbPoints, false)
+			// random full field scalars, the second one even
+			var expected edbn254.PointAffine
+			expected.X.SetZero()
+			expected.Y.SetOne()
+			for i := range witness.Scalars {
+				k, err := rand.Int(rand.Reader, ecc.BN254.ScalarField())
+				c.Assert(err, qt.IsNil)
+				if i == 1 {
+					k.SetBit(k, 0, 0)
+				}
+				witness.Scalars[i] = k
+				// random points of the prime order subgroup
+				p := params.Base
+				if i > 0 {
+					r, err := rand.Int(rand.Reader, &params.Order)
+					c.Assert(err, qt.IsNil)
+					p.ScalarMultiplication(&params.Base, r)
+					witness.Points[i-1] = twistededwards.Point{X: p.X, Y: p.Y}
+				}
+				var term edbn254.PointAffine
+				term.ScalarMultiplication(&p, k)
+				expected.Add(&expected, &term)
+			}
+			witness.Result = twistededwards.Point{X: expected.X, Y: expected.Y}
+			invalid := newTestMSMCircuit(nbPoints, false)
+			copy(invalid.Points, witness.Points)
+			copy(invalid.Scalars, witness.Scalars)
+			invalid.Result = witness.Result
+			invalid.Scalars[nbPoints] = new(big.Int).Add(witness.Scalars[nbPoints].(*big.Int), big.NewInt(1))
+
+			msm, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, newTestMSMCircuit(nbPoints, false))
+			c.Assert(err, qt.IsNil)
+			naive, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, newTestMSMCircuit(nbPoints, true))
+			c.Assert(err, qt.IsNil)
+			fmt.Println("constrains msm", msm.GetNbConstraints(), "naive", naive.GetNbConstraints())
+			c.Assert(msm.GetNbConstraints() < naive.GetNbConstraints(), qt.IsTrue)
+
+			assert := test.NewAssert(t)
+			assert.CheckCircuit(newTestMSMCircuit(nbPoints, false),
+				test.WithValidAssignment(witness),
+				test.WithInvalidAssignment(invalid),
+				test.WithCurves(ecc.BN254),
+				test.WithBackends(backend.GROTH16),
+			)
+		})
+	}
+}
+
+type testScalarMulTablesCircuit struct {
+	// the first table is the base point of the curve, a constant
+	Points  [2]twistededwards.Point
+	Scalar  frontend.Variable
+	Results [3]twistededwards.Point `gnark:",public"`
+}
+
+func (c *testScalarMulTablesCircuit) Define(api frontend.API) error {
+	curve, err := twistededwards.NewEdCurve(api, ecc_tw.BN254)
+	if err != nil {
+		return err
+	}
+	base := curve.Params().Base
+	tables := []*Table{NewTable(curve, twistededwards.Point{X: base[0], Y: base[1]})}
+	for _, p := range c.Points {
+		tables = append(tables, NewTable(curve, p))
+	}
+	for i, res := range ScalarMul(curve, c.Scalar, tables...) {
+		api.AssertIsEqual(res.X, c.Results[i].X)
+		api.AssertIsEqual(res.Y, c.Results[i].Y)
+	}
+	return nil
+}
+
+func TestScalarMulTables(t *testing.T) {
+	c := qt.New(t)
+	params := edbn254.GetEdwardsCurve()
+	k, err := rand.Int(rand.Reader, ecc.BN254.ScalarField())
+	c.Assert(err, qt.IsNil)
+	r, err := rand.Int(rand.Reader, &params.Order)
+	c.Assert(err, qt.IsNil)
+	// a point of the prime order subgroup and a point out of it, adding the
+	// point of order 2
+	var p1, p2, lowOrder edbn254.PointAffine
+	p1.ScalarMultiplication(&params.Base, r)
+	lowOrder.X.SetZero()
+	lowOrder.Y.SetOne()
+	lowOrder.Y.Neg(&lowOrder.Y)
+	p2.Add(&p1, &lowOrder)
+	witness := &testScalarMulTablesCircuit{Scalar: k}
+	for i, p := range []edbn254.PointAffine{params.Base, p1, p2} {
+		var res edbn254.PointAffine
+		res.ScalarMultiplication(&p, k)
+		witness.Results[i] = twistededwards.Point{X: res.X, Y: res.Y}
+		if i > 0 {
+			witness.Points[i-1] = twistededwards.Point{X: p.X, Y: p.Y}
+		}
+	}
+	invalid := *witness
+	invalid.Scalar = new(big.Int).Add(k, big.NewInt(1))
+
+	cs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &testScalarMulTablesCircuit{})
+	c.Assert(err, qt.IsNil)
+	fmt.Println("constrains", cs.GetNbConstraints())
+
+	assert := test.NewAssert(t)
+	assert.CheckCircuit(&testScalarMulTablesCircuit{},
+		test.WithValidAssignment(witness),
+		test.WithInvalidAssignment(&invalid),
+		test.WithCurves(ecc.BN254),
+		test.WithBackends(backend.GROTH16),
+	)
+}
+
+type testConstantScalarMulCircuit struct {
+	Point   twistededwards.Point
+	Scalars []*big.Int             `gnark:"-"`
+	Results []twistededwards.Point `gnark:",public"`
+}
+
+func (c *testConstantScalarMulCircuit) Define(api frontend.API) error {
+	curve, err := twistededwards.NewEdCurve(api, ecc_tw.BN254)
+	if err != nil {
+		return err
+	}
+	for i, s := range c.Scalars {
+		res := ConstantScalarMul(curve, c.Point, s)
+		api.AssertIsEqual(res.X, c.Results[i].X)
+		api.AssertIsEqual(res.Y, c.Results[i].Y)
+	}
+	return nil
+}
+
+func TestConstantScalarMul(t *testing.T) {
+	c := qt.New(t)
+	params := edbn254.GetEdwardsCurve()
+	r, err := rand.Int(rand.Reader, &params.Order)
+	c.Assert(err, qt.IsNil)
+	k, err := rand.Int(rand.Reader, ecc.BN254.ScalarField())
+	c.Assert(err, qt.IsNil)
+	scalars := []*big.Int{big.NewInt(0), big.NewInt(1), big.NewInt(2), big.NewInt(7), big.NewInt(8), big.NewInt(1000), k}
+	// a point out of the prime order subgroup, adding the point of order 2
+	var p1, p, lowOrder edbn254.PointAffine
+	p1.ScalarMultiplication(&params.Base, r)
+	lowOrder.X.SetZero()
+	lowOrder.Y.SetOne()
+	lowOrder.Y.Neg(&lowOrder.Y)
+	p.Add(&p1, &lowOrder)
+	witness := &testConstantScalarMulCircuit{
+		Point:   twistededwards.Point{X: p.X, Y: p.Y},
+		Results: make([]twistededwards.Point, len(scalars)),
+	}
+	for i, s := range scalars {
+		var res edbn254.PointAffine
+		res.ScalarMultiplication(&p, s)
+		witness.Results[i] = twistededwards.Point{X: res.X, Y: res.Y}
+	}
+	// the results of the subgroup point differ for the odd scalars
+	invalid := *witness
+	invalid.Point = twistededwards.Point{X: p1.X, Y: p1.Y}
+
+	for _, s := range scalars[:6] {
+		cs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &testConstantScalarMulCircuit{
+			Scalars: []*big.Int{s},
+			Results: make([]twistededwards.Point, 1),
+		})
+		c.Assert(err, qt.IsNil)
+		fmt.Println("scalar", s, "constrains", cs.GetNbConstraints())
+	}
+
+	assert := test.NewAssert(t)
+	assert.CheckCircuit(&testConstantScalarMulCircuit{Scalars: scalars, Results: make([]twistededwards.Point, len(scalars))},
+		test.WithValidAssignment(witness),
+		test.WithInvalidAssignment(&invalid),
+		test.WithCurves(ecc.BN254),
+		test.WithBackends(backend.GROTH16),
+	)
+}
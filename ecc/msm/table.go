@@ -0,0 +1,122 @@
+package msm
+
+import (
+	"math/big"
+	"math/bits"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/native/twistededwards"
+)
+
+// Table contains the multiples of a point for every window of the scalars,
+// as the coefficients of multilinear polynomials on the bits of the window,
+// to share the precomputation among several scalar multiplications of the
+// same point. The coefficients of a constant point are constants computed
+// at compile time, and the ones of a variable point are linear combinations
+// of its multiples, computed in the circuit with ~7 point operations per
+// window.
+type Table struct {
+	// windows[i][m] are the coefficients of the monomial of the bits in the
+	// mask m of the window i
+	windows [][][2]frontend.Variable
+}
+
+// NewTable returns the Table of the point provided for the scalars of the
+// native field. Unlike the scalar multiplication of the curve, the point
+// does not need to be in the prime order subgroup.
+func NewTable(curve twistededwards.Curve, p twistededwards.Point) *Table {
+	api := curve.API()
+	nbBits := api.Compiler().FieldBitLen()
+	t := &Table{}
+	x, xConst := api.Compiler().ConstantValue(p.X)
+	y, yConst := api.Compiler().ConstantValue(p.Y)
+	if xConst && yConst {
+		base := [2]*big.Int{x, y}
+		for i := 0; i < nbBits; i += windowSize {
+			coeffs := windowTable(curve, base, min(windowSize, nbBits-i))
+			window := make([][2]frontend.Variable, len(coeffs))
+			for m, c := range coeffs {
+				window[m] = [2]frontend.Variable{c[0], c[1]}
+			}
+			t.windows = append(t.windows, window)
+			// the base of the next window is [2^windowSize]base
+			for range windowSize {
+				base = nativeAdd(curve, base, base)
+			}
+		}
+		return t
+	}
+	base := p
+	for i := 0; i < nbBits; i += windowSize {
+		size := min(windowSize, nbBits-i)
+		// entries[v] = [v]base
+		entries := make([]twistededwards.Point, 1<<size)
+		entries[0] = twistededwards.Point{X: 0, Y: 1}
+		entries[1] = base
+		for v := 2; v < len(entries); v++ {
+			if v%2 == 0 {
+				entries[v] = curve.Double(entries[v/2])
+			} else {
+				entries[v] = curve.Add(entries[v-1], base)
+			}
+		}
+		t.windows = append(t.windows, mobius(api, entries))
+		// the base of the next window is [2^windowSize]base
+		if i+windowSize < nbBits {
+			base = curve.Double(entries[1<<(windowSize-1)])
+		}
+	}
+	return t
+}
+
+// ScalarMul returns [scalar]P for the point P of every table provided,
+// sharing the binary decomposition of the scalar and the products of the
+// bits of every window, so the multiples of a constant point need no
+// constraints but the additions of the windows. The scalar can be any native
+// field element.
+func ScalarMul(curve twistededwards.Curve, scalar frontend.Variable, tables ...*Table) []twistededwards.Point {
+	api := curve.API()
+	scalarBits := api.ToBinary(scalar)
+	res := make([]twistededwards.Point, len(tables))
+	for i := 0; i*windowSize < len(scalarBits); i++ {
+		window := scalarBits[i*windowSize : min((i+1)*windowSize, len(scalarBits))]
+		monomials := bitMonomials(api, window)
+		for j, t := range tables {
+			var entry twistededwards.Point
+			entry.X, entry.Y = 0, 0
+			for m, c := range t.windows[i] {
+				entry.X = api.Add(entry.X, api.Mul(monomials[m], c[0]))
+				entry.Y = api.Add(entry.Y, api.Mul(monomials[m], c[1]))
+			}
+			if i == 0 {
+				res[j] = entry
+			} else {
+				res[j] = curve.Add(res[j], entry)
+			}
+		}
+	}
+	return res
+}
+
+// mobius returns the coefficients of the coordinates of the entries, as
+// multilinear polynomials on the bits of their index, in the order of the
+// monomials returned by bitMonomials (see windowTable).
+func mobius(api frontend.API, entries []twistededwards.Point) [][2]frontend.Variable {
+	coeffs := make([][2]frontend.Variable, len(entries))
+	for m := range coeffs {
+		coeffs[m] = [2]frontend.Variable{0, 0}
+		for s := m; ; s = (s - 1) & m {
+			if bits.OnesCount(uint(m^s))%2 == 1 {
+				coeffs[m][0] = api.Sub(coeffs[m][0], entries[s].X)
+				coeffs[m][1] = api.Sub(coeffs[m][1], entries[s].Y)
+			} else {
+				coeffs[m][0] = api.Add(coeffs[m][0], entries[s].X)
+				coeffs[m][1] = api.Add(coeffs[m][1], entries[s].Y)
+			}
+			if s == 0 {
+				break
+			}
+		}
+	}
+	return coeffs
+}
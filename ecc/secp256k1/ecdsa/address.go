@@ -2,6 +2,7 @@ package ecdsa
 
 import (
 	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_emulated"
 	"github.com/consensys/gnark/std/hash/sha3"
 	"github.com/consensys/gnark/std/math/emulated"
 	"github.com/consensys/gnark/std/signature/ecdsa"
@@ -38,3 +39,45 @@ func DeriveAddress(api frontend.API, pubKey ecdsa.PublicKey[emulated.Secp256k1Fp
 	}
 	return addr, nil
 }
+
+// PublicKeyFromPrivateKey computes the public key of a private key over
+// Secp256k1 in the circuit, that is [privKey] * G, using the emulated
+// fixed-base scalar multiplication of gnark, which uses a precomputed table
+// of multiples of the generator. It asserts that the private key is in the
+// range [1, n-1] and returns the coordinates strictly reduced, so they can be
+// used in DeriveAddress.
+func PublicKeyFromPrivateKey(api frontend.API, privKey emulated.Element[emulated.Secp256k1Fr]) (ecdsa.PublicKey[emulated.Secp256k1Fp, emulated.Secp256k1Fr], error) {
+	scalarField, err := emulated.NewField[emulated.Secp256k1Fr](api)
+	if err != nil {
+		return ecdsa.PublicKey[emulated.Secp256k1Fp, emulated.Secp256k1Fr]{}, err
+	}
+	baseField, err := emulated.NewField[emulated.Secp256k1Fp](api)
+	if err != nil {
+		return ecdsa.PublicKey[emulated.Secp256k1Fp, emulated.Secp256k1Fr]{}, err
+	}
+	curve, err := sw_emulated.New[emulated.Secp256k1Fp, emulated.Secp256k1Fr](api, sw_emulated.GetSecp256k1Params())
+	if err != nil {
+		return ecdsa.PublicKey[emulated.Secp256k1Fp, emulated.Secp256k1Fr]{}, err
+	}
+	// the private key must be in [1, n-1]
+	scalarField.AssertIsInRange(&privKey)
+	api.AssertIsEqual(scalarField.IsZero(&privKey), 0)
+	// pubKey = [privKey] * G
+	pubKey := curve.ScalarMulBase(&privKey)
+	return ecdsa.PublicKey[emulated.Secp256k1Fp, emulated.Secp256k1Fr]{
+		X: *baseField.ReduceStrict(&pubKey.X),
+		Y: *baseField.ReduceStrict(&pubKey.Y),
+	}, nil
+}
+
+// DeriveAddressFromPrivateKey derives the Ethereum address of a private key
+// over Secp256k1, computing its public key with PublicKeyFromPrivateKey and
+// the address with DeriveAddress. It allows to prove the ownership of an
+// address without a signature.
+func DeriveAddressFromPrivateKey(api frontend.API, privKey emulated.Element[emulated.Secp256k1Fr]) (frontend.Variable, error) {
+	pubKey, err := PublicKeyFromPrivateKey(api, privKey)
+	if err != nil {
+		return 0, err
+	}
+	return DeriveAddress(api, pubKey)
+}
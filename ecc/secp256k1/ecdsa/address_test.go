@@ -9,6 +9,7 @@ import (
 	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/frontend/cs/scs"
 	"github.com/consensys/gnark/profile"
 	"github.com/consensys/gnark/std/math/emulated"
 	gecdsa "github.com/consensys/gnark/std/signature/ecdsa"
@@ -56,3 +57,54 @@ func TestAddressDerivation(t *testing.T) {
 	assert := test.NewAssert(t)
 	assert.SolvingSucceeded(&testAddressCircuit{}, &witness, test.WithCurves(ecc.BLS12_377), test.WithBackends(backend.GROTH16))
 }
+
+type testPrivateKeyAddressCircuit struct {
+	Address    frontend.Variable `gnark:",public"`
+	PrivateKey emulated.Element[emulated.Secp256k1Fr]
+}
+
+func (c *testPrivateKeyAddressCircuit) Define(api frontend.API) error {
+	addr, err := DeriveAddressFromPrivateKey(api, c.PrivateKey)
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(c.Address, addr)
+	return nil
+}
+
+func TestAddressFromPrivateKey(t *testing.T) {
+	c := qt.New(t)
+	// compile the circuit and get the constraints for R1CS and SCS
+	p := profile.Start()
+	now := time.Now()
+	_, _ = frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &testPrivateKeyAddressCircuit{})
+	fmt.Println("r1cs elapsed", time.Since(now))
+	p.Stop()
+	fmt.Println("r1cs constrains", p.NbConstraints())
+	p = profile.Start()
+	now = time.Now()
+	_, _ = frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, &testPrivateKeyAddressCircuit{})
+	fmt.Println("scs elapsed", time.Since(now))
+	p.Stop()
+	fmt.Println("scs constrains", p.NbConstraints())
+	// generate a key pair
+	privKey, err := crypto.GenerateKey()
+	c.Assert(err, qt.IsNil)
+	witness := &testPrivateKeyAddressCircuit{
+		Address:    crypto.PubkeyToAddress(privKey.PublicKey).Big(),
+		PrivateKey: emulated.ValueOf[emulated.Secp256k1Fr](privKey.D),
+	}
+	// the address of other key must fail
+	otherKey, err := crypto.GenerateKey()
+	c.Assert(err, qt.IsNil)
+	invalid := *witness
+	invalid.Address = crypto.PubkeyToAddress(otherKey.PublicKey).Big()
+
+	assert := test.NewAssert(t)
+	assert.CheckCircuit(&testPrivateKeyAddressCircuit{},
+		test.WithValidAssignment(witness),
+		test.WithInvalidAssignment(&invalid),
+		test.WithCurves(ecc.BN254),
+		test.WithBackends(backend.GROTH16),
+	)
+}
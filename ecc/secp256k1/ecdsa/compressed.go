@@ -0,0 +1,60 @@
+package ecdsa
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/std/rangecheck"
+	"github.com/consensys/gnark/std/signature/ecdsa"
+	"github.com/vocdoni/gnark-crypto-primitives/utils"
+)
+
+// CompressedPublicKeyLen is the length of a compressed secp256k1 public key:
+// the parity byte (0x02 for even Y, 0x03 for odd Y) followed by the 32 bytes
+// of the X coordinate in big-endian.
+const CompressedPublicKeyLen = 33
+
+// DecompressPublicKey returns the public key encoded in compressed format.
+// The Y coordinate is calculated by a hint as the square root of X³ + 7 with
+// the parity of the prefix, and then the circuit checks that Y² = X³ + 7 and
+// that the least significant bit of Y matches the prefix. Both coordinates
+// are constrained to be canonical (less than the field modulus), so the
+// resulting public key can be used in DeriveAddress and in the signature
+// verification of gnark.
+func DecompressPublicKey(api frontend.API, compressed utils.Bytes) (ecdsa.PublicKey[emulated.Secp256k1Fp, emulated.Secp256k1Fr], error) {
+	if len(compressed) != CompressedPublicKeyLen {
+		return ecdsa.PublicKey[emulated.Secp256k1Fp, emulated.Secp256k1Fr]{},
+			fmt.Errorf("invalid compressed public key length: %d", len(compressed))
+	}
+	field, err := emulated.NewField[emulated.Secp256k1Fp](api)
+	if err != nil {
+		return ecdsa.PublicKey[emulated.Secp256k1Fp, emulated.Secp256k1Fr]{}, err
+	}
+	// the prefix must be 0x02 or 0x03, so the parity is prefix - 2
+	parity := api.Sub(compressed[0].Val, 2)
+	api.AssertIsBoolean(parity)
+	// ensure that the X bytes are bytes and compose the X coordinate
+	rc := rangecheck.New(api)
+	for _, b := range compressed[1:] {
+		rc.Check(b.Val, 8)
+	}
+	x, err := utils.U8ToElem[emulated.Secp256k1Fp](api, utils.SwapEndianness(compressed[1:]))
+	if err != nil {
+		return ecdsa.PublicKey[emulated.Secp256k1Fp, emulated.Secp256k1Fr]{}, err
+	}
+	field.AssertIsInRange(&x)
+	// y² = x³ + 7
+	rhs := field.Add(field.Mul(&x, field.Mul(&x, &x)), field.NewElement(7))
+	hintParity := field.Select(parity, field.One(), field.Zero())
+	res, err := field.NewHint(DecompressYHint, 1, rhs, hintParity)
+	if err != nil {
+		return ecdsa.PublicKey[emulated.Secp256k1Fp, emulated.Secp256k1Fr]{}, err
+	}
+	y := res[0]
+	field.AssertIsEqual(field.Mul(y, y), rhs)
+	// the canonical y must have the parity of the prefix
+	yBits := field.ToBitsCanonical(y)
+	api.AssertIsEqual(yBits[0], parity)
+	return ecdsa.PublicKey[emulated.Secp256k1Fp, emulated.Secp256k1Fr]{X: x, Y: *field.ReduceStrict(y)}, nil
+}
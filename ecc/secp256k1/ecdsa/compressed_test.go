@@ -0,0 +1,74 @@
+package ecdsa
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/profile"
+	"github.com/consensys/gnark/std/math/uints"
+	"github.com/consensys/gnark/test"
+	"github.com/ethereum/go-ethereum/crypto"
+	qt "github.com/frankban/quicktest"
+	"github.com/vocdoni/gnark-crypto-primitives/utils"
+)
+
+type testDecompressCircuit struct {
+	Address   frontend.Variable `gnark:",public"`
+	PublicKey [CompressedPublicKeyLen]uints.U8
+}
+
+func (c *testDecompressCircuit) Define(api frontend.API) error {
+	pubKey, err := DecompressPublicKey(api, c.PublicKey[:])
+	if err != nil {
+		return err
+	}
+	addr, err := DeriveAddress(api, pubKey)
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(c.Address, addr)
+	return nil
+}
+
+func TestDecompressPublicKey(t *testing.T) {
+	c := qt.New(t)
+	// profiling the circuit compilation
+	p := profile.Start()
+	now := time.Now()
+	_, _ = frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &testDecompressCircuit{})
+	fmt.Println("elapsed", time.Since(now))
+	p.Stop()
+	fmt.Println("constrains", p.NbConstraints())
+	// generate keys until there is one with even Y and one with odd Y
+	witnesses := map[byte]*testDecompressCircuit{}
+	for len(witnesses) < 2 {
+		privKey, err := crypto.GenerateKey()
+		c.Assert(err, qt.IsNil)
+		compressed := crypto.CompressPubkey(&privKey.PublicKey)
+		witnesses[compressed[0]] = &testDecompressCircuit{
+			Address:   crypto.PubkeyToAddress(privKey.PublicKey).Big(),
+			PublicKey: [CompressedPublicKeyLen]uints.U8(utils.BytesFromSlice(compressed, CompressedPublicKeyLen)),
+		}
+	}
+	// flipping the parity results in the negated point, with other address
+	wrongParity := *witnesses[0x02]
+	wrongParity.PublicKey[0] = uints.NewU8(0x03)
+	// the uncompressed prefix is not accepted
+	wrongPrefix := *witnesses[0x02]
+	wrongPrefix.PublicKey[0] = uints.NewU8(0x04)
+
+	assert := test.NewAssert(t)
+	assert.CheckCircuit(&testDecompressCircuit{},
+		test.WithValidAssignment(witnesses[0x02]),
+		test.WithValidAssignment(witnesses[0x03]),
+		test.WithInvalidAssignment(&wrongParity),
+		test.WithInvalidAssignment(&wrongPrefix),
+		test.WithCurves(ecc.BN254),
+		test.WithBackends(backend.GROTH16),
+	)
+}
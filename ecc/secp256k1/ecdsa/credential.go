@@ -0,0 +1,74 @@
+package ecdsa
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/secp256k1"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_emulated"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/std/signature/ecdsa"
+)
+
+// Credential contains a public key, a signature and a message digest over
+// Secp256k1 to be verified. It implements the signature.Verifier interface,
+// so ECDSA signatures can be verified together with other schemes, using the
+// Ethereum address as the identity of the signer.
+type Credential struct {
+	api    frontend.API
+	pubKey ecdsa.PublicKey[emulated.Secp256k1Fp, emulated.Secp256k1Fr]
+	sig    ecdsa.Signature[emulated.Secp256k1Fr]
+	msg    emulated.Element[emulated.Secp256k1Fr]
+}
+
+// NewCredential returns a new Credential to verify the signature of the
+// message digest (as a scalar, see DigestToScalar) with the public key.
+func NewCredential(api frontend.API,
+	pubKey ecdsa.PublicKey[emulated.Secp256k1Fp, emulated.Secp256k1Fr],
+	sig ecdsa.Signature[emulated.Secp256k1Fr],
+	msg emulated.Element[emulated.Secp256k1Fr],
+) *Credential {
+	return &Credential{
+		api:    api,
+		pubKey: pubKey,
+		sig:    sig,
+		msg:    msg,
+	}
+}
+
+// IsValid returns 1 if the signature of the credential is valid, 0
+// otherwise.
+func (c *Credential) IsValid() (frontend.Variable, error) {
+	return c.pubKey.IsValid(c.api, sw_emulated.GetSecp256k1Params(), &c.msg, &c.sig), nil
+}
+
+// Identity returns the Ethereum address of the public key (see
+// DeriveAddress).
+func (c *Credential) Identity() (frontend.Variable, error) {
+	return DeriveAddress(c.api, c.pubKey)
+}
+
+// DummyInputs returns the public placeholder inputs of a Credential, to fill
+// the ECDSA inputs of a circuit when another scheme is selected (see
+// signature.Select): the public key [2]G, r = s = 1 and the message 1. They
+// satisfy the checks of the verification (non-zero s, r and s lower than
+// the modulus, a public key on the curve that is not G), but they are not a
+// valid signature.
+func DummyInputs() (
+	ecdsa.PublicKey[emulated.Secp256k1Fp, emulated.Secp256k1Fr],
+	ecdsa.Signature[emulated.Secp256k1Fr],
+	emulated.Element[emulated.Secp256k1Fr],
+) {
+	_, g := secp256k1.Generators()
+	var p secp256k1.G1Affine
+	p.Double(&g)
+	pubKey := ecdsa.PublicKey[emulated.Secp256k1Fp, emulated.Secp256k1Fr]{
+		X: emulated.ValueOf[emulated.Secp256k1Fp](p.X.BigInt(new(big.Int))),
+		Y: emulated.ValueOf[emulated.Secp256k1Fp](p.Y.BigInt(new(big.Int))),
+	}
+	sig := ecdsa.Signature[emulated.Secp256k1Fr]{
+		R: emulated.ValueOf[emulated.Secp256k1Fr](1),
+		S: emulated.ValueOf[emulated.Secp256k1Fr](1),
+	}
+	return pubKey, sig, emulated.ValueOf[emulated.Secp256k1Fr](1)
+}
@@ -0,0 +1,112 @@
+package ecdsa
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash/sha3"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/std/math/uints"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/vocdoni/gnark-crypto-primitives/utils"
+)
+
+// PersonalMessagePrefix is the prefix that EIP-191 (version 0x45) prepends
+// to the messages signed with personal_sign, before the decimal length of the
+// message.
+const PersonalMessagePrefix = "\x19Ethereum Signed Message:\n"
+
+// HashPersonalMessage computes the EIP-191 digest of the first length bytes
+// of msg, as personal_sign does:
+//
+//	keccak256("\x19Ethereum Signed Message:\n" ‖ decimal(length) ‖ msg[:length])
+//
+// The length of msg sets the maximum length of the message supported by the
+// circuit, and the bytes after length are ignored. The decimal digits of the
+// length are provided by a hint and constrained to recompose it, and then the
+// message is shifted to start right after them, so the hashed preimage
+// has no gaps.
+func HashPersonalMessage(api frontend.API, msg utils.Bytes, length frontend.Variable) (utils.Bytes, error) {
+	maxLen := len(msg)
+	if maxLen == 0 {
+		return nil, fmt.Errorf("empty message buffer")
+	}
+	api.AssertIsLessOrEqual(length, maxLen)
+	// get the decimal digits of the length, padded to the number of digits
+	// of the maximum length
+	nDigits := len(strconv.Itoa(maxLen))
+	digits, err := api.NewHint(DecimalDigitsHint, nDigits, length)
+	if err != nil {
+		return nil, err
+	}
+	// check that every digit is in [0, 9] and that they recompose the length
+	terms := make([]frontend.Variable, nDigits)
+	for i, d := range digits {
+		api.AssertIsLessOrEqual(d, 9)
+		coeff := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(nDigits-1-i)), nil)
+		terms[i] = api.Mul(d, coeff)
+	}
+	api.AssertIsEqual(api.Add(0, 0, terms...), length)
+	// count the leading zeros to strip, keeping always the last digit to
+	// encode a zero length as "0"
+	leadingZero := frontend.Variable(1)
+	stripped := frontend.Variable(0)
+	for i := range nDigits - 1 {
+		leadingZero = api.Mul(leadingZero, api.IsZero(digits[i]))
+		stripped = api.Add(stripped, leadingZero)
+	}
+	usedDigits := api.Sub(nDigits, stripped)
+	// compose the body (decimal length ‖ message) for every possible number
+	// of digits and select the one that matches
+	selectors := make([]frontend.Variable, nDigits)
+	for c := 1; c <= nDigits; c++ {
+		selectors[c-1] = api.IsZero(api.Sub(usedDigits, c))
+	}
+	body := make(utils.Bytes, nDigits+maxLen)
+	for t := range body {
+		candidates := make([]frontend.Variable, nDigits)
+		for c := 1; c <= nDigits; c++ {
+			var candidate frontend.Variable = 0
+			if t < c {
+				// ascii code of the digit
+				candidate = api.Add(digits[nDigits-c+t], '0')
+			} else if t-c < maxLen {
+				candidate = msg[t-c].Val
+			}
+			candidates[c-1] = api.Mul(selectors[c-1], candidate)
+		}
+		body[t] = uints.U8{Val: api.Add(0, 0, candidates...)}
+	}
+	// hash the prefix and the body, using only the used bytes
+	keccak, err := sha3.NewLegacyKeccak256(api)
+	if err != nil {
+		return nil, err
+	}
+	keccak.Write(uints.NewU8Array([]byte(PersonalMessagePrefix)))
+	keccak.Write(body)
+	return keccak.FixedLengthSum(api.Add(len(PersonalMessagePrefix), usedDigits, length)), nil
+}
+
+// PersonalMessageHash returns the EIP-191 digest of msg calculated natively,
+// which is the same value that HashPersonalMessage returns in the circuit.
+func PersonalMessageHash(msg []byte) []byte {
+	prefix := PersonalMessagePrefix + strconv.Itoa(len(msg))
+	return crypto.Keccak256([]byte(prefix), msg)
+}
+
+// DigestToScalar converts a 32-byte big-endian digest, like the ones returned
+// by HashPersonalMessage or HashTypedData, into an emulated secp256k1 scalar,
+// which is the message format expected by the ECDSA signature verification of
+// gnark.
+func DigestToScalar(api frontend.API, digest utils.Bytes) (*emulated.Element[emulated.Secp256k1Fr], error) {
+	if len(digest) != 32 {
+		return nil, fmt.Errorf("invalid digest length: %d", len(digest))
+	}
+	scalar, err := utils.U8ToElem[emulated.Secp256k1Fr](api, utils.SwapEndianness(digest))
+	if err != nil {
+		return nil, err
+	}
+	return &scalar, nil
+}
@@ -0,0 +1,80 @@
+package ecdsa
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/profile"
+	"github.com/consensys/gnark/std/math/uints"
+	"github.com/consensys/gnark/test"
+	"github.com/ethereum/go-ethereum/common"
+	qt "github.com/frankban/quicktest"
+	"github.com/vocdoni/gnark-crypto-primitives/utils"
+)
+
+const maxPersonalMessageLen = 120
+
+type testPersonalMessageCircuit struct {
+	Message [maxPersonalMessageLen]uints.U8
+	Length  frontend.Variable
+	Digest  [32]uints.U8 `gnark:",public"`
+}
+
+func (c *testPersonalMessageCircuit) Define(api frontend.API) error {
+	digest, err := HashPersonalMessage(api, c.Message[:], c.Length)
+	if err != nil {
+		return err
+	}
+	digest.AssertIsEqual(api, c.Digest[:])
+	return nil
+}
+
+func TestHashPersonalMessage(t *testing.T) {
+	// profiling the circuit compilation
+	p := profile.Start()
+	now := time.Now()
+	_, _ = frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &testPersonalMessageCircuit{})
+	fmt.Println("elapsed", time.Since(now))
+	p.Stop()
+	fmt.Println("constrains", p.NbConstraints())
+
+	messages := []string{
+		"",
+		"hello",
+		"vote: 1234",
+		strings.Repeat("a", 99),
+		strings.Repeat("b", 100),
+		strings.Repeat("c", maxPersonalMessageLen),
+	}
+	opts := []test.TestingOption{test.WithCurves(ecc.BN254), test.WithBackends(backend.GROTH16)}
+	for _, msg := range messages {
+		opts = append(opts, test.WithValidAssignment(&testPersonalMessageCircuit{
+			Message: [maxPersonalMessageLen]uints.U8(utils.BytesFromSlice([]byte(msg), maxPersonalMessageLen)),
+			Length:  len(msg),
+			Digest:  [32]uints.U8(utils.BytesFromSlice(PersonalMessageHash([]byte(msg)), 32)),
+		}))
+	}
+	// a digest calculated with a wrong length must fail
+	msg := []byte("hello")
+	opts = append(opts, test.WithInvalidAssignment(&testPersonalMessageCircuit{
+		Message: [maxPersonalMessageLen]uints.U8(utils.BytesFromSlice(msg, maxPersonalMessageLen)),
+		Length:  len(msg) + 1,
+		Digest:  [32]uints.U8(utils.BytesFromSlice(PersonalMessageHash(msg), 32)),
+	}))
+
+	assert := test.NewAssert(t)
+	assert.CheckCircuit(&testPersonalMessageCircuit{}, opts...)
+}
+
+func TestPersonalMessageHash(t *testing.T) {
+	c := qt.New(t)
+	// hash of "hello" as returned by personal_sign implementations
+	expected := common.HexToHash("0x50b2c43fd39106bafbba0da34fc430e1f91e3c96ea2acee2bc34119f92b37750")
+	c.Assert(PersonalMessageHash([]byte("hello")), qt.DeepEquals, expected.Bytes())
+}
@@ -0,0 +1,188 @@
+package ecdsa
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash/sha3"
+	"github.com/consensys/gnark/std/math/uints"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/vocdoni/gnark-crypto-primitives/utils"
+)
+
+// TypedDataField is a member of an EIP-712 struct type, defined by its name
+// and its Solidity type, for example {Name: "processId", Type: "bytes32"}.
+type TypedDataField struct {
+	Name string
+	Type string
+}
+
+// TypedDataTypes describes a set of EIP-712 struct types indexed by their
+// names, like the "types" member of an eth_signTypedData_v4 request.
+type TypedDataTypes map[string][]TypedDataField
+
+// dependencies returns the struct types referenced by primaryType, directly
+// or transitively, without including primaryType itself.
+func (t TypedDataTypes) dependencies(primaryType string, found map[string]bool) {
+	for _, field := range t[primaryType] {
+		name := field.Type
+		if i := strings.Index(name, "["); i >= 0 {
+			name = name[:i]
+		}
+		if _, ok := t[name]; !ok || found[name] {
+			continue
+		}
+		found[name] = true
+		t.dependencies(name, found)
+	}
+}
+
+// EncodeType returns the EIP-712 encoding of primaryType: its name followed
+// by its members between parentheses, and then the encodings of the struct
+// types it references, sorted by name. For example:
+//
+//	Mail(Person from,Person to,string contents)Person(string name,address wallet)
+func (t TypedDataTypes) EncodeType(primaryType string) (string, error) {
+	if _, ok := t[primaryType]; !ok {
+		return "", fmt.Errorf("unknown type %s", primaryType)
+	}
+	found := map[string]bool{}
+	t.dependencies(primaryType, found)
+	delete(found, primaryType)
+	deps := make([]string, 0, len(found))
+	for name := range found {
+		deps = append(deps, name)
+	}
+	sort.Strings(deps)
+
+	var b strings.Builder
+	for _, name := range append([]string{primaryType}, deps...) {
+		members := make([]string, len(t[name]))
+		for i, field := range t[name] {
+			members[i] = field.Type + " " + field.Name
+		}
+		b.WriteString(name + "(" + strings.Join(members, ",") + ")")
+	}
+	return b.String(), nil
+}
+
+// TypeHash returns the keccak256 hash of the encoded primaryType.
+func (t TypedDataTypes) TypeHash(primaryType string) ([]byte, error) {
+	encoded, err := t.EncodeType(primaryType)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.Keccak256([]byte(encoded)), nil
+}
+
+// TypedDataDomain contains the values of the EIP712Domain struct. Only the
+// members that are set are included in the domain type, in the order defined
+// by the standard.
+type TypedDataDomain struct {
+	Name              string
+	Version           string
+	ChainID           *big.Int
+	VerifyingContract *common.Address
+	Salt              *common.Hash
+}
+
+// Separator returns the domain separator, that is the hashStruct of the
+// EIP712Domain. It is calculated natively because the domain of an
+// application is usually known when the circuit is defined, so it can be
+// included as a constant using utils.BytesFromSlice.
+func (d TypedDataDomain) Separator() []byte {
+	fields := []TypedDataField{}
+	encoded := [][]byte{}
+	if d.Name != "" {
+		fields = append(fields, TypedDataField{Name: "name", Type: "string"})
+		encoded = append(encoded, crypto.Keccak256([]byte(d.Name)))
+	}
+	if d.Version != "" {
+		fields = append(fields, TypedDataField{Name: "version", Type: "string"})
+		encoded = append(encoded, crypto.Keccak256([]byte(d.Version)))
+	}
+	if d.ChainID != nil {
+		fields = append(fields, TypedDataField{Name: "chainId", Type: "uint256"})
+		encoded = append(encoded, common.BigToHash(d.ChainID).Bytes())
+	}
+	if d.VerifyingContract != nil {
+		fields = append(fields, TypedDataField{Name: "verifyingContract", Type: "address"})
+		encoded = append(encoded, common.BytesToHash(d.VerifyingContract.Bytes()).Bytes())
+	}
+	if d.Salt != nil {
+		fields = append(fields, TypedDataField{Name: "salt", Type: "bytes32"})
+		encoded = append(encoded, d.Salt.Bytes())
+	}
+	// the domain type has no dependencies, so it can not fail
+	typeHash, _ := TypedDataTypes{"EIP712Domain": fields}.TypeHash("EIP712Domain")
+	return crypto.Keccak256(append([][]byte{typeHash}, encoded...)...)
+}
+
+// HashStruct computes the EIP-712 hashStruct of a value of primaryType inside
+// the circuit:
+//
+//	keccak256(typeHash(primaryType) ‖ encodeData(value))
+//
+// The type hash is calculated natively from the types description. The
+// members must be provided already encoded, in the same order as they are
+// declared in the type, as 32-byte big-endian words: atomic values are
+// left-padded (utils.BytesFromVariable does it for integers, addresses and
+// booleans), dynamic values (bytes and string) are hashed with
+// EncodeDynamicBytes and struct values are encoded with their own HashStruct.
+func HashStruct(api frontend.API, types TypedDataTypes, primaryType string, members ...utils.Bytes) (utils.Bytes, error) {
+	typeHash, err := types.TypeHash(primaryType)
+	if err != nil {
+		return nil, err
+	}
+	if len(members) != len(types[primaryType]) {
+		return nil, fmt.Errorf("expected %d members for type %s, got %d",
+			len(types[primaryType]), primaryType, len(members))
+	}
+	keccak, err := sha3.NewLegacyKeccak256(api)
+	if err != nil {
+		return nil, err
+	}
+	keccak.Write(uints.NewU8Array(typeHash))
+	for i, member := range members {
+		if len(member) != 32 {
+			return nil, fmt.Errorf("member %s is not encoded as a 32-byte word", types[primaryType][i].Name)
+		}
+		keccak.Write(member)
+	}
+	return keccak.Sum(), nil
+}
+
+// EncodeDynamicBytes returns the EIP-712 encoding of a dynamic member (bytes
+// or string), that is the keccak256 hash of its first length bytes. The
+// length of value sets the maximum length supported.
+func EncodeDynamicBytes(api frontend.API, value utils.Bytes, length frontend.Variable) (utils.Bytes, error) {
+	keccak, err := sha3.NewLegacyKeccak256(api)
+	if err != nil {
+		return nil, err
+	}
+	keccak.Write(value)
+	return keccak.FixedLengthSum(length), nil
+}
+
+// HashTypedData computes the EIP-712 digest that is signed by
+// eth_signTypedData_v4, from the domain separator and the hashStruct of the
+// message:
+//
+//	keccak256("\x19\x01" ‖ domainSeparator ‖ hashStruct(message))
+func HashTypedData(api frontend.API, domainSeparator, structHash utils.Bytes) (utils.Bytes, error) {
+	if len(domainSeparator) != 32 || len(structHash) != 32 {
+		return nil, fmt.Errorf("domain separator and struct hash must have 32 bytes")
+	}
+	keccak, err := sha3.NewLegacyKeccak256(api)
+	if err != nil {
+		return nil, err
+	}
+	keccak.Write(uints.NewU8Array([]byte{0x19, 0x01}))
+	keccak.Write(domainSeparator)
+	keccak.Write(structHash)
+	return keccak.Sum(), nil
+}
@@ -0,0 +1,164 @@
+package ecdsa
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/profile"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_emulated"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/std/math/uints"
+	gecdsa "github.com/consensys/gnark/std/signature/ecdsa"
+	"github.com/consensys/gnark/test"
+	"github.com/ethereum/go-ethereum/common"
+	qt "github.com/frankban/quicktest"
+	"github.com/vocdoni/gnark-crypto-primitives/testutil"
+	"github.com/vocdoni/gnark-crypto-primitives/utils"
+)
+
+const maxMailStringLen = 16
+
+// testMailTypes, testMailDomain and the expected hashes are the example of
+// the EIP-712 specification.
+var (
+	testMailTypes = TypedDataTypes{
+		"Person": {
+			{Name: "name", Type: "string"},
+			{Name: "wallet", Type: "address"},
+		},
+		"Mail": {
+			{Name: "from", Type: "Person"},
+			{Name: "to", Type: "Person"},
+			{Name: "contents", Type: "string"},
+		},
+	}
+	testMailContract = common.HexToAddress("0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC")
+	testMailDomain   = TypedDataDomain{
+		Name:              "Ether Mail",
+		Version:           "1",
+		ChainID:           big.NewInt(1),
+		VerifyingContract: &testMailContract,
+	}
+	testMailSeparator = common.HexToHash("0xf2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f")
+	testMailDigest    = common.HexToHash("0xbe609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2")
+)
+
+type testPerson struct {
+	Name       [maxMailStringLen]uints.U8
+	NameLength frontend.Variable
+	Wallet     frontend.Variable
+}
+
+func (p *testPerson) hash(api frontend.API) (utils.Bytes, error) {
+	name, err := EncodeDynamicBytes(api, p.Name[:], p.NameLength)
+	if err != nil {
+		return nil, err
+	}
+	wallet, err := utils.BytesFromVariable(api, p.Wallet)
+	if err != nil {
+		return nil, err
+	}
+	return HashStruct(api, testMailTypes, "Person", name, wallet)
+}
+
+type testTypedDataCircuit struct {
+	From           testPerson
+	To             testPerson
+	Contents       [maxMailStringLen]uints.U8
+	ContentsLength frontend.Variable
+	Address        frontend.Variable `gnark:",public"`
+	PublicKey      gecdsa.PublicKey[emulated.Secp256k1Fp, emulated.Secp256k1Fr]
+	Signature      gecdsa.Signature[emulated.Secp256k1Fr]
+}
+
+func (c *testTypedDataCircuit) Define(api frontend.API) error {
+	// encode the members of the mail
+	from, err := c.From.hash(api)
+	if err != nil {
+		return err
+	}
+	to, err := c.To.hash(api)
+	if err != nil {
+		return err
+	}
+	contents, err := EncodeDynamicBytes(api, c.Contents[:], c.ContentsLength)
+	if err != nil {
+		return err
+	}
+	// hash the mail and the typed data
+	mail, err := HashStruct(api, testMailTypes, "Mail", from, to, contents)
+	if err != nil {
+		return err
+	}
+	digest, err := HashTypedData(api, utils.BytesFromSlice(testMailDomain.Separator(), 32), mail)
+	if err != nil {
+		return err
+	}
+	// verify the signature and the address of the signer
+	msg, err := DigestToScalar(api, digest)
+	if err != nil {
+		return err
+	}
+	c.PublicKey.Verify(api, sw_emulated.GetSecp256k1Params(), msg, &c.Signature)
+	addr, err := DeriveAddress(api, c.PublicKey)
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(c.Address, addr)
+	return nil
+}
+
+func TestTypedDataNative(t *testing.T) {
+	c := qt.New(t)
+	encoded, err := testMailTypes.EncodeType("Mail")
+	c.Assert(err, qt.IsNil)
+	c.Assert(encoded, qt.Equals, "Mail(Person from,Person to,string contents)Person(string name,address wallet)")
+	_, err = testMailTypes.EncodeType("Unknown")
+	c.Assert(err, qt.IsNotNil)
+	c.Assert(testMailDomain.Separator(), qt.DeepEquals, testMailSeparator.Bytes())
+}
+
+func TestHashTypedData(t *testing.T) {
+	c := qt.New(t)
+	// profiling the circuit compilation
+	p := profile.Start()
+	now := time.Now()
+	_, _ = frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &testTypedDataCircuit{})
+	fmt.Println("elapsed", time.Since(now))
+	p.Stop()
+	fmt.Println("constrains", p.NbConstraints())
+	// sign the digest of the example mail
+	testSig, err := testutil.GenerateAccountAndSign(testMailDigest.Bytes())
+	c.Assert(err, qt.IsNil)
+
+	person := func(name, wallet string) testPerson {
+		return testPerson{
+			Name:       [maxMailStringLen]uints.U8(utils.BytesFromSlice([]byte(name), maxMailStringLen)),
+			NameLength: len(name),
+			Wallet:     common.HexToAddress(wallet).Big(),
+		}
+	}
+	contents := "Hello, Bob!"
+	assert := test.NewAssert(t)
+	assert.SolvingSucceeded(&testTypedDataCircuit{}, &testTypedDataCircuit{
+		From:           person("Cow", "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"),
+		To:             person("Bob", "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"),
+		Contents:       [maxMailStringLen]uints.U8(utils.BytesFromSlice([]byte(contents), maxMailStringLen)),
+		ContentsLength: len(contents),
+		Address:        testSig.Address,
+		PublicKey: gecdsa.PublicKey[emulated.Secp256k1Fp, emulated.Secp256k1Fr]{
+			X: emulated.ValueOf[emulated.Secp256k1Fp](testSig.PublicKey.X),
+			Y: emulated.ValueOf[emulated.Secp256k1Fp](testSig.PublicKey.Y),
+		},
+		Signature: gecdsa.Signature[emulated.Secp256k1Fr]{
+			R: emulated.ValueOf[emulated.Secp256k1Fr](testSig.R),
+			S: emulated.ValueOf[emulated.Secp256k1Fr](testSig.S),
+		},
+	}, test.WithCurves(ecc.BN254), test.WithBackends(backend.GROTH16))
+}
@@ -0,0 +1,38 @@
+package ecdsa
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark/constraint/solver"
+	"github.com/consensys/gnark/std/math/emulated"
+)
+
+func init() { solver.RegisterHint(DecimalDigitsHint, DecompressYHint) }
+
+// DecimalDigitsHint decomposes in[0] into len(out) decimal digits, starting
+// from the most significant one and padding with leading zeros.
+var DecimalDigitsHint solver.Hint = func(_ *big.Int, in, out []*big.Int) error {
+	v := new(big.Int).Set(in[0])
+	ten := big.NewInt(10)
+	for i := len(out) - 1; i >= 0; i-- {
+		v.DivMod(v, ten, out[i])
+	}
+	return nil
+}
+
+// DecompressYHint returns the square root of in[0] in the emulated field
+// whose least significant bit matches the least significant bit of in[1].
+var DecompressYHint solver.Hint = func(nativeMod *big.Int, in, out []*big.Int) error {
+	return emulated.UnwrapHint(in, out, func(mod *big.Int, in, out []*big.Int) error {
+		y := new(big.Int).ModSqrt(in[0], mod)
+		if y == nil {
+			return fmt.Errorf("no square root, the point is not on the curve")
+		}
+		if y.Bit(0) != in[1].Bit(0) {
+			y.Sub(mod, y)
+		}
+		out[0].Set(y)
+		return nil
+	})
+}
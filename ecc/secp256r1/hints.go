@@ -0,0 +1,30 @@
+package secp256r1
+
+import (
+	"encoding/base64"
+	"math/big"
+
+	"github.com/consensys/gnark/constraint/solver"
+)
+
+func init() { solver.RegisterHint(Base64URLDecodeHint) }
+
+// Base64URLDecodeHint decodes the base64url (without padding) characters
+// received as inputs into len(out) bytes. The circuit must encode the
+// outputs again to check that they match the inputs.
+var Base64URLDecodeHint solver.Hint = func(_ *big.Int, in, out []*big.Int) error {
+	encoded := make([]byte, len(in))
+	for i, c := range in {
+		encoded[i] = byte(c.Uint64())
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(string(encoded))
+	if err != nil {
+		return err
+	}
+	for i := range out {
+		if i < len(decoded) {
+			out[i].SetUint64(uint64(decoded[i]))
+		}
+	}
+	return nil
+}
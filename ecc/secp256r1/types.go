@@ -0,0 +1,48 @@
+package secp256r1
+
+import (
+	"crypto/ecdsa"
+	"encoding/asn1"
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark/std/math/emulated"
+	gecdsa "github.com/consensys/gnark/std/signature/ecdsa"
+)
+
+// PublicKey is a P-256 public key, with its coordinates as emulated elements
+// of the P-256 base field.
+type PublicKey = gecdsa.PublicKey[emulated.P256Fp, emulated.P256Fr]
+
+// Signature is a P-256 ECDSA signature, with its R and S components as
+// emulated elements of the P-256 scalar field.
+type Signature = gecdsa.Signature[emulated.P256Fr]
+
+// PublicKeyFromECDSA converts a P-256 public key of the standard library to a
+// gnark public key.
+func PublicKeyFromECDSA(pubKey *ecdsa.PublicKey) PublicKey {
+	return PublicKey{
+		X: emulated.ValueOf[emulated.P256Fp](pubKey.X),
+		Y: emulated.ValueOf[emulated.P256Fp](pubKey.Y),
+	}
+}
+
+// SignatureFromASN1 converts an ASN.1 DER encoded ECDSA signature, which is
+// the format used by WebAuthn authenticators for ES256 credentials, to a
+// gnark signature.
+func SignatureFromASN1(sig []byte) (Signature, error) {
+	var decoded struct {
+		R, S *big.Int
+	}
+	rest, err := asn1.Unmarshal(sig, &decoded)
+	if err != nil {
+		return Signature{}, fmt.Errorf("error decoding signature: %w", err)
+	}
+	if len(rest) != 0 {
+		return Signature{}, fmt.Errorf("trailing data after signature")
+	}
+	return Signature{
+		R: emulated.ValueOf[emulated.P256Fr](decoded.R),
+		S: emulated.ValueOf[emulated.P256Fr](decoded.S),
+	}, nil
+}
@@ -0,0 +1,220 @@
+// secp256r1 package contains the implementation of a WebAuthn (passkey)
+// assertion verifier in Gnark, for credentials that sign with ECDSA over the
+// P-256 curve (COSE algorithm ES256).
+//
+// An authenticator signs SHA-256(authenticatorData ‖ SHA-256(clientDataJSON)),
+// where the clientDataJSON contains the challenge provided by the relying
+// party encoded in base64url. The verifier recomputes the signed message,
+// extracts the challenge from the client data and verifies the signature
+// using emulated arithmetic.
+//
+// Read more about this here: https://www.w3.org/TR/webauthn-2/#sctn-verifying-assertion
+package secp256r1
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_emulated"
+	"github.com/consensys/gnark/std/hash/sha2"
+	"github.com/consensys/gnark/std/lookup/logderivlookup"
+	"github.com/consensys/gnark/std/math/bits"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/std/math/uints"
+	"github.com/vocdoni/gnark-crypto-primitives/utils"
+)
+
+const (
+	// ChallengeKey is the JSON key, including the quotes and the colon, that
+	// precedes the challenge value in the client data.
+	ChallengeKey = `"challenge":"`
+	// base64URLAlphabet is the alphabet of the base64url encoding.
+	base64URLAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+	// userPresentFlag is the bit of the authenticator data flags that is set
+	// when the user was present during the assertion.
+	userPresentFlag = 0
+	// flagsIndex is the position of the flags byte in the authenticator data,
+	// after the 32 bytes of the rpIdHash.
+	flagsIndex = 32
+	// minAuthenticatorDataLen is the length of the authenticator data without
+	// attested credential data nor extensions.
+	minAuthenticatorDataLen = 37
+)
+
+// Assertion is the in-circuit representation of a WebAuthn assertion. The
+// authenticator data has a fixed length, while the client data JSON can have
+// any length up to the length of ClientDataJSON, and ClientDataLength sets
+// the number of bytes used. ChallengeOffset is the position of the first
+// character of the challenge value in the client data.
+type Assertion struct {
+	AuthenticatorData utils.Bytes
+	ClientDataJSON    utils.Bytes
+	ClientDataLength  frontend.Variable
+	ChallengeOffset   frontend.Variable
+	Signature         Signature
+}
+
+// NewAssertion returns an empty assertion with the given authenticator data
+// length and maximum client data length, to be used in the definition of a
+// circuit.
+func NewAssertion(authDataLen, maxClientDataLen int) Assertion {
+	return Assertion{
+		AuthenticatorData: make(utils.Bytes, authDataLen),
+		ClientDataJSON:    make(utils.Bytes, maxClientDataLen),
+	}
+}
+
+// AssertionFromWebAuthn converts the values of a WebAuthn assertion response
+// (the authenticator data, the client data JSON and the ASN.1 DER encoded
+// signature) to a circuit assignment, padding the client data up to
+// maxClientDataLen bytes. It returns an error if the client data does not
+// fit or does not include a challenge.
+func AssertionFromWebAuthn(authData, clientDataJSON, signature []byte, maxClientDataLen int) (Assertion, error) {
+	if len(clientDataJSON) > maxClientDataLen {
+		return Assertion{}, fmt.Errorf("client data too long: %d > %d", len(clientDataJSON), maxClientDataLen)
+	}
+	offset := bytes.Index(clientDataJSON, []byte(ChallengeKey))
+	if offset < 0 {
+		return Assertion{}, fmt.Errorf("challenge not found in client data")
+	}
+	sig, err := SignatureFromASN1(signature)
+	if err != nil {
+		return Assertion{}, err
+	}
+	return Assertion{
+		AuthenticatorData: utils.BytesFromSlice(authData, len(authData)),
+		ClientDataJSON:    utils.BytesFromSlice(clientDataJSON, maxClientDataLen),
+		ClientDataLength:  len(clientDataJSON),
+		ChallengeOffset:   offset + len(ChallengeKey),
+		Signature:         sig,
+	}, nil
+}
+
+// MessageHash returns the message signed by the authenticator, that is
+// SHA-256(authenticatorData ‖ SHA-256(clientDataJSON)), using only the first
+// ClientDataLength bytes of the client data.
+func (a *Assertion) MessageHash(api frontend.API) (utils.Bytes, error) {
+	clientDataHasher, err := sha2.New(api)
+	if err != nil {
+		return nil, err
+	}
+	clientDataHasher.Write(a.ClientDataJSON)
+	clientDataHash := clientDataHasher.FixedLengthSum(a.ClientDataLength)
+
+	hasher, err := sha2.New(api)
+	if err != nil {
+		return nil, err
+	}
+	hasher.Write(a.AuthenticatorData)
+	hasher.Write(clientDataHash)
+	return hasher.Sum(), nil
+}
+
+// Challenge extracts the challenge of challengeLen bytes from the client
+// data. It checks that the base64url encoded challenge starts at
+// ChallengeOffset, preceded by the challenge key and followed by the closing
+// quote, inside the used part of the client data. The decoded bytes are
+// provided by a hint and encoded again in the circuit to be compared with
+// the characters of the client data.
+func (a *Assertion) Challenge(api frontend.API, challengeLen int) (utils.Bytes, error) {
+	nChars := base64.RawURLEncoding.EncodedLen(challengeLen)
+	// the challenge and the closing quote must be in the hashed data
+	api.AssertIsLessOrEqual(api.Add(a.ChallengeOffset, nChars+1), a.ClientDataLength)
+	// read the key, the challenge and the closing quote from the client data
+	// using a lookup table with the bytes of the client data
+	table := logderivlookup.New(api)
+	for _, b := range a.ClientDataJSON {
+		table.Insert(b.Val)
+	}
+	start := api.Sub(a.ChallengeOffset, len(ChallengeKey))
+	indexes := make([]frontend.Variable, len(ChallengeKey)+nChars+1)
+	for i := range indexes {
+		indexes[i] = api.Add(start, i)
+	}
+	window := table.Lookup(indexes...)
+	for i, c := range []byte(ChallengeKey) {
+		api.AssertIsEqual(window[i], c)
+	}
+	api.AssertIsEqual(window[len(window)-1], '"')
+	chars := window[len(ChallengeKey) : len(ChallengeKey)+nChars]
+	// decode the challenge with a hint and check that encoding it again
+	// results in the same characters
+	decoded, err := api.NewHint(Base64URLDecodeHint, challengeLen, chars...)
+	if err != nil {
+		return nil, err
+	}
+	challenge := make(utils.Bytes, challengeLen)
+	for i, b := range decoded {
+		challenge[i] = uints.U8{Val: b}
+	}
+	encoded := base64URLEncode(api, challenge)
+	for i := range chars {
+		api.AssertIsEqual(encoded[i], chars[i])
+	}
+	return challenge, nil
+}
+
+// Verify asserts that the assertion is signed by the public key provided,
+// that the user was present during the assertion and that the challenge of
+// the client data is the one provided.
+func (a *Assertion) Verify(api frontend.API, pubKey PublicKey, challenge utils.Bytes) error {
+	if len(a.AuthenticatorData) < minAuthenticatorDataLen {
+		return fmt.Errorf("authenticator data too short: %d", len(a.AuthenticatorData))
+	}
+	// check the user present flag
+	flags := bits.ToBinary(api, a.AuthenticatorData[flagsIndex].Val, bits.WithNbDigits(8))
+	api.AssertIsEqual(flags[userPresentFlag], 1)
+	// check the challenge
+	clientChallenge, err := a.Challenge(api, len(challenge))
+	if err != nil {
+		return err
+	}
+	clientChallenge.AssertIsEqual(api, challenge)
+	// verify the signature of the message hash
+	msgHash, err := a.MessageHash(api)
+	if err != nil {
+		return err
+	}
+	msg, err := utils.U8ToElem[emulated.P256Fr](api, utils.SwapEndianness(msgHash))
+	if err != nil {
+		return err
+	}
+	pubKey.Verify(api, sw_emulated.GetP256Params(), &msg, &a.Signature)
+	return nil
+}
+
+// base64URLEncode returns the ascii codes of the base64url encoding (without
+// padding) of the data provided. The bytes are decomposed into bits, which
+// also ensures that they are valid bytes, and grouped into 6-bit values that
+// are mapped to the alphabet using a lookup table.
+func base64URLEncode(api frontend.API, data utils.Bytes) []frontend.Variable {
+	alphabet := logderivlookup.New(api)
+	for _, c := range []byte(base64URLAlphabet) {
+		alphabet.Insert(c)
+	}
+	// get the bits of the data, starting from the most significant one
+	dataBits := make([]frontend.Variable, 0, len(data)*8)
+	for _, b := range data {
+		bBits := bits.ToBinary(api, b.Val, bits.WithNbDigits(8))
+		for i := 7; i >= 0; i-- {
+			dataBits = append(dataBits, bBits[i])
+		}
+	}
+	// group them into 6-bit values, padding the last one with zeros
+	nChars := base64.RawURLEncoding.EncodedLen(len(data))
+	values := make([]frontend.Variable, nChars)
+	for i := range values {
+		terms := make([]frontend.Variable, 6)
+		for j := range terms {
+			terms[j] = 0
+			if bit := i*6 + j; bit < len(dataBits) {
+				terms[j] = api.Mul(dataBits[bit], big.NewInt(1<<(5-j)))
+			}
+		}
+		values[i] = api.Add(terms[0], terms[1], terms[2:]...)
+	}
+	return alphabet.Lookup(values...)
+}
@@ -0,0 +1,117 @@
+package secp256r1
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/profile"
+	"github.com/consensys/gnark/std/math/uints"
+	"github.com/consensys/gnark/test"
+	qt "github.com/frankban/quicktest"
+	"github.com/vocdoni/gnark-crypto-primitives/utils"
+)
+
+const (
+	authDataLen      = 37
+	maxClientDataLen = 192
+	challengeLen     = 32
+)
+
+type testWebAuthnCircuit struct {
+	PublicKey PublicKey              `gnark:",public"`
+	Challenge [challengeLen]uints.U8 `gnark:",public"`
+	Assertion Assertion
+}
+
+func (c *testWebAuthnCircuit) Define(api frontend.API) error {
+	return c.Assertion.Verify(api, c.PublicKey, c.Challenge[:])
+}
+
+// testAssertion generates a WebAuthn assertion for the challenge provided,
+// signed with a new P-256 key, as an authenticator would do.
+func testAssertion(challenge []byte) (*ecdsa.PublicKey, []byte, []byte, []byte, error) {
+	privKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	// rpIdHash ‖ flags (user present and verified) ‖ signCount
+	rpIDHash := sha256.Sum256([]byte("vocdoni.app"))
+	authData := append(rpIDHash[:], 0x05, 0x00, 0x00, 0x00, 0x2a)
+	clientDataJSON := []byte(fmt.Sprintf(
+		`{"type":"webauthn.get","challenge":"%s","origin":"https://vocdoni.app","crossOrigin":false}`,
+		base64.RawURLEncoding.EncodeToString(challenge)))
+	// sign SHA-256(authenticatorData ‖ SHA-256(clientDataJSON))
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	msgHash := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, privKey, msgHash[:])
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	return &privKey.PublicKey, authData, clientDataJSON, signature, nil
+}
+
+func TestWebAuthnAssertion(t *testing.T) {
+	c := qt.New(t)
+	// profiling the circuit compilation
+	circuit := &testWebAuthnCircuit{Assertion: NewAssertion(authDataLen, maxClientDataLen)}
+	p := profile.Start()
+	now := time.Now()
+	_, _ = frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, circuit)
+	fmt.Println("elapsed", time.Since(now))
+	p.Stop()
+	fmt.Println("constrains", p.NbConstraints())
+
+	challenge := make([]byte, challengeLen)
+	_, err := rand.Read(challenge)
+	c.Assert(err, qt.IsNil)
+	pubKey, authData, clientDataJSON, signature, err := testAssertion(challenge)
+	c.Assert(err, qt.IsNil)
+	assertion, err := AssertionFromWebAuthn(authData, clientDataJSON, signature, maxClientDataLen)
+	c.Assert(err, qt.IsNil)
+	witness := &testWebAuthnCircuit{
+		PublicKey: PublicKeyFromECDSA(pubKey),
+		Challenge: [challengeLen]uints.U8(utils.BytesFromSlice(challenge, challengeLen)),
+		Assertion: assertion,
+	}
+	// an assertion for a different challenge must fail
+	invalid := *witness
+	otherChallenge := append([]byte{challenge[0] ^ 0xff}, challenge[1:]...)
+	invalid.Challenge = [challengeLen]uints.U8(utils.BytesFromSlice(otherChallenge, challengeLen))
+
+	assert := test.NewAssert(t)
+	assert.CheckCircuit(circuit,
+		test.WithValidAssignment(witness),
+		test.WithInvalidAssignment(&invalid),
+		test.WithCurves(ecc.BN254),
+		test.WithBackends(backend.GROTH16),
+	)
+}
+
+func TestAssertionFromWebAuthn(t *testing.T) {
+	c := qt.New(t)
+	_, authData, clientDataJSON, signature, err := testAssertion([]byte("challenge"))
+	c.Assert(err, qt.IsNil)
+
+	assertion, err := AssertionFromWebAuthn(authData, clientDataJSON, signature, maxClientDataLen)
+	c.Assert(err, qt.IsNil)
+	c.Assert(assertion.ChallengeOffset, qt.Equals, len(`{"type":"webauthn.get","challenge":"`))
+	c.Assert(assertion.ClientDataLength, qt.Equals, len(clientDataJSON))
+	c.Assert(assertion.ClientDataJSON, qt.HasLen, maxClientDataLen)
+
+	_, err = AssertionFromWebAuthn(authData, clientDataJSON, signature, len(clientDataJSON)-1)
+	c.Assert(err, qt.IsNotNil)
+	_, err = AssertionFromWebAuthn(authData, []byte(`{"type":"webauthn.get"}`), signature, maxClientDataLen)
+	c.Assert(err, qt.IsNotNil)
+	_, err = AssertionFromWebAuthn(authData, clientDataJSON, signature[1:], maxClientDataLen)
+	c.Assert(err, qt.IsNotNil)
+}
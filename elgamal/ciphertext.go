@@ -1,14 +1,19 @@
 package elgamal
 
 import (
+	"fmt"
 	"math/big"
 
 	ecc_tweds "github.com/consensys/gnark-crypto/ecc/twistededwards"
+	"github.com/consensys/gnark/constraint/solver"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/std/algebra/native/twistededwards"
+	"github.com/vocdoni/gnark-crypto-primitives/ecc/msm"
 	"github.com/vocdoni/gnark-crypto-primitives/utils"
 )
 
+func init() { solver.RegisterHint(ScalarSignHint) }
+
 type Ciphertext struct {
 	C1, C2 twistededwards.Point
 }
@@ -45,6 +50,83 @@ func (z *Ciphertext) Neg(api frontend.API, x *Ciphertext) *Ciphertext {
 	return z
 }
 
+// Sub sets z to the difference x-y and returns z, the encryption of the
+// difference of the messages.
+//
+// Panics if twistededwards curve init fails.
+func (z *Ciphertext) Sub(api frontend.API, x, y *Ciphertext) *Ciphertext {
+	curve, err := twistededwards.NewEdCurve(api, ecc_tweds.BN254)
+	if err != nil {
+		panic(err)
+	}
+	z.C1 = curve.Add(x.C1, curve.Neg(y.C1))
+	z.C2 = curve.Add(x.C2, curve.Neg(y.C2))
+	return z
+}
+
+// ScalarSignHint receives a scalar s and returns 1 if it is negative as a
+// signed integer in (-p/2, p/2], that is s > p/2, where p is the modulus of
+// the field, or 0 otherwise.
+var ScalarSignHint solver.Hint = func(field *big.Int, in, out []*big.Int) error {
+	if len(in) != 1 || len(out) != 1 {
+		return fmt.Errorf("expected 1 input and 1 output, got %d and %d", len(in), len(out))
+	}
+	if in[0].Cmp(new(big.Int).Rsh(field, 1)) > 0 {
+		out[0].SetUint64(1)
+	} else {
+		out[0].SetUint64(0)
+	}
+	return nil
+}
+
+// ScalarMul sets z to [s]x, the encryption of the message of x times s, and
+// returns z. The scalar is taken as a signed integer in (-p/2, p/2], where p
+// is the modulus of the scalar field of BN254, so [-s]x is the negation of
+// [s]x (the encryption of -s times the message) and not [p-s]x. A scalar
+// known at compile time (like a constant weight) is multiplied with fixed
+// windows by its absolute value, which costs a few constraints per bit of
+// it, and a variable one with the scalar multiplication of the curve, which
+// requires the points of x to be in the prime order subgroup (as the ones of
+// any ciphertext encrypted with a valid public key), with the sign of the
+// scalar provided by ScalarSignHint.
+//
+// Panics if twistededwards curve init or the hint fails.
+func (z *Ciphertext) ScalarMul(api frontend.API, x *Ciphertext, s frontend.Variable) *Ciphertext {
+	curve, err := twistededwards.NewEdCurve(api, ecc_tweds.BN254)
+	if err != nil {
+		panic(err)
+	}
+	field := api.Compiler().Field()
+	half := new(big.Int).Rsh(field, 1)
+	if c, isConst := api.Compiler().ConstantValue(s); isConst {
+		c = new(big.Int).Mod(c, field)
+		if c.Cmp(half) <= 0 {
+			z.C1 = msm.ConstantScalarMul(curve, x.C1, c)
+			z.C2 = msm.ConstantScalarMul(curve, x.C2, c)
+			return z
+		}
+		c.Sub(field, c)
+		z.C1 = curve.Neg(msm.ConstantScalarMul(curve, x.C1, c))
+		z.C2 = curve.Neg(msm.ConstantScalarMul(curve, x.C2, c))
+		return z
+	}
+	// multiply by |s| and negate the result if s is negative, where the sign
+	// from the hint is the only one with |s| <= p/2 (but for s = 0)
+	res, err := api.Compiler().NewHint(ScalarSignHint, 1, s)
+	if err != nil {
+		panic(err)
+	}
+	isNeg := res[0]
+	api.AssertIsBoolean(isNeg)
+	abs := api.Select(isNeg, api.Neg(s), s)
+	api.AssertIsLessOrEqual(abs, half)
+	c1 := curve.ScalarMul(x.C1, abs)
+	c2 := curve.ScalarMul(x.C2, abs)
+	z.C1 = twistededwards.Point{X: api.Select(isNeg, api.Neg(c1.X), c1.X), Y: c1.Y}
+	z.C2 = twistededwards.Point{X: api.Select(isNeg, api.Neg(c2.X), c2.X), Y: c2.Y}
+	return z
+}
+
 // AssertDecrypt checks if the ciphertext z can be decrypted with privKey
 // to the message m. It returns an error if the curve initialization fails.
 func (z *Ciphertext) AssertDecrypt(api frontend.API, privKey, m frontend.Variable) error {
@@ -135,35 +217,44 @@ func (p *DecryptionProof) Verify(
 	curve.AssertIsOnCurve(pubkey)
 	curve.AssertIsOnCurve(ciphertext.C1)
 	curve.AssertIsOnCurve(ciphertext.C2)
-	curve.AssertIsOnCurve(p.A1)
-	curve.AssertIsOnCurve(p.A2)
 	// M = [msg] * G
 	M := FixedBaseScalarMulBN254(api, msg)
 	// D = C2 - M = C2 + [-M]
 	D := curve.Add(ciphertext.C2, curve.Neg(M))
+	return p.verify(api, curve, hFn, pubkey, ciphertext.C1, D)
+}
+
+// verify checks that the proof is a valid proof that pubkey and D share the
+// same discrete log with respect to G and C1.
+func (p *DecryptionProof) verify(
+	api frontend.API,
+	curve twistededwards.Curve,
+	hFn utils.Hasher,
+	pubkey, C1, D twistededwards.Point,
+) error {
+	curve.AssertIsOnCurve(p.A1)
+	curve.AssertIsOnCurve(p.A2)
 
 	// E (Fiat-Shamir challenge) = hFn(PubKey, PubKey, C1, D, A1, A2)
-	E := hashPointsToScalar(api, hFn, pubkey, pubkey, ciphertext.C1, D, p.A1, p.A2)
-
-	// zG = [z] * G
-	zG := FixedBaseScalarMulBN254(api, p.Z)
-	// eP = [E] * P
-	eP := curve.ScalarMul(pubkey, E)
-	// A1PlusEP = A1 + eP
-	A1PlusEP := curve.Add(p.A1, eP)
-	// z·G == A1 + e·P
-	api.AssertIsEqual(A1PlusEP.X, zG.X)
-	api.AssertIsEqual(A1PlusEP.Y, zG.Y)
-
-	// zC1 = [z] * C1
-	zC1 := curve.ScalarMul(ciphertext.C1, p.Z)
-	// eD = [E] * D
-	eD := curve.ScalarMul(D, E)
-	// A2PlusED = A2 + eD
-	A2PlusED := curve.Add(p.A2, eD)
-	// z·C1 == A2 + e·D
-	api.AssertIsEqual(A2PlusED.X, zC1.X)
-	api.AssertIsEqual(A2PlusED.Y, zC1.Y)
+	E := hashPointsToScalar(api, hFn, pubkey, pubkey, C1, D, p.A1, p.A2)
+
+	// z·G - e·P == A1, with G as a constant to use the fixed-base tables
+	base := curve.Params().Base
+	G := twistededwards.Point{X: base[0], Y: base[1]}
+	zGMinusEP, err := msm.MultiScalarMul(curve, []twistededwards.Point{G, curve.Neg(pubkey)}, []frontend.Variable{p.Z, E})
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(zGMinusEP.X, p.A1.X)
+	api.AssertIsEqual(zGMinusEP.Y, p.A1.Y)
+
+	// z·C1 - e·D == A2, sharing the doublings of both scalar multiplications
+	zC1MinusED, err := msm.MultiScalarMul(curve, []twistededwards.Point{C1, curve.Neg(D)}, []frontend.Variable{p.Z, E})
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(zC1MinusED.X, p.A2.X)
+	api.AssertIsEqual(zC1MinusED.Y, p.A2.Y)
 	return nil
 }
 
@@ -1,7 +1,6 @@
 package elgamal
 
 import (
-	"crypto/rand"
 	"fmt"
 	"math/big"
 	"os"
@@ -9,6 +8,7 @@ import (
 	"time"
 
 	"github.com/consensys/gnark-crypto/ecc"
+	ecc_tweds "github.com/consensys/gnark-crypto/ecc/twistededwards"
 	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/frontend/cs/r1cs"
@@ -16,7 +16,9 @@ import (
 	"github.com/consensys/gnark/profile"
 	"github.com/consensys/gnark/std/algebra/native/twistededwards"
 	"github.com/consensys/gnark/test"
+	qt "github.com/frankban/quicktest"
 	"github.com/iden3/go-iden3-crypto/babyjub"
+	iden3poseidon "github.com/iden3/go-iden3-crypto/poseidon"
 	"github.com/rs/zerolog"
 	"github.com/vocdoni/davinci-node/crypto/ecc/format"
 	"github.com/vocdoni/gnark-crypto-primitives/hash/native/bn254/poseidon"
@@ -38,37 +40,25 @@ func (c *testElGamalAddCircuit) Define(api frontend.API) error {
 
 func TestElGamalAdd(t *testing.T) {
 	// generate a public mocked key and a random k to encrypt first message
-	_, pubKey, err := generateKeyPair(nil)
+	_, pubKey, err := GenerateKey()
 	if err != nil {
 		t.Fatalf("Error generating key pair: %v\n", err)
 	}
-	k1, err := randomK()
+	k1, err := RandomK()
 	if err != nil {
 		t.Fatalf("Error generating random k: %v\n", err)
 	}
 	// encrypt a simple message
-	msg1 := big.NewInt(3)
-	a1, a2 := encrypt(msg1, pubKey, k1)
-	// reduce the points to reduced twisted edwards form
-	xA1RTE, yA1RTE := format.FromTEtoRTE(a1.X, a1.Y)
-	xA2RTE, yA2RTE := format.FromTEtoRTE(a2.X, a2.Y)
+	a := NewNativeCiphertext().Encrypt(pubKey, k1, big.NewInt(3))
 	// generate a second random k to encrypt a second message
-	k2, err := randomK()
+	k2, err := RandomK()
 	if err != nil {
 		t.Fatalf("Error generating random k: %v\n", err)
 	}
 	// encrypt a second simple message
-	msg2 := big.NewInt(5)
-	b1, b2 := encrypt(msg2, pubKey, k2)
-	// reduce the points to reduced twisted edwards form
-	xB1RTE, yB1RTE := format.FromTEtoRTE(b1.X, b1.Y)
-	xB2RTE, yB2RTE := format.FromTEtoRTE(b2.X, b2.Y)
+	b := NewNativeCiphertext().Encrypt(pubKey, k2, big.NewInt(5))
 	// calculate the sum of the encrypted messages to check the homomorphic property
-	c1 := new(babyjub.PointProjective).Add(a1.Projective(), b1.Projective()).Affine()
-	c2 := new(babyjub.PointProjective).Add(a2.Projective(), b2.Projective()).Affine()
-	// reduce the points to reduced twisted edwards form
-	xC1RTE, yC1RTE := format.FromTEtoRTE(c1.X, c1.Y)
-	xC2RTE, yC2RTE := format.FromTEtoRTE(c2.X, c2.Y)
+	sum := NewNativeCiphertext().Add(a, b)
 	// profiling the circuit compilation
 	p := profile.Start()
 	now := time.Now()
@@ -76,39 +66,13 @@ func TestElGamalAdd(t *testing.T) {
 	fmt.Println("elapsed", time.Since(now))
 	p.Stop()
 	fmt.Println("constrains", p.NbConstraints())
-	// run the test to prove the homomorphic property
+	// run the test to prove the homomorphic property, with the points in
+	// reduced twisted edwards form
 	assert := test.NewAssert(t)
 	inputs := &testElGamalAddCircuit{
-		A: Ciphertext{
-			C1: twistededwards.Point{
-				X: xA1RTE,
-				Y: yA1RTE,
-			},
-			C2: twistededwards.Point{
-				X: xA2RTE,
-				Y: yA2RTE,
-			},
-		},
-		B: Ciphertext{
-			C1: twistededwards.Point{
-				X: xB1RTE,
-				Y: yB1RTE,
-			},
-			C2: twistededwards.Point{
-				X: xB2RTE,
-				Y: yB2RTE,
-			},
-		},
-		Sum: Ciphertext{
-			C1: twistededwards.Point{
-				X: xC1RTE,
-				Y: yC1RTE,
-			},
-			C2: twistededwards.Point{
-				X: xC2RTE,
-				Y: yC2RTE,
-			},
-		},
+		A:   CiphertextFromNative(a),
+		B:   CiphertextFromNative(b),
+		Sum: CiphertextFromNative(sum),
 	}
 	now = time.Now()
 	assert.SolvingSucceeded(&testElGamalAddCircuit{}, inputs, test.WithCurves(ecc.BN254), test.WithBackends(backend.GROTH16))
@@ -127,84 +91,30 @@ func (c *testElGamalNegCircuit) Define(api frontend.API) error {
 	return nil
 }
 
-func negatePoint(x, y *big.Int) (*big.Int, *big.Int) {
-	xNeg := new(big.Int).Neg(x)
-	xNeg.Mod(xNeg, ecc.BN254.ScalarField())
-	return xNeg, new(big.Int).Set(y)
-}
-
 func TestElGamalNeg(t *testing.T) {
-	_, pubKey, err := generateKeyPair(big.NewInt(11))
+	_, pubKey, err := GenerateKey()
 	if err != nil {
 		t.Fatalf("Error generating key pair: %v\n", err)
 	}
 
-	k := big.NewInt(17)
-	msg := big.NewInt(3)
-	c1, c2 := encrypt(msg, pubKey, k)
-
-	negC1X, negC1Y := negatePoint(c1.X, c1.Y)
-	negC2X, negC2Y := negatePoint(c2.X, c2.Y)
-
-	xC1RTE, yC1RTE := format.FromTEtoRTE(c1.X, c1.Y)
-	xC2RTE, yC2RTE := format.FromTEtoRTE(c2.X, c2.Y)
-	xNegC1RTE, yNegC1RTE := format.FromTEtoRTE(negC1X, negC1Y)
-	xNegC2RTE, yNegC2RTE := format.FromTEtoRTE(negC2X, negC2Y)
+	ct := NewNativeCiphertext().Encrypt(pubKey, big.NewInt(17), big.NewInt(3))
+	// the negation only changes the sign of the X coordinates
+	negC1X := new(big.Int).Sub(ecc.BN254.ScalarField(), ct.C1.X)
+	negC2X := new(big.Int).Sub(ecc.BN254.ScalarField(), ct.C2.X)
+	neg := &NativeCiphertext{
+		C1: &babyjub.Point{X: negC1X, Y: ct.C1.Y},
+		C2: &babyjub.Point{X: negC2X, Y: ct.C2.Y},
+	}
 
 	assert := test.NewAssert(t)
 	assignments := &testElGamalNegCircuit{
-		In: Ciphertext{
-			C1: twistededwards.Point{X: xC1RTE, Y: yC1RTE},
-			C2: twistededwards.Point{X: xC2RTE, Y: yC2RTE},
-		},
-		Out: Ciphertext{
-			C1: twistededwards.Point{X: xNegC1RTE, Y: yNegC1RTE},
-			C2: twistededwards.Point{X: xNegC2RTE, Y: yNegC2RTE},
-		},
+		In:  CiphertextFromNative(ct),
+		Out: CiphertextFromNative(neg),
 	}
 
 	assert.SolvingSucceeded(&testElGamalNegCircuit{}, assignments, test.WithCurves(ecc.BN254), test.WithBackends(backend.GROTH16))
 }
 
-func randomK() (*big.Int, error) {
-	// Generate random scalar k
-	kBytes := make([]byte, 20)
-	_, err := rand.Read(kBytes)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate random k: %v", err)
-	}
-
-	k := new(big.Int).SetBytes(kBytes)
-	k.Mod(k, babyjub.SubOrder)
-	return k, nil
-}
-
-func generateKeyPair(d *big.Int) (privateKey *big.Int, publicKey *babyjub.PublicKey, err error) {
-	if d == nil {
-		if d, err = rand.Int(rand.Reader, babyjub.SubOrder); err != nil {
-			return nil, nil, fmt.Errorf("failed to generate private key scalar: %v", err)
-		}
-	}
-	if d.Sign() == 0 {
-		d = big.NewInt(1) // avoid zero private keys
-	}
-
-	pubKey := babyjub.NewPoint().Mul(d, babyjub.B8)
-	return d, (*babyjub.PublicKey)(pubKey), nil
-}
-
-func encrypt(message *big.Int, publicKey *babyjub.PublicKey, k *big.Int) (*babyjub.Point, *babyjub.Point) {
-	// c1 = [k] * G
-	c1 := babyjub.NewPoint().Mul(k, babyjub.B8)
-	// s = [k] * publicKey
-	s := babyjub.NewPoint().Mul(k, publicKey.Point())
-	// m = [message] * G
-	m := babyjub.NewPoint().Mul(message, babyjub.B8)
-	// c2 = m + s
-	c2p := babyjub.NewPointProjective().Add(m.Projective(), s.Projective())
-	return c1, c2p.Affine()
-}
-
 type testElGamalEncryptCircuit struct {
 	PrivKey frontend.Variable
 	PubKey  twistededwards.Point `gnark:",public"`
@@ -225,43 +135,26 @@ func (c *testElGamalEncryptCircuit) Define(api frontend.API) error {
 
 func TestEncryptAssertDecrypt(t *testing.T) {
 	// generate a public mocked key and a random k to encrypt first message
-	privKey, pubKey, err := generateKeyPair(nil)
+	privKey, pubKey, err := GenerateKey()
 	if err != nil {
 		t.Fatalf("Error generating key pair: %v\n", err)
 		return
 	}
-	k, err := randomK()
+	k, err := RandomK()
 	if err != nil {
 		t.Fatalf("Error generating random k: %v\n", err)
 		return
 	}
 	// encrypt a simple message
 	msg := big.NewInt(3)
-	a1, a2 := encrypt(msg, pubKey, k)
-	// reduce the points to reduced twisted edwards form
-	xA1RTE, yA1RTE := format.FromTEtoRTE(a1.X, a1.Y)
-	xA2RTE, yA2RTE := format.FromTEtoRTE(a2.X, a2.Y)
-
-	pubKeyX, pubKeyY := format.FromTEtoRTE(pubKey.X, pubKey.Y)
+	ct := NewNativeCiphertext().Encrypt(pubKey, k, msg)
 
 	assignments := &testElGamalEncryptCircuit{
 		PrivKey: privKey,
-		PubKey: twistededwards.Point{
-			X: pubKeyX,
-			Y: pubKeyY,
-		},
-		Result: Ciphertext{
-			C1: twistededwards.Point{
-				X: xA1RTE,
-				Y: yA1RTE,
-			},
-			C2: twistededwards.Point{
-				X: xA2RTE,
-				Y: yA2RTE,
-			},
-		},
-		K:   k,
-		Msg: msg,
+		PubKey:  PointFromNative(pubKey.Point()),
+		Result:  CiphertextFromNative(ct),
+		K:       k,
+		Msg:     msg,
 	}
 
 	assert := test.NewAssert(t)
@@ -334,6 +227,18 @@ func TestVerifyDecryptionProof(t *testing.T) {
 	invalid := *assignments
 	invalid.Proof.A1.Y = big.NewInt(0)
 
+	// the proof must be valid natively too, with the points in twisted
+	// edwards form
+	toTE := func(x, y *big.Int) *babyjub.Point {
+		teX, teY := format.FromRTEtoTE(x, y)
+		return &babyjub.Point{X: teX, Y: teY}
+	}
+	nativeProof := &NativeDecryptionProof{A1: toTE(mockA1X, mockA1Y), A2: toTE(mockA2X, mockA2Y), Z: mockZ}
+	nativeCiphertext := &NativeCiphertext{C1: toTE(c1X, c1Y), C2: toTE(c2X, c2Y)}
+	nativePubKey := (*babyjub.PublicKey)(toTE(pubKeyX, pubKeyY))
+	qt.Assert(t, nativeProof.Verify(iden3poseidon.Hash, nativePubKey, nativeCiphertext, mockMsg), qt.IsNil)
+	qt.Assert(t, nativeProof.Verify(iden3poseidon.Hash, nativePubKey, nativeCiphertext, big.NewInt(51)), qt.IsNotNil)
+
 	assert := test.NewAssert(t)
 	assert.CheckCircuit(
 		&testVerifyDecryptionProofCircuit{},
@@ -343,3 +248,44 @@ func TestVerifyDecryptionProof(t *testing.T) {
 		test.WithBackends(backend.GROTH16),
 	)
 }
+
+// testNaiveDecryptionProofCircuit verifies the decryption proof computing the
+// four scalar multiplications independently, to compare the number of
+// constraints with the multi-scalar multiplication.
+type testNaiveDecryptionProofCircuit struct {
+	PubKey     twistededwards.Point `gnark:",public"`
+	Ciphertext Ciphertext           `gnark:",public"`
+	Proof      DecryptionProof      `gnark:",public"`
+	Msg        frontend.Variable
+}
+
+func (c *testNaiveDecryptionProofCircuit) Define(api frontend.API) error {
+	curve, err := twistededwards.NewEdCurve(api, ecc_tweds.BN254)
+	if err != nil {
+		return err
+	}
+	M := FixedBaseScalarMulBN254(api, c.Msg)
+	D := curve.Add(c.Ciphertext.C2, curve.Neg(M))
+	E := hashPointsToScalar(api, HashFn, c.PubKey, c.PubKey, c.Ciphertext.C1, D, c.Proof.A1, c.Proof.A2)
+	// z·G == A1 + e·P
+	zG := FixedBaseScalarMulBN254(api, c.Proof.Z)
+	A1PlusEP := curve.Add(c.Proof.A1, curve.ScalarMul(c.PubKey, E))
+	api.AssertIsEqual(A1PlusEP.X, zG.X)
+	api.AssertIsEqual(A1PlusEP.Y, zG.Y)
+	// z·C1 == A2 + e·D
+	zC1 := curve.ScalarMul(c.Ciphertext.C1, c.Proof.Z)
+	A2PlusED := curve.Add(c.Proof.A2, curve.ScalarMul(D, E))
+	api.AssertIsEqual(A2PlusED.X, zC1.X)
+	api.AssertIsEqual(A2PlusED.Y, zC1.Y)
+	return nil
+}
+
+func TestDecryptionProofConstraints(t *testing.T) {
+	c := qt.New(t)
+	msm, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &testVerifyDecryptionProofCircuit{})
+	c.Assert(err, qt.IsNil)
+	naive, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &testNaiveDecryptionProofCircuit{})
+	c.Assert(err, qt.IsNil)
+	fmt.Println("constrains msm", msm.GetNbConstraints(), "naive", naive.GetNbConstraints())
+	c.Assert(msm.GetNbConstraints() < naive.GetNbConstraints(), qt.IsTrue)
+}
@@ -0,0 +1,82 @@
+package elgamal
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+
+	ecc_tweds "github.com/consensys/gnark-crypto/ecc/twistededwards"
+	"github.com/consensys/gnark/constraint/solver"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/native/twistededwards"
+	"github.com/iden3/go-iden3-crypto/babyjub"
+	"github.com/vocdoni/davinci-node/crypto/ecc/format"
+)
+
+func init() { solver.RegisterHint(DiscreteLogHint) }
+
+var (
+	// discreteLogTables caches the tables of DiscreteLogHint by their max
+	// message, since a tally decrypts several ciphertexts in the same range.
+	discreteLogTables   = map[uint64]*DiscreteLogTable{}
+	discreteLogTablesMu sync.Mutex
+)
+
+// DiscreteLogHint receives the max message and the coordinates of a point
+// M = [m] * G in Reduced TwistedEdwards format, and returns m, looking for it
+// in [0, max] with the baby-step giant-step algorithm (see DiscreteLogTable).
+// It returns an error if m is out of the range.
+var DiscreteLogHint solver.Hint = func(_ *big.Int, in, out []*big.Int) error {
+	if len(in) != 3 || len(out) != 1 {
+		return fmt.Errorf("expected 3 inputs and 1 output, got %d and %d", len(in), len(out))
+	}
+	if !in[0].IsUint64() {
+		return fmt.Errorf("invalid max message: %s", in[0])
+	}
+	maxMessage := in[0].Uint64()
+	discreteLogTablesMu.Lock()
+	table, ok := discreteLogTables[maxMessage]
+	if !ok {
+		table = NewDiscreteLogTable(maxMessage)
+		discreteLogTables[maxMessage] = table
+	}
+	discreteLogTablesMu.Unlock()
+	x, y := format.FromRTEtoTE(in[1], in[2])
+	m, err := table.DiscreteLog(&babyjub.Point{X: x, Y: y})
+	if err != nil {
+		return err
+	}
+	out[0].SetUint64(m)
+	return nil
+}
+
+// Decrypt returns the message m encrypted in z with the public key of
+// privKey, for the messages in [0, maxValue], like the results of a tally.
+// It computes M = C2 - [privKey] * C1 and obtains m from DiscreteLogHint,
+// constraining m <= maxValue and [m] * G == M, so m is the only message of
+// the range encrypted in z. It returns an error if the curve initialization
+// fails. The circuit is not satisfiable if z does not decrypt to a message of
+// the range.
+func (z *Ciphertext) Decrypt(api frontend.API, privKey frontend.Variable, maxValue uint64) (frontend.Variable, error) {
+	curve, err := twistededwards.NewEdCurve(api, ecc_tweds.BN254)
+	if err != nil {
+		return nil, err
+	}
+	curve.AssertIsOnCurve(z.C1)
+	curve.AssertIsOnCurve(z.C2)
+	// M = C2 - [privKey] * C1
+	S := curve.ScalarMul(z.C1, privKey)
+	M := curve.Add(z.C2, curve.Neg(S))
+	res, err := api.Compiler().NewHint(DiscreteLogHint, 1, maxValue, M.X, M.Y)
+	if err != nil {
+		return nil, err
+	}
+	m := res[0]
+	// m <= maxValue < order of the subgroup, so [m] * G == M has a single
+	// solution in the range
+	api.AssertIsLessOrEqual(m, maxValue)
+	mG := FixedBaseScalarMulBN254(api, m)
+	api.AssertIsEqual(mG.X, M.X)
+	api.AssertIsEqual(mG.Y, M.Y)
+	return m, nil
+}
@@ -0,0 +1,112 @@
+package elgamal
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/constraint/solver"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/test"
+	qt "github.com/frankban/quicktest"
+	"github.com/iden3/go-iden3-crypto/babyjub"
+)
+
+// testDecryptMax is the max message of the decryption test circuit
+const testDecryptMax = 1000
+
+type testDecryptCircuit struct {
+	PrivKey    frontend.Variable
+	Ciphertext Ciphertext        `gnark:",public"`
+	Msg        frontend.Variable `gnark:",public"`
+}
+
+func (c *testDecryptCircuit) Define(api frontend.API) error {
+	m, err := c.Ciphertext.Decrypt(api, c.PrivKey, testDecryptMax)
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(m, c.Msg)
+	return nil
+}
+
+func TestDecrypt(t *testing.T) {
+	c := qt.New(t)
+	privKey, pubKey, err := GenerateKey()
+	c.Assert(err, qt.IsNil)
+	otherPrivKey, _, err := GenerateKey()
+	c.Assert(err, qt.IsNil)
+
+	assert := test.NewAssert(t)
+	for _, msg := range []int64{0, 1, 517, testDecryptMax} {
+		k, err := RandomK()
+		c.Assert(err, qt.IsNil)
+		ct := NewNativeCiphertext().Encrypt(pubKey, k, big.NewInt(msg))
+		witness := &testDecryptCircuit{
+			PrivKey:    privKey,
+			Ciphertext: CiphertextFromNative(ct),
+			Msg:        msg,
+		}
+		otherMsg := *witness
+		otherMsg.Msg = msg + 1
+		otherKey := *witness
+		otherKey.PrivKey = otherPrivKey
+		assert.CheckCircuit(&testDecryptCircuit{},
+			test.WithValidAssignment(witness),
+			test.WithInvalidAssignment(&otherMsg),
+			test.WithInvalidAssignment(&otherKey),
+			test.WithCurves(ecc.BN254),
+			test.WithBackends(backend.GROTH16),
+		)
+	}
+	// a message out of the range can not be decrypted
+	k, err := RandomK()
+	c.Assert(err, qt.IsNil)
+	ct := NewNativeCiphertext().Encrypt(pubKey, k, big.NewInt(testDecryptMax+1))
+	c.Assert(test.IsSolved(&testDecryptCircuit{}, &testDecryptCircuit{
+		PrivKey:    privKey,
+		Ciphertext: CiphertextFromNative(ct),
+		Msg:        testDecryptMax + 1,
+	}, ecc.BN254.ScalarField()), qt.IsNotNil)
+}
+
+func TestDecryptMaliciousHint(t *testing.T) {
+	c := qt.New(t)
+	privKey, pubKey, err := GenerateKey()
+	c.Assert(err, qt.IsNil)
+	k, err := RandomK()
+	c.Assert(err, qt.IsNil)
+	msg := int64(42)
+	ct := NewNativeCiphertext().Encrypt(pubKey, k, big.NewInt(msg))
+
+	cs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &testDecryptCircuit{})
+	c.Assert(err, qt.IsNil)
+	fmt.Println("constrains", cs.GetNbConstraints())
+
+	// the hint can only return the message encrypted, not another one with
+	// the same point out of the range, m + order
+	for _, offset := range []*big.Int{big.NewInt(0), big.NewInt(1), babyjub.SubOrder} {
+		hint := func(field *big.Int, in, out []*big.Int) error {
+			if err := DiscreteLogHint(field, in, out); err != nil {
+				return err
+			}
+			out[0].Add(out[0], offset)
+			return nil
+		}
+		witness, err := frontend.NewWitness(&testDecryptCircuit{
+			PrivKey:    privKey,
+			Ciphertext: CiphertextFromNative(ct),
+			Msg:        new(big.Int).Add(big.NewInt(msg), offset),
+		}, ecc.BN254.ScalarField())
+		c.Assert(err, qt.IsNil)
+		_, err = cs.Solve(witness, solver.OverrideHint(solver.GetHintID(DiscreteLogHint), hint))
+		if offset.Sign() == 0 {
+			c.Assert(err, qt.IsNil)
+		} else {
+			c.Assert(err, qt.IsNotNil)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package elgamal
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+
+	"github.com/iden3/go-iden3-crypto/babyjub"
+	"github.com/vocdoni/gnark-crypto-primitives/utils"
+)
+
+// Dealer is a trustee of the distributed key generation of a threshold
+// ElGamal key (Pedersen's DKG), where every trustee deals the shares of a
+// random secret with Feldman's verifiable secret sharing. The private key is
+// the sum of the secrets of the dealers, and it is never computed: every
+// trustee receives the sum of the shares of the dealers at its index, which
+// are the shares of the private key for a threshold of trustees.
+//
+// The indexes of the trustees start at 1, because the secret is the value of
+// the polynomial at 0.
+type Dealer struct {
+	Index int
+	// Commitments are the commitments to the coefficients of the polynomial
+	// of the dealer, [a_k] * G, to be published to the other trustees.
+	Commitments []*babyjub.Point
+	coeffs      []*big.Int
+}
+
+// NewDealer returns a new Dealer with the index provided and a random
+// polynomial of degree threshold-1, so any threshold trustees can decrypt.
+func NewDealer(index, threshold int) (*Dealer, error) {
+	if index < 1 {
+		return nil, fmt.Errorf("invalid dealer index: %d", index)
+	}
+	if threshold < 1 {
+		return nil, fmt.Errorf("invalid threshold: %d", threshold)
+	}
+	d := &Dealer{
+		Index:       index,
+		Commitments: make([]*babyjub.Point, threshold),
+		coeffs:      make([]*big.Int, threshold),
+	}
+	for k := range d.coeffs {
+		coeff, err := rand.Int(rand.Reader, babyjub.SubOrder)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate coefficient: %w", err)
+		}
+		d.coeffs[k] = coeff
+		d.Commitments[k] = babyjub.NewPoint().Mul(coeff, babyjub.B8)
+	}
+	return d, nil
+}
+
+// Share returns the share of the secret of the dealer for the trustee with
+// the index provided, that is the value of the polynomial at the index. It
+// must be sent privately to the trustee, which should check it with
+// VerifyShare.
+func (d *Dealer) Share(index int) (*big.Int, error) {
+	if index < 1 {
+		return nil, fmt.Errorf("invalid trustee index: %d", index)
+	}
+	// Horner's rule: f(x) = a_0 + x * (a_1 + x * (a_2 + ...))
+	x := big.NewInt(int64(index))
+	share := new(big.Int)
+	for k := len(d.coeffs) - 1; k >= 0; k-- {
+		share.Mul(share, x).Add(share, d.coeffs[k]).Mod(share, babyjub.SubOrder)
+	}
+	return share, nil
+}
+
+// VerifyShare checks that the share received by the trustee with the index
+// provided is the value at the index of the polynomial of the commitments of
+// the dealer, that is [share] * G = Σ [index^k] * Commitments[k]. It returns
+// an error if it is not, so the trustee can complain against the dealer.
+func VerifyShare(commitments []*babyjub.Point, index int, share *big.Int) error {
+	if babyjub.NewPoint().Mul(share, babyjub.B8).Compress() != evalCommitments(commitments, index).Compress() {
+		return fmt.Errorf("invalid share for the trustee %d", index)
+	}
+	return nil
+}
+
+// CombineShares returns the share of the private key of a trustee, that is
+// the sum of the shares received from every dealer (including its own).
+func CombineShares(shares ...*big.Int) *big.Int {
+	keyShare := new(big.Int)
+	for _, share := range shares {
+		keyShare.Add(keyShare, share)
+	}
+	return keyShare.Mod(keyShare, babyjub.SubOrder)
+}
+
+// DKGPublicKey returns the public key of the distributed key generation, that
+// is the sum of the commitments to the secrets of the dealers provided.
+func DKGPublicKey(commitments ...[]*babyjub.Point) *babyjub.PublicKey {
+	pubKey := babyjub.NewPoint()
+	for _, c := range commitments {
+		pubKey = addPoints(pubKey, c[0])
+	}
+	return (*babyjub.PublicKey)(pubKey)
+}
+
+// VerificationKey returns the public key of the share of the private key of
+// the trustee with the index provided, [keyShare] * G, computed from the
+// commitments of every dealer. It is used to verify the decryption shares of
+// the trustee.
+func VerificationKey(index int, commitments ...[]*babyjub.Point) *babyjub.Point {
+	key := babyjub.NewPoint()
+	for _, c := range commitments {
+		key = addPoints(key, evalCommitments(c, index))
+	}
+	return key
+}
+
+// LagrangeCoefficient returns the Lagrange coefficient at 0 of the trustee
+// with the index provided for the set of indexes of the trustees that
+// decrypt, that is Π j / (j - index) for every other index j, modulo the
+// order of the subgroup.
+func LagrangeCoefficient(index int, indexes []int) (*big.Int, error) {
+	num, den := big.NewInt(1), big.NewInt(1)
+	found := false
+	for _, j := range indexes {
+		if j < 1 {
+			return nil, fmt.Errorf("invalid trustee index: %d", j)
+		}
+		if j == index {
+			if found {
+				return nil, fmt.Errorf("duplicated trustee index: %d", j)
+			}
+			found = true
+			continue
+		}
+		num.Mul(num, big.NewInt(int64(j)))
+		den.Mul(den, big.NewInt(int64(j-index)))
+	}
+	if !found {
+		return nil, fmt.Errorf("the trustee %d is not in the set of indexes", index)
+	}
+	den.Mod(den, babyjub.SubOrder)
+	if den.ModInverse(den, babyjub.SubOrder) == nil {
+		return nil, fmt.Errorf("duplicated trustee indexes")
+	}
+	return num.Mul(num, den).Mod(num, babyjub.SubOrder), nil
+}
+
+// NativeDecryptionShare is the partial decryption of a ciphertext by the
+// trustee with the index provided, D = [keyShare] * C1, with a proof that it
+// shares the discrete log with the verification key of the trustee. It is
+// the off-circuit counterpart of DecryptionShare.
+type NativeDecryptionShare struct {
+	Index int
+	D     *babyjub.Point
+	Proof *NativeDecryptionProof
+}
+
+// ProveDecryptionShare returns the partial decryption of the ciphertext with
+// the share of the private key of the trustee with the index provided, and
+// the proof of its correctness, with the transcript of the decryption proofs.
+func ProveDecryptionShare(hFn utils.NativeHasher, index int, keyShare *big.Int, ct *NativeCiphertext) (*NativeDecryptionShare, error) {
+	keyShare = new(big.Int).Mod(keyShare, babyjub.SubOrder)
+	d := babyjub.NewPoint().Mul(keyShare, ct.C1)
+	proof, err := proveDLEQ(hFn, keyShare, ct.C1, d)
+	if err != nil {
+		return nil, err
+	}
+	return &NativeDecryptionShare{Index: index, D: d, Proof: proof}, nil
+}
+
+// Verify checks natively that the decryption share is the partial decryption
+// of the ciphertext by the trustee of the verification key provided. It
+// returns an error if it is not.
+func (s *NativeDecryptionShare) Verify(hFn utils.NativeHasher, verificationKey *babyjub.Point, ct *NativeCiphertext) error {
+	return s.Proof.verify(hFn, verificationKey, ct.C1, s.D)
+}
+
+// CombineDecryptionShares returns the message of the ciphertext from the
+// decryption shares of a threshold of trustees, that is the discrete log of
+// C2 - Σ [λ_i] * D_i, where λ_i are the Lagrange coefficients of the indexes
+// of the shares, looking for it in the range of the table provided. The
+// shares should be verified first.
+func CombineDecryptionShares(ct *NativeCiphertext, shares []*NativeDecryptionShare, table *DiscreteLogTable) (*big.Int, error) {
+	indexes := make([]int, len(shares))
+	for i, s := range shares {
+		indexes[i] = s.Index
+	}
+	m := ct.C2
+	for _, s := range shares {
+		lambda, err := LagrangeCoefficient(s.Index, indexes)
+		if err != nil {
+			return nil, err
+		}
+		m = addPoints(m, negPoint(babyjub.NewPoint().Mul(lambda, s.D)))
+	}
+	res, err := table.DiscreteLog(m)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetUint64(res), nil
+}
+
+// DecryptionShareFromNative converts a native decryption share and the
+// verification key of its trustee to a circuit decryption share, with the
+// points in Reduced TwistedEdwards format.
+func DecryptionShareFromNative(s *NativeDecryptionShare, verificationKey *babyjub.Point) DecryptionShare {
+	return DecryptionShare{
+		Index:           s.Index,
+		VerificationKey: PointFromNative(verificationKey),
+		D:               PointFromNative(s.D),
+		Proof:           DecryptionProofFromNative(s.Proof),
+	}
+}
+
+// evalCommitments returns Σ [index^k] * commitments[k], that is [f(index)] * G
+// for the polynomial f of the commitments.
+func evalCommitments(commitments []*babyjub.Point, index int) *babyjub.Point {
+	x := big.NewInt(int64(index))
+	res := babyjub.NewPoint()
+	for k := len(commitments) - 1; k >= 0; k-- {
+		res = addPoints(babyjub.NewPoint().Mul(x, res), commitments[k])
+	}
+	return res
+}
@@ -0,0 +1,257 @@
+package elgamal
+
+import (
+	"math/big"
+
+	edbn254 "github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bn254"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/iden3/go-iden3-crypto/babyjub"
+	"github.com/vocdoni/davinci-node/crypto/ecc/format"
+	circuitformat "github.com/vocdoni/gnark-crypto-primitives/ecc/format"
+)
+
+// EmulatedPoint is a point of BabyJubJub in Reduced TwistedEdwards format,
+// like the points of the native circuits, with the coordinates emulated in
+// the scalar field of BN254. It allows to use the points of the inner BN254
+// circuits in the outer circuits of a recursion (like BW6-761).
+type EmulatedPoint struct {
+	X, Y emulated.Element[sw_bn254.ScalarField]
+}
+
+// EmulatedCiphertext is the emulated counterpart of Ciphertext, to accumulate
+// homomorphic tallies of the ciphertexts of inner BN254 circuits in outer
+// circuits over other curves.
+type EmulatedCiphertext struct {
+	C1, C2 EmulatedPoint
+}
+
+// NewEmulatedCiphertext returns a new EmulatedCiphertext with both points set
+// to the identity.
+func NewEmulatedCiphertext() *EmulatedCiphertext {
+	zero := EmulatedPoint{
+		X: emulated.ValueOf[sw_bn254.ScalarField](0),
+		Y: emulated.ValueOf[sw_bn254.ScalarField](1),
+	}
+	return &EmulatedCiphertext{C1: zero, C2: zero}
+}
+
+// Add sets z to the sum x+y and returns z.
+//
+// Panics if the emulated field init fails.
+func (z *EmulatedCiphertext) Add(api frontend.API, x, y *EmulatedCiphertext) *EmulatedCiphertext {
+	curve := mustEmulatedCurve(api)
+	z.C1 = curve.add(x.C1, y.C1)
+	z.C2 = curve.add(x.C2, y.C2)
+	return z
+}
+
+// Neg sets z to the negation of x and returns z.
+//
+// Panics if the emulated field init fails.
+func (z *EmulatedCiphertext) Neg(api frontend.API, x *EmulatedCiphertext) *EmulatedCiphertext {
+	curve := mustEmulatedCurve(api)
+	z.C1 = curve.neg(x.C1)
+	z.C2 = curve.neg(x.C2)
+	return z
+}
+
+// Encrypt sets z to the encryption of the message provided with the public
+// key and the random k provided, and returns z, as Ciphertext.Encrypt does
+// in the native circuits:
+//
+//	C1 = [k] * G, C2 = [msg] * G + [k] * PublicKey
+//
+// C2 is computed with a joint scalar multiplication that shares the
+// doublings. It returns an error if the emulated field init fails.
+func (z *EmulatedCiphertext) Encrypt(api frontend.API, pubKey EmulatedPoint, k, msg emulated.Element[sw_bn254.ScalarField]) (*EmulatedCiphertext, error) {
+	curve, err := newEmulatedCurve(api)
+	if err != nil {
+		return nil, err
+	}
+	curve.assertIsOnCurve(pubKey)
+	// the scalars must be canonical, since the field is not the order of the
+	// curve
+	kBits := curve.fr.ToBitsCanonical(&k)
+	msgBits := curve.fr.ToBitsCanonical(&msg)
+	G := curve.base()
+	z.C1 = curve.scalarMul(G, kBits)
+	z.C2 = curve.doubleBaseScalarMul(G, pubKey, msgBits, kBits)
+	return z, nil
+}
+
+// AssertIsEqual fails if any of the fields differ between z and x.
+//
+// Panics if the emulated field init fails.
+func (z *EmulatedCiphertext) AssertIsEqual(api frontend.API, x *EmulatedCiphertext) {
+	curve := mustEmulatedCurve(api)
+	curve.fr.AssertIsEqual(&z.C1.X, &x.C1.X)
+	curve.fr.AssertIsEqual(&z.C1.Y, &x.C1.Y)
+	curve.fr.AssertIsEqual(&z.C2.X, &x.C2.X)
+	curve.fr.AssertIsEqual(&z.C2.Y, &x.C2.Y)
+}
+
+// Serialize returns a slice with the C1.X, C1.Y, C2.X, C2.Y in order, in
+// Reduced TwistedEdwards format.
+func (z *EmulatedCiphertext) Serialize() []emulated.Element[sw_bn254.ScalarField] {
+	return []emulated.Element[sw_bn254.ScalarField]{
+		z.C1.X,
+		z.C1.Y,
+		z.C2.X,
+		z.C2.Y,
+	}
+}
+
+// FromTEtoRTE sets z to the ciphertext x with the points in TwistedEdwards
+// format (like the ones of Iden3) converted to Reduced TwistedEdwards format,
+// and returns z.
+func (z *EmulatedCiphertext) FromTEtoRTE(api frontend.API, x *EmulatedCiphertext) (*EmulatedCiphertext, error) {
+	c1x, c1y, err := circuitformat.FromEmulatedTEtoRTE(api, x.C1.X, x.C1.Y)
+	if err != nil {
+		return nil, err
+	}
+	c2x, c2y, err := circuitformat.FromEmulatedTEtoRTE(api, x.C2.X, x.C2.Y)
+	if err != nil {
+		return nil, err
+	}
+	z.C1 = EmulatedPoint{X: c1x, Y: c1y}
+	z.C2 = EmulatedPoint{X: c2x, Y: c2y}
+	return z, nil
+}
+
+// EmulatedCiphertextFromNative converts a native ciphertext to an emulated
+// ciphertext, with the points in Reduced TwistedEdwards format.
+func EmulatedCiphertextFromNative(ct *NativeCiphertext) EmulatedCiphertext {
+	return EmulatedCiphertext{
+		C1: EmulatedPointFromNative(ct.C1),
+		C2: EmulatedPointFromNative(ct.C2),
+	}
+}
+
+// EmulatedPointFromNative converts a native point in TwistedEdwards format
+// (like a public key) to an emulated point in Reduced TwistedEdwards format.
+func EmulatedPointFromNative(p *babyjub.Point) EmulatedPoint {
+	x, y := format.FromTEtoRTE(p.X, p.Y)
+	return EmulatedPoint{
+		X: emulated.ValueOf[sw_bn254.ScalarField](x),
+		Y: emulated.ValueOf[sw_bn254.ScalarField](y),
+	}
+}
+
+// emulatedCurve implements the BabyJubJub point arithmetic in Reduced
+// TwistedEdwards format (a = -1) over the emulated scalar field of BN254. The
+// addition formulas are complete because d is not a square, so they work for
+// any pair of points, including the identity (0, 1).
+type emulatedCurve struct {
+	fr *emulated.Field[sw_bn254.ScalarField]
+	d  *emulated.Element[sw_bn254.ScalarField]
+}
+
+func newEmulatedCurve(api frontend.API) (*emulatedCurve, error) {
+	fr, err := emulated.NewField[sw_bn254.ScalarField](api)
+	if err != nil {
+		return nil, err
+	}
+	params := edbn254.GetEdwardsCurve()
+	return &emulatedCurve{fr: fr, d: fr.NewElement(params.D.BigInt(new(big.Int)))}, nil
+}
+
+func mustEmulatedCurve(api frontend.API) *emulatedCurve {
+	curve, err := newEmulatedCurve(api)
+	if err != nil {
+		panic(err)
+	}
+	return curve
+}
+
+// identity returns the neutral point (0, 1).
+func (c *emulatedCurve) identity() EmulatedPoint {
+	return EmulatedPoint{X: *c.fr.Zero(), Y: *c.fr.One()}
+}
+
+// base returns the base point G of the native circuits.
+func (c *emulatedCurve) base() EmulatedPoint {
+	params := edbn254.GetEdwardsCurve()
+	return EmulatedPoint{
+		X: *c.fr.NewElement(params.Base.X.BigInt(new(big.Int))),
+		Y: *c.fr.NewElement(params.Base.Y.BigInt(new(big.Int))),
+	}
+}
+
+// assertIsOnCurve fails if p is not on the curve: -x² + y² = 1 + d·x²·y².
+func (c *emulatedCurve) assertIsOnCurve(p EmulatedPoint) {
+	x2 := c.fr.Mul(&p.X, &p.X)
+	y2 := c.fr.Mul(&p.Y, &p.Y)
+	lhs := c.fr.Sub(y2, x2)
+	rhs := c.fr.Add(c.fr.One(), c.fr.Mul(c.d, c.fr.Mul(x2, y2)))
+	c.fr.AssertIsEqual(lhs, rhs)
+}
+
+// neg returns -p = (-x, y).
+func (c *emulatedCurve) neg(p EmulatedPoint) EmulatedPoint {
+	return EmulatedPoint{X: *c.fr.Neg(&p.X), Y: p.Y}
+}
+
+// add returns p + q:
+//
+//	x = (x1·y2 + y1·x2) / (1 + d·x1·x2·y1·y2)
+//	y = (y1·y2 + x1·x2) / (1 - d·x1·x2·y1·y2)
+func (c *emulatedCurve) add(p, q EmulatedPoint) EmulatedPoint {
+	x1y2 := c.fr.Mul(&p.X, &q.Y)
+	y1x2 := c.fr.Mul(&p.Y, &q.X)
+	x1x2 := c.fr.Mul(&p.X, &q.X)
+	y1y2 := c.fr.Mul(&p.Y, &q.Y)
+	dxy := c.fr.Mul(c.d, c.fr.Mul(x1x2, y1y2))
+	x := c.fr.Div(c.fr.Add(x1y2, y1x2), c.fr.Add(c.fr.One(), dxy))
+	y := c.fr.Div(c.fr.Add(y1y2, x1x2), c.fr.Sub(c.fr.One(), dxy))
+	return EmulatedPoint{X: *x, Y: *y}
+}
+
+// double returns 2p, using the curve equation to remove d from the addition
+// formulas:
+//
+//	x = 2·x·y / (y² - x²)
+//	y = (y² + x²) / (2 - y² + x²)
+func (c *emulatedCurve) double(p EmulatedPoint) EmulatedPoint {
+	xy := c.fr.Mul(&p.X, &p.Y)
+	x2 := c.fr.Mul(&p.X, &p.X)
+	y2 := c.fr.Mul(&p.Y, &p.Y)
+	x := c.fr.Div(c.fr.Add(xy, xy), c.fr.Sub(y2, x2))
+	y := c.fr.Div(c.fr.Add(y2, x2), c.fr.Sub(c.fr.NewElement(2), c.fr.Sub(y2, x2)))
+	return EmulatedPoint{X: *x, Y: *y}
+}
+
+// selectPoint returns p1 if b is true, else p2.
+func (c *emulatedCurve) selectPoint(b frontend.Variable, p1, p2 EmulatedPoint) EmulatedPoint {
+	return EmulatedPoint{
+		X: *c.fr.Select(b, &p1.X, &p2.X),
+		Y: *c.fr.Select(b, &p1.Y, &p2.Y),
+	}
+}
+
+// scalarMul returns [s]p, with the scalar provided as little-endian bits.
+func (c *emulatedCurve) scalarMul(p EmulatedPoint, s []frontend.Variable) EmulatedPoint {
+	res := c.identity()
+	for i := len(s) - 1; i >= 0; i-- {
+		res = c.double(res)
+		res = c.add(res, c.selectPoint(s[i], p, c.identity()))
+	}
+	return res
+}
+
+// doubleBaseScalarMul returns [s1]p1 + [s2]p2 using the Straus-Shamir trick,
+// with the scalars provided as little-endian bits of the same length.
+func (c *emulatedCurve) doubleBaseScalarMul(p1, p2 EmulatedPoint, s1, s2 []frontend.Variable) EmulatedPoint {
+	p12 := c.add(p1, p2)
+	res := c.identity()
+	for i := len(s1) - 1; i >= 0; i-- {
+		res = c.double(res)
+		entry := EmulatedPoint{
+			X: *c.fr.Lookup2(s1[i], s2[i], c.fr.Zero(), &p1.X, &p2.X, &p12.X),
+			Y: *c.fr.Lookup2(s1[i], s2[i], c.fr.One(), &p1.Y, &p2.Y, &p12.Y),
+		}
+		res = c.add(res, entry)
+	}
+	return res
+}
@@ -0,0 +1,122 @@
+package elgamal
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bn254"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/test"
+	qt "github.com/frankban/quicktest"
+	"github.com/iden3/go-iden3-crypto/babyjub"
+)
+
+type testEmulatedCiphertextCircuit struct {
+	PubKey EmulatedPoint `gnark:",public"`
+	K, Msg emulated.Element[sw_bn254.ScalarField]
+	A, B   EmulatedCiphertext `gnark:",public"`
+	// B with the points in TwistedEdwards format
+	BTE EmulatedCiphertext
+	// natively computed ciphertexts
+	Encrypted, Sum, Neg EmulatedCiphertext
+}
+
+func (c *testEmulatedCiphertextCircuit) Define(api frontend.API) error {
+	encrypted, err := NewEmulatedCiphertext().Encrypt(api, c.PubKey, c.K, c.Msg)
+	if err != nil {
+		return err
+	}
+	encrypted.AssertIsEqual(api, &c.Encrypted)
+	NewEmulatedCiphertext().Add(api, &c.A, &c.B).AssertIsEqual(api, &c.Sum)
+	NewEmulatedCiphertext().Neg(api, &c.A).AssertIsEqual(api, &c.Neg)
+	b, err := NewEmulatedCiphertext().FromTEtoRTE(api, &c.BTE)
+	if err != nil {
+		return err
+	}
+	b.AssertIsEqual(api, &c.B)
+	if len(b.Serialize()) != 4 {
+		return fmt.Errorf("unexpected serialization length")
+	}
+	return nil
+}
+
+func TestEmulatedCiphertext(t *testing.T) {
+	c := qt.New(t)
+	_, pubKey, err := GenerateKey()
+	c.Assert(err, qt.IsNil)
+	k1, err := RandomK()
+	c.Assert(err, qt.IsNil)
+	k2, err := RandomK()
+	c.Assert(err, qt.IsNil)
+	m1, m2 := big.NewInt(3), big.NewInt(12)
+
+	a := NewNativeCiphertext().Encrypt(pubKey, k1, m1)
+	b := NewNativeCiphertext().Encrypt(pubKey, k2, m2)
+	teElement := func(v *big.Int) emulated.Element[sw_bn254.ScalarField] {
+		return emulated.ValueOf[sw_bn254.ScalarField](v)
+	}
+	teCiphertext := func(ct *NativeCiphertext) EmulatedCiphertext {
+		return EmulatedCiphertext{
+			C1: EmulatedPoint{X: teElement(ct.C1.X), Y: teElement(ct.C1.Y)},
+			C2: EmulatedPoint{X: teElement(ct.C2.X), Y: teElement(ct.C2.Y)},
+		}
+	}
+	witness := &testEmulatedCiphertextCircuit{
+		PubKey:    EmulatedPointFromNative(pubKey.Point()),
+		K:         emulated.ValueOf[sw_bn254.ScalarField](k1),
+		Msg:       emulated.ValueOf[sw_bn254.ScalarField](m1),
+		A:         EmulatedCiphertextFromNative(a),
+		B:         EmulatedCiphertextFromNative(b),
+		BTE:       teCiphertext(b),
+		Encrypted: EmulatedCiphertextFromNative(a),
+		Sum:       EmulatedCiphertextFromNative(NewNativeCiphertext().Add(a, b)),
+		Neg:       EmulatedCiphertextFromNative(NewNativeCiphertext().Neg(a)),
+	}
+	field := ecc.BW6_761.ScalarField()
+	c.Assert(test.IsSolved(&testEmulatedCiphertextCircuit{}, witness, field), qt.IsNil)
+	// the encryption of another message
+	otherMsg := *witness
+	otherMsg.Msg = emulated.ValueOf[sw_bn254.ScalarField](m2)
+	c.Assert(test.IsSolved(&testEmulatedCiphertextCircuit{}, &otherMsg, field), qt.IsNotNil)
+	// the sum with another ciphertext
+	otherSum := *witness
+	otherSum.Sum = EmulatedCiphertextFromNative(NewNativeCiphertext().Add(a, a))
+	c.Assert(test.IsSolved(&testEmulatedCiphertextCircuit{}, &otherSum, field), qt.IsNotNil)
+	// a public key out of the curve
+	offCurve := *witness
+	offCurve.PubKey = EmulatedPointFromNative(&babyjub.Point{X: big.NewInt(1), Y: big.NewInt(2)})
+	c.Assert(test.IsSolved(&testEmulatedCiphertextCircuit{}, &offCurve, field), qt.IsNotNil)
+}
+
+type testEmulatedAddCircuit struct {
+	A, B, Sum EmulatedCiphertext
+}
+
+func (c *testEmulatedAddCircuit) Define(api frontend.API) error {
+	NewEmulatedCiphertext().Add(api, &c.A, &c.B).AssertIsEqual(api, &c.Sum)
+	return nil
+}
+
+type testEmulatedEncryptCircuit struct {
+	PubKey EmulatedPoint
+	K, Msg emulated.Element[sw_bn254.ScalarField]
+}
+
+func (c *testEmulatedEncryptCircuit) Define(api frontend.API) error {
+	_, err := NewEmulatedCiphertext().Encrypt(api, c.PubKey, c.K, c.Msg)
+	return err
+}
+
+func TestEmulatedCiphertextConstraints(t *testing.T) {
+	c := qt.New(t)
+	cs, err := frontend.Compile(ecc.BW6_761.ScalarField(), r1cs.NewBuilder, &testEmulatedAddCircuit{})
+	c.Assert(err, qt.IsNil)
+	fmt.Println("emulated add constrains (bw6-761)", cs.GetNbConstraints())
+	cs, err = frontend.Compile(ecc.BW6_761.ScalarField(), r1cs.NewBuilder, &testEmulatedEncryptCircuit{})
+	c.Assert(err, qt.IsNil)
+	fmt.Println("emulated encrypt constrains (bw6-761)", cs.GetNbConstraints())
+}
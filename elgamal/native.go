@@ -0,0 +1,708 @@
+package elgamal
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/std/algebra/native/twistededwards"
+	"github.com/iden3/go-iden3-crypto/babyjub"
+	"github.com/iden3/go-iden3-crypto/poseidon"
+	"github.com/vocdoni/davinci-node/crypto/ecc/format"
+	"github.com/vocdoni/gnark-crypto-primitives/utils"
+)
+
+// NativeCiphertext is an ElGamal ciphertext computed natively, with the
+// points in TwistedEdwards format. It is the off-circuit counterpart of
+// Ciphertext, using B8 as the base point, which is the base point of the
+// circuit in Reduced TwistedEdwards format.
+type NativeCiphertext struct {
+	C1, C2 *babyjub.Point
+}
+
+// NewNativeCiphertext returns a new NativeCiphertext with both points set to
+// the identity, which is the encryption of zero with k = 0.
+func NewNativeCiphertext() *NativeCiphertext {
+	return &NativeCiphertext{C1: babyjub.NewPoint(), C2: babyjub.NewPoint()}
+}
+
+// GenerateKey returns a random private key in [1, SubOrder) and its public
+// key, [privKey] * B8.
+func GenerateKey() (*big.Int, *babyjub.PublicKey, error) {
+	privKey, err := rand.Int(rand.Reader, new(big.Int).Sub(babyjub.SubOrder, big.NewInt(1)))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate private key: %w", err)
+	}
+	privKey.Add(privKey, big.NewInt(1))
+	return privKey, (*babyjub.PublicKey)(babyjub.NewPoint().Mul(privKey, babyjub.B8)), nil
+}
+
+// RandomK returns a random k in [0, SubOrder) to encrypt a message.
+func RandomK() (*big.Int, error) {
+	k, err := rand.Int(rand.Reader, babyjub.SubOrder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate random k: %w", err)
+	}
+	return k, nil
+}
+
+// Encrypt sets z to the encryption of the message m with the public key and
+// the random k provided, and returns z. It computes the same ciphertext as
+// Ciphertext.Encrypt does in the circuit:
+//
+//	C1 = [k] * G
+//	C2 = [m] * G + [k] * pubKey
+func (z *NativeCiphertext) Encrypt(pubKey *babyjub.PublicKey, k, m *big.Int) *NativeCiphertext {
+	k = new(big.Int).Mod(k, babyjub.SubOrder)
+	m = new(big.Int).Mod(m, babyjub.SubOrder)
+	z.C1 = babyjub.NewPoint().Mul(k, babyjub.B8)
+	s := babyjub.NewPoint().Mul(k, pubKey.Point())
+	z.C2 = addPoints(babyjub.NewPoint().Mul(m, babyjub.B8), s)
+	return z
+}
+
+// EncryptedZeroNative returns the encryption of the zero message with the
+// public key and the random k provided, as EncryptedZero does in the circuit.
+func EncryptedZeroNative(pubKey *babyjub.PublicKey, k *big.Int) *NativeCiphertext {
+	return NewNativeCiphertext().Encrypt(pubKey, k, big.NewInt(0))
+}
+
+// Add sets z to the sum x+y and returns z.
+func (z *NativeCiphertext) Add(x, y *NativeCiphertext) *NativeCiphertext {
+	z.C1 = addPoints(x.C1, y.C1)
+	z.C2 = addPoints(x.C2, y.C2)
+	return z
+}
+
+// Neg sets z to the negation of x and returns z.
+func (z *NativeCiphertext) Neg(x *NativeCiphertext) *NativeCiphertext {
+	z.C1 = negPoint(x.C1)
+	z.C2 = negPoint(x.C2)
+	return z
+}
+
+// Sub sets z to the difference x-y and returns z.
+func (z *NativeCiphertext) Sub(x, y *NativeCiphertext) *NativeCiphertext {
+	z.C1 = addPoints(x.C1, negPoint(y.C1))
+	z.C2 = addPoints(x.C2, negPoint(y.C2))
+	return z
+}
+
+// ScalarMul sets z to [s]x and returns z, taking s as a signed integer in
+// (-p/2, p/2] modulo the scalar field of BN254, as Ciphertext.ScalarMul does
+// in the circuit. So [-s]x is the negation of [s]x, the encryption of -s
+// times the message of x.
+func (z *NativeCiphertext) ScalarMul(x *NativeCiphertext, s *big.Int) *NativeCiphertext {
+	field := ecc.BN254.ScalarField()
+	s = new(big.Int).Mod(s, field)
+	neg := s.Cmp(new(big.Int).Rsh(field, 1)) > 0
+	if neg {
+		s.Sub(field, s)
+	}
+	s.Mod(s, babyjub.SubOrder)
+	z.C1 = babyjub.NewPoint().Mul(s, x.C1)
+	z.C2 = babyjub.NewPoint().Mul(s, x.C2)
+	if neg {
+		z.C1, z.C2 = negPoint(z.C1), negPoint(z.C2)
+	}
+	return z
+}
+
+// Decrypt returns the message encrypted in z with the public key of privKey,
+// that is the discrete log of M = C2 - [privKey] * C1, looking for it in the
+// range of the table provided. It returns an error if the message is out of
+// the range of the table (or z was not encrypted with the public key of
+// privKey).
+func (z *NativeCiphertext) Decrypt(privKey *big.Int, table *DiscreteLogTable) (*big.Int, error) {
+	s := babyjub.NewPoint().Mul(new(big.Int).Mod(privKey, babyjub.SubOrder), z.C1)
+	m, err := table.DiscreteLog(addPoints(z.C2, negPoint(s)))
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetUint64(m), nil
+}
+
+// CiphertextFromNative converts a native ciphertext to a circuit ciphertext,
+// with the points in Reduced TwistedEdwards format.
+func CiphertextFromNative(ct *NativeCiphertext) Ciphertext {
+	return Ciphertext{
+		C1: PointFromNative(ct.C1),
+		C2: PointFromNative(ct.C2),
+	}
+}
+
+// PointFromNative converts a native point in TwistedEdwards format (like a
+// public key) to a circuit point in Reduced TwistedEdwards format.
+func PointFromNative(p *babyjub.Point) twistededwards.Point {
+	x, y := format.FromTEtoRTE(p.X, p.Y)
+	return twistededwards.Point{X: x, Y: y}
+}
+
+// NativeCiphertexts is a vector of ciphertexts computed natively, the
+// off-circuit counterpart of Ciphertexts.
+type NativeCiphertexts []*NativeCiphertext
+
+// NewNativeCiphertexts returns a new vector of n native ciphertexts with both
+// points of every ciphertext set to the identity.
+func NewNativeCiphertexts(n int) NativeCiphertexts {
+	z := make(NativeCiphertexts, n)
+	for i := range z {
+		z[i] = NewNativeCiphertext()
+	}
+	return z
+}
+
+// FieldK returns the random used to encrypt the field i of a vector of
+// ciphertexts with the random k, that is Poseidon(k, i).
+func FieldK(k *big.Int, i int) (*big.Int, error) {
+	return poseidon.Hash([]*big.Int{k, big.NewInt(int64(i))})
+}
+
+// Encrypt sets z to the encryption of the messages provided with the public
+// key and the randoms derived from k (see FieldK), and returns z, as
+// Ciphertexts.Encrypt does in the circuit. It returns an error if the number
+// of messages is not the length of z.
+func (z NativeCiphertexts) Encrypt(pubKey *babyjub.PublicKey, k *big.Int, msgs ...*big.Int) (NativeCiphertexts, error) {
+	if len(msgs) != len(z) {
+		return nil, fmt.Errorf("mismatched number of messages (%d) and ciphertexts (%d)", len(msgs), len(z))
+	}
+	for i, m := range msgs {
+		ki, err := FieldK(k, i)
+		if err != nil {
+			return nil, err
+		}
+		z[i].Encrypt(pubKey, ki, m)
+	}
+	return z, nil
+}
+
+// Add sets z to the sum x+y of every ciphertext and returns z. It returns an
+// error if the lengths of the vectors differ.
+func (z NativeCiphertexts) Add(x, y NativeCiphertexts) (NativeCiphertexts, error) {
+	if len(x) != len(z) || len(y) != len(z) {
+		return nil, fmt.Errorf("mismatched number of ciphertexts: %d, %d and %d", len(z), len(x), len(y))
+	}
+	for i := range z {
+		z[i].Add(x[i], y[i])
+	}
+	return z, nil
+}
+
+// CiphertextsFromNative converts a vector of native ciphertexts to a circuit
+// vector of ciphertexts, with the points in Reduced TwistedEdwards format.
+func CiphertextsFromNative(cts NativeCiphertexts) Ciphertexts {
+	res := make(Ciphertexts, len(cts))
+	for i, ct := range cts {
+		res[i] = CiphertextFromNative(ct)
+	}
+	return res
+}
+
+// NativeDecryptionProof is a Chaum–Pedersen proof of correct decryption
+// computed natively, with the points in TwistedEdwards format. It is the
+// off-circuit counterpart of DecryptionProof.
+type NativeDecryptionProof struct {
+	A1, A2 *babyjub.Point
+	Z      *big.Int
+}
+
+// ProveDecryption returns a proof that the ciphertext decrypts to the message
+// provided with the private key, that is, that D = C2 - [msg] * G and C1
+// share the same discrete log with respect to the public key and G:
+//
+//	A1 = [r] * G, A2 = [r] * C1
+//	e = hFn(PubKey, PubKey, C1, D, A1, A2)
+//	z = r + e * privKey
+//
+// The challenge is computed over the coordinates of the points in Reduced
+// TwistedEdwards format, as DecryptionProof.Verify does in the circuit, so the
+// hash function must be the native counterpart of the circuit one. It returns
+// an error if the ciphertext does not decrypt to the message.
+func ProveDecryption(hFn utils.NativeHasher, privKey *big.Int, ct *NativeCiphertext, msg *big.Int) (*NativeDecryptionProof, error) {
+	privKey = new(big.Int).Mod(privKey, babyjub.SubOrder)
+	d := decryptionShare(ct, msg)
+	if babyjub.NewPoint().Mul(privKey, ct.C1).Compress() != d.Compress() {
+		return nil, fmt.Errorf("the ciphertext does not decrypt to the message")
+	}
+	return proveDLEQ(hFn, privKey, ct.C1, d)
+}
+
+// Verify checks natively that the proof is a valid proof that the ciphertext
+// decrypts to the message with the private key of the public key provided,
+// as DecryptionProof.Verify does in the circuit:
+//
+//	z·G = A1 + e·P
+//	z·C1 = A2 + e·D
+//
+// It returns an error if it is not.
+func (p *NativeDecryptionProof) Verify(hFn utils.NativeHasher, pubKey *babyjub.PublicKey, ct *NativeCiphertext, msg *big.Int) error {
+	return p.verify(hFn, pubKey.Point(), ct.C1, decryptionShare(ct, msg))
+}
+
+// proveDLEQ returns a proof that pubKey = [privKey] * G and d = [privKey] * c1
+// share the same discrete log, with the transcript of DecryptionProof.
+func proveDLEQ(hFn utils.NativeHasher, privKey *big.Int, c1, d *babyjub.Point) (*NativeDecryptionProof, error) {
+	pubKey := babyjub.NewPoint().Mul(privKey, babyjub.B8)
+	r, err := rand.Int(rand.Reader, babyjub.SubOrder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate random nonce: %w", err)
+	}
+	proof := &NativeDecryptionProof{
+		A1: babyjub.NewPoint().Mul(r, babyjub.B8),
+		A2: babyjub.NewPoint().Mul(r, c1),
+	}
+	e, err := hashPointsToScalarNative(hFn, pubKey, pubKey, c1, d, proof.A1, proof.A2)
+	if err != nil {
+		return nil, err
+	}
+	proof.Z = new(big.Int).Mul(e, privKey)
+	proof.Z.Add(proof.Z, r).Mod(proof.Z, babyjub.SubOrder)
+	return proof, nil
+}
+
+// verify checks natively that the proof is a valid proof that pubKey and d
+// share the same discrete log with respect to G and c1.
+func (p *NativeDecryptionProof) verify(hFn utils.NativeHasher, pubKey, c1, d *babyjub.Point) error {
+	for _, point := range []*babyjub.Point{p.A1, p.A2} {
+		if !point.InCurve() {
+			return fmt.Errorf("point (%s, %s) is not on the curve", point.X, point.Y)
+		}
+	}
+	// the scalar multiplications of the circuit require the variable points
+	// in the prime order subgroup
+	for _, point := range []*babyjub.Point{pubKey, c1, d} {
+		if !point.InCurve() || !point.InSubGroup() {
+			return fmt.Errorf("point (%s, %s) is not in the prime order subgroup", point.X, point.Y)
+		}
+	}
+	e, err := hashPointsToScalarNative(hFn, pubKey, pubKey, c1, d, p.A1, p.A2)
+	if err != nil {
+		return err
+	}
+	e.Mod(e, babyjub.SubOrder)
+	z := new(big.Int).Mod(p.Z, babyjub.SubOrder)
+	zG := babyjub.NewPoint().Mul(z, babyjub.B8)
+	if zG.Compress() != addPoints(p.A1, babyjub.NewPoint().Mul(e, pubKey)).Compress() {
+		return fmt.Errorf("invalid decryption proof: z·G != A1 + e·P")
+	}
+	zC1 := babyjub.NewPoint().Mul(z, c1)
+	if zC1.Compress() != addPoints(p.A2, babyjub.NewPoint().Mul(e, d)).Compress() {
+		return fmt.Errorf("invalid decryption proof: z·C1 != A2 + e·D")
+	}
+	return nil
+}
+
+// DecryptionProofFromNative converts a native decryption proof to a circuit
+// proof, with the points in Reduced TwistedEdwards format.
+func DecryptionProofFromNative(p *NativeDecryptionProof) DecryptionProof {
+	return DecryptionProof{
+		A1: PointFromNative(p.A1),
+		A2: PointFromNative(p.A2),
+		Z:  p.Z,
+	}
+}
+
+// Rerandomize returns a new ciphertext that encrypts the same message as z
+// with the public key provided, adding to z the encryption of zero with the
+// random r, as Ciphertext.Rerandomize does in the circuit. z is not
+// modified.
+func (z *NativeCiphertext) Rerandomize(pubKey *babyjub.PublicKey, r *big.Int) *NativeCiphertext {
+	return NewNativeCiphertext().Add(z, EncryptedZeroNative(pubKey, r))
+}
+
+// NativeRerandomizationProof is a proof of re-randomization computed
+// natively, with the points in TwistedEdwards format. It is the off-circuit
+// counterpart of RerandomizationProof.
+type NativeRerandomizationProof NativeDecryptionProof
+
+// ProveRerandomization returns a proof that the ciphertext out is the
+// re-randomization of the ciphertext in with the public key and the random r
+// provided, with the transcript of the decryption proofs. It returns an
+// error if it is not.
+func ProveRerandomization(hFn utils.NativeHasher, pubKey *babyjub.PublicKey, r *big.Int, in, out *NativeCiphertext) (*NativeRerandomizationProof, error) {
+	if in.Rerandomize(pubKey, r).Compress() != out.Compress() {
+		return nil, fmt.Errorf("the ciphertext is not a re-randomization with the random provided")
+	}
+	r = new(big.Int).Mod(r, babyjub.SubOrder)
+	r2 := addPoints(out.C2, negPoint(in.C2))
+	proof, err := proveDLEQ(hFn, r, pubKey.Point(), r2)
+	if err != nil {
+		return nil, err
+	}
+	return (*NativeRerandomizationProof)(proof), nil
+}
+
+// Verify checks natively that the proof is a valid proof that the ciphertext
+// out is a re-randomization of the ciphertext in with the public key
+// provided, as RerandomizationProof.Verify does in the circuit. It returns an
+// error if it is not.
+func (p *NativeRerandomizationProof) Verify(hFn utils.NativeHasher, pubKey *babyjub.PublicKey, in, out *NativeCiphertext) error {
+	r1 := addPoints(out.C1, negPoint(in.C1))
+	r2 := addPoints(out.C2, negPoint(in.C2))
+	return (*NativeDecryptionProof)(p).verify(hFn, r1, pubKey.Point(), r2)
+}
+
+// RerandomizationProofFromNative converts a native re-randomization proof to
+// a circuit proof, with the points in Reduced TwistedEdwards format.
+func RerandomizationProofFromNative(p *NativeRerandomizationProof) RerandomizationProof {
+	return RerandomizationProof(DecryptionProofFromNative((*NativeDecryptionProof)(p)))
+}
+
+// NativeMembershipProof is a disjunctive Chaum–Pedersen proof that a
+// ciphertext encrypts one of a set of values computed natively, with the
+// points in TwistedEdwards format. It is the off-circuit counterpart of
+// MembershipProof.
+type NativeMembershipProof struct {
+	A1, A2 []*babyjub.Point
+	E, Z   []*big.Int
+}
+
+// ProveMembership returns a proof that the ciphertext, the encryption of msg
+// with the public key and the random k provided, encrypts one of the values,
+// as MembershipProof.Verify checks in the circuit. The proof of the value
+// encrypted is computed as a decryption proof with the random as the
+// witness, and the other ones are simulated with random challenges:
+//
+//	A1_i = [z_i] * G - [e_i] * C1, A2_i = [z_i] * P - [e_i] * D_i
+//
+// The challenge of the value encrypted is the Fiat-Shamir challenge minus
+// the other ones in the scalar field of BN254, as the circuit adds them up.
+// It returns an error if msg is not one of the values or the ciphertext is
+// not its encryption with k.
+func ProveMembership(hFn utils.NativeHasher, pubKey *babyjub.PublicKey, k, msg *big.Int, ct *NativeCiphertext, values ...*big.Int) (*NativeMembershipProof, error) {
+	if NewNativeCiphertext().Encrypt(pubKey, k, msg).Compress() != ct.Compress() {
+		return nil, fmt.Errorf("the ciphertext is not the encryption of the message with the random provided")
+	}
+	index := -1
+	for i, v := range values {
+		diff := new(big.Int).Sub(v, msg)
+		if diff.Mod(diff, babyjub.SubOrder).Sign() == 0 {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return nil, fmt.Errorf("the message is not one of the values")
+	}
+	k = new(big.Int).Mod(k, babyjub.SubOrder)
+	p := pubKey.Point()
+	proof := &NativeMembershipProof{
+		A1: make([]*babyjub.Point, len(values)),
+		A2: make([]*babyjub.Point, len(values)),
+		E:  make([]*big.Int, len(values)),
+		Z:  make([]*big.Int, len(values)),
+	}
+	randoms := make([]*big.Int, 2*len(values))
+	for i := range randoms {
+		r, err := rand.Int(rand.Reader, babyjub.SubOrder)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate random nonce: %w", err)
+		}
+		randoms[i] = r
+	}
+	for i, v := range values {
+		if i == index {
+			proof.A1[i] = babyjub.NewPoint().Mul(randoms[2*i], babyjub.B8)
+			proof.A2[i] = babyjub.NewPoint().Mul(randoms[2*i], p)
+		} else {
+			proof.E[i], proof.Z[i] = randoms[2*i], randoms[2*i+1]
+			d := decryptionShare(ct, v)
+			proof.A1[i] = addPoints(babyjub.NewPoint().Mul(proof.Z[i], babyjub.B8), negPoint(babyjub.NewPoint().Mul(proof.E[i], ct.C1)))
+			proof.A2[i] = addPoints(babyjub.NewPoint().Mul(proof.Z[i], p), negPoint(babyjub.NewPoint().Mul(proof.E[i], d)))
+		}
+	}
+	e, err := membershipChallenge(hFn, p, ct, values, proof.A1, proof.A2)
+	if err != nil {
+		return nil, err
+	}
+	field := ecc.BN254.ScalarField()
+	for i := range values {
+		if i != index {
+			e.Sub(e, proof.E[i])
+		}
+	}
+	proof.E[index] = e.Mod(e, field)
+	z := new(big.Int).Mod(proof.E[index], babyjub.SubOrder)
+	z.Mul(z, k).Add(z, randoms[2*index]).Mod(z, babyjub.SubOrder)
+	proof.Z[index] = z
+	return proof, nil
+}
+
+// Verify checks natively that the proof is a valid proof that the ciphertext
+// encrypts one of the values with the public key provided, as
+// MembershipProof.Verify does in the circuit. It returns an error if it is
+// not.
+func (p *NativeMembershipProof) Verify(hFn utils.NativeHasher, pubKey *babyjub.PublicKey, ct *NativeCiphertext, values ...*big.Int) error {
+	n := len(values)
+	if n == 0 || len(p.A1) != n || len(p.A2) != n || len(p.E) != n || len(p.Z) != n {
+		return fmt.Errorf("mismatched number of values (%d) and proofs", n)
+	}
+	// the scalar multiplications of the circuit require the variable points
+	// in the prime order subgroup
+	pk := pubKey.Point()
+	for _, point := range []*babyjub.Point{pk, ct.C1, ct.C2} {
+		if !point.InCurve() || !point.InSubGroup() {
+			return fmt.Errorf("point (%s, %s) is not in the prime order subgroup", point.X, point.Y)
+		}
+	}
+	for i := range values {
+		for _, point := range []*babyjub.Point{p.A1[i], p.A2[i]} {
+			if !point.InCurve() {
+				return fmt.Errorf("point (%s, %s) is not on the curve", point.X, point.Y)
+			}
+		}
+	}
+	e, err := membershipChallenge(hFn, pk, ct, values, p.A1, p.A2)
+	if err != nil {
+		return err
+	}
+	sum := new(big.Int)
+	for i, v := range values {
+		sum.Add(sum, p.E[i])
+		ei := new(big.Int).Mod(p.E[i], babyjub.SubOrder)
+		zi := new(big.Int).Mod(p.Z[i], babyjub.SubOrder)
+		if babyjub.NewPoint().Mul(zi, babyjub.B8).Compress() != addPoints(p.A1[i], babyjub.NewPoint().Mul(ei, ct.C1)).Compress() {
+			return fmt.Errorf("invalid membership proof: z·G != A1 + e·C1 for the value %d", i)
+		}
+		d := decryptionShare(ct, v)
+		if babyjub.NewPoint().Mul(zi, pk).Compress() != addPoints(p.A2[i], babyjub.NewPoint().Mul(ei, d)).Compress() {
+			return fmt.Errorf("invalid membership proof: z·P != A2 + e·D for the value %d", i)
+		}
+	}
+	if sum.Mod(sum, ecc.BN254.ScalarField()).Cmp(e) != 0 {
+		return fmt.Errorf("invalid membership proof: the challenges do not add up")
+	}
+	return nil
+}
+
+// MembershipProofFromNative converts a native membership proof to a circuit
+// proof, with the points in Reduced TwistedEdwards format.
+func MembershipProofFromNative(p *NativeMembershipProof) MembershipProof {
+	res := NewMembershipProof(len(p.A1))
+	for i := range p.A1 {
+		res.A1[i] = PointFromNative(p.A1[i])
+		res.A2[i] = PointFromNative(p.A2[i])
+		res.E[i] = p.E[i]
+		res.Z[i] = p.Z[i]
+	}
+	return res
+}
+
+// NativeRangeProof is a proof that a ciphertext encrypts a value in
+// [0, max] computed natively, with the points in TwistedEdwards format. It is
+// the off-circuit counterpart of RangeProof.
+type NativeRangeProof struct {
+	Bits   []*NativeCiphertext
+	Proofs []*NativeMembershipProof
+}
+
+// ProveRange returns a proof that the encryption of msg with the public key
+// and the random k provided encrypts a value in [0, max], as
+// RangeProof.Verify checks in the circuit. The randoms of the bits are
+// chosen so that their weighted sum is k. It returns an error if max is zero
+// or msg is out of the range.
+func ProveRange(hFn utils.NativeHasher, pubKey *babyjub.PublicKey, k, msg *big.Int, max uint64) (*NativeRangeProof, error) {
+	weights := rangeWeights(max)
+	if len(weights) == 0 {
+		return nil, fmt.Errorf("empty range")
+	}
+	if msg.Sign() < 0 || !msg.IsUint64() || msg.Uint64() > max {
+		return nil, fmt.Errorf("the message is out of the range [0, %d]", max)
+	}
+	// the last weight is used for the values over the sum of the other ones,
+	// 2^(n-1) - 1, and the remainder is decomposed in bits
+	n := len(weights)
+	m := msg.Uint64()
+	bits := make([]uint64, n)
+	if m >= uint64(1)<<(n-1) {
+		bits[n-1] = 1
+		m -= weights[n-1]
+	}
+	for i := range n - 1 {
+		bits[i] = (m >> i) & 1
+	}
+	// k = sum(w_i * k_i), so the last random is (k - sum(w_i * k_i)) / w_last
+	randoms := make([]*big.Int, n)
+	last := new(big.Int).Mod(k, babyjub.SubOrder)
+	for i := range n - 1 {
+		r, err := RandomK()
+		if err != nil {
+			return nil, err
+		}
+		randoms[i] = r
+		last.Sub(last, new(big.Int).Mul(r, new(big.Int).SetUint64(weights[i])))
+	}
+	inv := new(big.Int).ModInverse(new(big.Int).SetUint64(weights[n-1]), babyjub.SubOrder)
+	randoms[n-1] = last.Mul(last, inv).Mod(last, babyjub.SubOrder)
+
+	proof := &NativeRangeProof{
+		Bits:   make([]*NativeCiphertext, n),
+		Proofs: make([]*NativeMembershipProof, n),
+	}
+	for i, b := range bits {
+		bit := new(big.Int).SetUint64(b)
+		proof.Bits[i] = NewNativeCiphertext().Encrypt(pubKey, randoms[i], bit)
+		p, err := ProveMembership(hFn, pubKey, randoms[i], bit, proof.Bits[i], big.NewInt(0), big.NewInt(1))
+		if err != nil {
+			return nil, err
+		}
+		proof.Proofs[i] = p
+	}
+	return proof, nil
+}
+
+// Verify checks natively that the proof is a valid proof that the ciphertext
+// encrypts a value in [0, max] with the public key provided, as
+// RangeProof.Verify does in the circuit. It returns an error if it is not.
+func (p *NativeRangeProof) Verify(hFn utils.NativeHasher, pubKey *babyjub.PublicKey, ct *NativeCiphertext, max uint64) error {
+	weights := rangeWeights(max)
+	if len(weights) == 0 {
+		return fmt.Errorf("empty range")
+	}
+	if len(p.Bits) != len(weights) || len(p.Proofs) != len(weights) {
+		return fmt.Errorf("mismatched number of bits (%d) and proofs (%d), expected %d",
+			len(p.Bits), len(p.Proofs), len(weights))
+	}
+	sum := NewNativeCiphertext()
+	for i, w := range weights {
+		if err := p.Proofs[i].Verify(hFn, pubKey, p.Bits[i], big.NewInt(0), big.NewInt(1)); err != nil {
+			return fmt.Errorf("bit %d: %w", i, err)
+		}
+		sum.Add(sum, NewNativeCiphertext().ScalarMul(p.Bits[i], new(big.Int).SetUint64(w)))
+	}
+	if sum.Compress() != ct.Compress() {
+		return fmt.Errorf("invalid range proof: the ciphertext is not the weighted sum of the bits")
+	}
+	return nil
+}
+
+// RangeProofFromNative converts a native range proof to a circuit proof,
+// with the points in Reduced TwistedEdwards format.
+func RangeProofFromNative(p *NativeRangeProof) RangeProof {
+	res := RangeProof{
+		Bits:   make([]Ciphertext, len(p.Bits)),
+		Proofs: make([]MembershipProof, len(p.Proofs)),
+	}
+	for i := range p.Bits {
+		res.Bits[i] = CiphertextFromNative(p.Bits[i])
+		res.Proofs[i] = MembershipProofFromNative(p.Proofs[i])
+	}
+	return res
+}
+
+// Compress returns the compressed points of the ciphertext, C1 and C2, to
+// compare or index ciphertexts.
+func (z *NativeCiphertext) Compress() [64]byte {
+	var res [64]byte
+	c1, c2 := z.C1.Compress(), z.C2.Compress()
+	copy(res[:32], c1[:])
+	copy(res[32:], c2[:])
+	return res
+}
+
+// decryptionShare returns D = C2 - [msg] * G, which is [privKey] * C1 if the
+// ciphertext decrypts to the message.
+func decryptionShare(ct *NativeCiphertext, msg *big.Int) *babyjub.Point {
+	m := babyjub.NewPoint().Mul(new(big.Int).Mod(msg, babyjub.SubOrder), babyjub.B8)
+	return addPoints(ct.C2, negPoint(m))
+}
+
+// hashPointsToScalarNative returns the hash of the coordinates of the points
+// in Reduced TwistedEdwards format, as hashPointsToScalar does in the
+// circuit.
+func hashPointsToScalarNative(hFn utils.NativeHasher, points ...*babyjub.Point) (*big.Int, error) {
+	coords := make([]*big.Int, 0, 2*len(points))
+	for _, p := range points {
+		x, y := format.FromTEtoRTE(p.X, p.Y)
+		coords = append(coords, x, y)
+	}
+	return hFn(coords)
+}
+
+// membershipChallenge returns the Fiat-Shamir challenge of a membership
+// proof, as MembershipProof.Verify computes it in the circuit: the hash of
+// the public key, the ciphertext, the values reduced modulo the scalar field
+// of BN254 and the commitments A1_i, A2_i, with the points in Reduced
+// TwistedEdwards format.
+func membershipChallenge(hFn utils.NativeHasher, pubKey *babyjub.Point, ct *NativeCiphertext, values []*big.Int, a1, a2 []*babyjub.Point) (*big.Int, error) {
+	coords := make([]*big.Int, 0, 6+5*len(values))
+	appendPoints := func(points ...*babyjub.Point) {
+		for _, p := range points {
+			x, y := format.FromTEtoRTE(p.X, p.Y)
+			coords = append(coords, x, y)
+		}
+	}
+	appendPoints(pubKey, ct.C1, ct.C2)
+	for _, v := range values {
+		coords = append(coords, new(big.Int).Mod(v, ecc.BN254.ScalarField()))
+	}
+	for i := range values {
+		appendPoints(a1[i], a2[i])
+	}
+	return hFn(coords)
+}
+
+// DiscreteLogTable is a precomputed table to find the discrete log m of the
+// points [m] * B8, for m in [0, MaxMessage], using the baby-step giant-step
+// algorithm. It needs ~sqrt(MaxMessage) points of memory and additions per
+// discrete log, so the same table should be reused to decrypt several
+// ciphertexts, like the results of a tally.
+type DiscreteLogTable struct {
+	MaxMessage uint64
+	// step is the number of entries of the baby steps
+	step uint64
+	// babySteps maps the compressed points [j] * B8 to j, for j < step
+	babySteps map[[32]byte]uint64
+	// giantStep is [-step] * B8
+	giantStep *babyjub.Point
+}
+
+// NewDiscreteLogTable returns a new DiscreteLogTable for the messages in
+// [0, maxMessage].
+func NewDiscreteLogTable(maxMessage uint64) *DiscreteLogTable {
+	// step = ceil(sqrt(maxMessage + 1)), so step^2 > maxMessage
+	sqrt := new(big.Int).Sqrt(new(big.Int).SetUint64(maxMessage))
+	step := sqrt.Uint64() + 1
+	t := &DiscreteLogTable{
+		MaxMessage: maxMessage,
+		step:       step,
+		babySteps:  make(map[[32]byte]uint64, step),
+	}
+	p := babyjub.NewPoint()
+	for j := range step {
+		t.babySteps[p.Compress()] = j
+		p = addPoints(p, babyjub.B8)
+	}
+	// p is [step] * B8 after the last iteration
+	t.giantStep = negPoint(p)
+	return t
+}
+
+// DiscreteLog returns m such that p = [m] * B8, if m is in the range of the
+// table, or an error otherwise.
+func (t *DiscreteLogTable) DiscreteLog(p *babyjub.Point) (uint64, error) {
+	// p - [i * step] * B8 = [j] * B8 for m = i * step + j
+	for i := uint64(0); i <= t.MaxMessage/t.step; i++ {
+		if j, ok := t.babySteps[p.Compress()]; ok {
+			if m := i*t.step + j; m <= t.MaxMessage {
+				return m, nil
+			}
+			break
+		}
+		p = addPoints(p, t.giantStep)
+	}
+	return 0, fmt.Errorf("message not found in [0, %d]", t.MaxMessage)
+}
+
+// addPoints returns a + b.
+func addPoints(a, b *babyjub.Point) *babyjub.Point {
+	return babyjub.NewPointProjective().Add(a.Projective(), b.Projective()).Affine()
+}
+
+// negPoint returns -p, that is (-x, y) in TwistedEdwards format.
+func negPoint(p *babyjub.Point) *babyjub.Point {
+	x := new(big.Int).Neg(p.X)
+	x.Mod(x, ecc.BN254.ScalarField())
+	return &babyjub.Point{X: x, Y: new(big.Int).Set(p.Y)}
+}
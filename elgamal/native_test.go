@@ -0,0 +1,172 @@
+package elgamal
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/native/twistededwards"
+	"github.com/consensys/gnark/test"
+	qt "github.com/frankban/quicktest"
+	iden3poseidon "github.com/iden3/go-iden3-crypto/poseidon"
+)
+
+type testNativeCompatibilityCircuit struct {
+	PubKey twistededwards.Point `gnark:",public"`
+	K1, K2 frontend.Variable
+	M1, M2 frontend.Variable
+	// natively computed ciphertexts
+	A, B, Sum, Neg, Zero Ciphertext
+}
+
+func (c *testNativeCompatibilityCircuit) Define(api frontend.API) error {
+	a, err := new(Ciphertext).Encrypt(api, c.PubKey, c.K1, c.M1)
+	if err != nil {
+		return err
+	}
+	a.AssertIsEqual(api, &c.A)
+	b, err := new(Ciphertext).Encrypt(api, c.PubKey, c.K2, c.M2)
+	if err != nil {
+		return err
+	}
+	b.AssertIsEqual(api, &c.B)
+	new(Ciphertext).Add(api, a, b).AssertIsEqual(api, &c.Sum)
+	new(Ciphertext).Neg(api, a).AssertIsEqual(api, &c.Neg)
+	zero := EncryptedZero(api, c.PubKey, c.K1)
+	zero.AssertIsEqual(api, &c.Zero)
+	return nil
+}
+
+func TestNativeCompatibility(t *testing.T) {
+	c := qt.New(t)
+	_, pubKey, err := GenerateKey()
+	c.Assert(err, qt.IsNil)
+	k1, err := RandomK()
+	c.Assert(err, qt.IsNil)
+	k2, err := RandomK()
+	c.Assert(err, qt.IsNil)
+	m1, m2 := big.NewInt(7), big.NewInt(1000)
+
+	a := NewNativeCiphertext().Encrypt(pubKey, k1, m1)
+	b := NewNativeCiphertext().Encrypt(pubKey, k2, m2)
+	witness := &testNativeCompatibilityCircuit{
+		PubKey: PointFromNative(pubKey.Point()),
+		K1:     k1,
+		K2:     k2,
+		M1:     m1,
+		M2:     m2,
+		A:      CiphertextFromNative(a),
+		B:      CiphertextFromNative(b),
+		Sum:    CiphertextFromNative(NewNativeCiphertext().Add(a, b)),
+		Neg:    CiphertextFromNative(NewNativeCiphertext().Neg(a)),
+		Zero:   CiphertextFromNative(EncryptedZeroNative(pubKey, k1)),
+	}
+	// the ciphertext of another message must fail
+	invalid := *witness
+	invalid.Sum = CiphertextFromNative(NewNativeCiphertext().Add(a, a))
+
+	assert := test.NewAssert(t)
+	assert.CheckCircuit(&testNativeCompatibilityCircuit{},
+		test.WithValidAssignment(witness),
+		test.WithInvalidAssignment(&invalid),
+		test.WithCurves(ecc.BN254),
+		test.WithBackends(backend.GROTH16),
+	)
+}
+
+func TestNativeDecrypt(t *testing.T) {
+	c := qt.New(t)
+	privKey, pubKey, err := GenerateKey()
+	c.Assert(err, qt.IsNil)
+
+	encrypt := func(m int64) *NativeCiphertext {
+		k, err := RandomK()
+		c.Assert(err, qt.IsNil)
+		return NewNativeCiphertext().Encrypt(pubKey, k, big.NewInt(m))
+	}
+
+	c.Run("range", func(c *qt.C) {
+		// the bounds of the range, with a perfect square and the previous
+		// and next values as maximum
+		for _, maxMessage := range []uint64{0, 1, 99, 100, 101, 1 << 16} {
+			table := NewDiscreteLogTable(maxMessage)
+			for _, m := range []uint64{0, maxMessage / 2, maxMessage} {
+				res, err := encrypt(int64(m)).Decrypt(privKey, table)
+				c.Assert(err, qt.IsNil)
+				c.Assert(res.Uint64(), qt.Equals, m)
+			}
+			_, err := encrypt(int64(maxMessage)+1).Decrypt(privKey, table)
+			c.Assert(err, qt.IsNotNil)
+		}
+	})
+
+	c.Run("tally", func(c *qt.C) {
+		// the sum of the ciphertexts decrypts to the sum of the messages
+		table := NewDiscreteLogTable(1 << 20)
+		sum := NewNativeCiphertext()
+		total := int64(0)
+		for _, m := range []int64{5, 0, 1000, 123456, 1} {
+			sum.Add(sum, encrypt(m))
+			total += m
+		}
+		res, err := sum.Decrypt(privKey, table)
+		c.Assert(err, qt.IsNil)
+		c.Assert(res.Int64(), qt.Equals, total)
+		// the negation cancels the sum
+		zero := NewNativeCiphertext().Add(sum, NewNativeCiphertext().Neg(sum))
+		res, err = zero.Decrypt(privKey, table)
+		c.Assert(err, qt.IsNil)
+		c.Assert(res.Int64(), qt.Equals, int64(0))
+	})
+
+	c.Run("wrong key", func(c *qt.C) {
+		otherKey, _, err := GenerateKey()
+		c.Assert(err, qt.IsNil)
+		_, err = encrypt(3).Decrypt(otherKey, NewDiscreteLogTable(1000))
+		c.Assert(err, qt.IsNotNil)
+	})
+}
+
+func TestNativeDecryptionProof(t *testing.T) {
+	c := qt.New(t)
+	privKey, pubKey, err := GenerateKey()
+	c.Assert(err, qt.IsNil)
+	k, err := RandomK()
+	c.Assert(err, qt.IsNil)
+	msg := big.NewInt(42)
+	ct := NewNativeCiphertext().Encrypt(pubKey, k, msg)
+
+	proof, err := ProveDecryption(iden3poseidon.Hash, privKey, ct, msg)
+	c.Assert(err, qt.IsNil)
+	c.Assert(proof.Verify(iden3poseidon.Hash, pubKey, ct, msg), qt.IsNil)
+	// another message or a tampered response must fail natively
+	c.Assert(proof.Verify(iden3poseidon.Hash, pubKey, ct, big.NewInt(43)), qt.IsNotNil)
+	tampered := *proof
+	tampered.Z = new(big.Int).Add(proof.Z, big.NewInt(1))
+	c.Assert(tampered.Verify(iden3poseidon.Hash, pubKey, ct, msg), qt.IsNotNil)
+	// the prover refuses to prove a wrong message
+	_, err = ProveDecryption(iden3poseidon.Hash, privKey, ct, big.NewInt(43))
+	c.Assert(err, qt.IsNotNil)
+
+	witness := &testVerifyDecryptionProofCircuit{
+		PubKey:     PointFromNative(pubKey.Point()),
+		Ciphertext: CiphertextFromNative(ct),
+		Proof:      DecryptionProofFromNative(proof),
+		Msg:        msg,
+	}
+	invalidMsg := *witness
+	invalidMsg.Msg = big.NewInt(43)
+	invalidZ := *witness
+	invalidZ.Proof.Z = tampered.Z
+
+	assert := test.NewAssert(t)
+	assert.CheckCircuit(&testVerifyDecryptionProofCircuit{},
+		test.WithValidAssignment(witness),
+		test.WithInvalidAssignment(&invalidMsg),
+		test.WithInvalidAssignment(&invalidZ),
+		test.WithCurves(ecc.BN254),
+		test.WithBackends(backend.GROTH16),
+	)
+}
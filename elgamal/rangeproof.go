@@ -0,0 +1,185 @@
+package elgamal
+
+import (
+	"fmt"
+
+	ecc_tweds "github.com/consensys/gnark-crypto/ecc/twistededwards"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/native/twistededwards"
+	"github.com/vocdoni/gnark-crypto-primitives/ecc/msm"
+	"github.com/vocdoni/gnark-crypto-primitives/utils"
+)
+
+// MembershipProof is a disjunctive Chaum–Pedersen proof (Cramer–Damgård–
+// Schoenmakers OR-proof) that a ciphertext encrypts one of a set of values,
+// without revealing which one. For every value v_i of the set, it contains a
+// Chaum–Pedersen proof that C1 and D_i = C2 - [v_i]G share the same discrete
+// log with respect to G and the public key, where the proofs of the values
+// that are not encrypted are simulated and the challenges E_i must add up to
+// the Fiat-Shamir challenge:
+//
+//	e = hFn(P, C1, C2, v_0, ..., v_n, A1_0, A2_0, ..., A1_n, A2_n)
+//
+// The values are part of the transcript, so the proof is bound to the set
+// even if the values are witnesses of the circuit.
+type MembershipProof struct {
+	A1, A2 []twistededwards.Point
+	E, Z   []frontend.Variable
+}
+
+// NewMembershipProof returns a new MembershipProof with the sizes of a proof
+// for a set of n values, to be used in the definition of the circuits.
+func NewMembershipProof(n int) MembershipProof {
+	return MembershipProof{
+		A1: make([]twistededwards.Point, n),
+		A2: make([]twistededwards.Point, n),
+		E:  make([]frontend.Variable, n),
+		Z:  make([]frontend.Variable, n),
+	}
+}
+
+// Verify checks that the proof is a valid proof that the ciphertext encrypts
+// one of the values provided with the public key, that is, for every value:
+//
+//	z_i·G = A1_i + e_i·C1
+//	z_i·P = A2_i + e_i·D_i
+//
+// and e_0 + ... + e_n = e. The points of the ciphertext must be in the prime
+// order subgroup. It returns an error if the sizes of the proof and the
+// values do not match or the curve initialization fails.
+func (p *MembershipProof) Verify(
+	api frontend.API,
+	hFn utils.Hasher,
+	pubKey twistededwards.Point,
+	ciphertext Ciphertext,
+	values ...frontend.Variable,
+) error {
+	n := len(values)
+	if n == 0 {
+		return fmt.Errorf("no values provided")
+	}
+	if len(p.A1) != n || len(p.A2) != n || len(p.E) != n || len(p.Z) != n {
+		return fmt.Errorf("mismatched number of values (%d) and proofs (%d, %d, %d, %d)",
+			n, len(p.A1), len(p.A2), len(p.E), len(p.Z))
+	}
+	curve, err := twistededwards.NewEdCurve(api, ecc_tweds.BN254)
+	if err != nil {
+		return err
+	}
+	curve.AssertIsOnCurve(pubKey)
+	curve.AssertIsOnCurve(ciphertext.C1)
+	curve.AssertIsOnCurve(ciphertext.C2)
+	// E (Fiat-Shamir challenge) = hFn(PubKey, C1, C2, v_0, ..., A1_0, A2_0, ...)
+	transcript := []frontend.Variable{
+		pubKey.X, pubKey.Y,
+		ciphertext.C1.X, ciphertext.C1.Y,
+		ciphertext.C2.X, ciphertext.C2.Y,
+	}
+	transcript = append(transcript, values...)
+	for i := range values {
+		curve.AssertIsOnCurve(p.A1[i])
+		curve.AssertIsOnCurve(p.A2[i])
+		transcript = append(transcript, p.A1[i].X, p.A1[i].Y, p.A2[i].X, p.A2[i].Y)
+	}
+	E, err := hFn(api, transcript...)
+	if err != nil {
+		return err
+	}
+
+	base := curve.Params().Base
+	G := twistededwards.Point{X: base[0], Y: base[1]}
+	sum := frontend.Variable(0)
+	for i, v := range values {
+		sum = api.Add(sum, p.E[i])
+		// z_i·G - e_i·C1 == A1_i
+		zGMinusEC1, err := msm.MultiScalarMul(curve,
+			[]twistededwards.Point{G, curve.Neg(ciphertext.C1)}, []frontend.Variable{p.Z[i], p.E[i]})
+		if err != nil {
+			return err
+		}
+		api.AssertIsEqual(zGMinusEC1.X, p.A1[i].X)
+		api.AssertIsEqual(zGMinusEC1.Y, p.A1[i].Y)
+		// D_i = C2 - [v_i] * G
+		D := curve.Add(ciphertext.C2, curve.Neg(FixedBaseScalarMulBN254(api, v)))
+		// z_i·P - e_i·D_i == A2_i
+		zPMinusED, err := msm.MultiScalarMul(curve,
+			[]twistededwards.Point{pubKey, curve.Neg(D)}, []frontend.Variable{p.Z[i], p.E[i]})
+		if err != nil {
+			return err
+		}
+		api.AssertIsEqual(zPMinusED.X, p.A2[i].X)
+		api.AssertIsEqual(zPMinusED.Y, p.A2[i].Y)
+	}
+	api.AssertIsEqual(sum, E)
+	return nil
+}
+
+// RangeProof is a proof that a ciphertext encrypts a value in [0, max]
+// without revealing it. The value is decomposed in bits with the weights
+// 1, 2, ..., 2^(n-2) and max - 2^(n-1) + 1, where n is the bit length of max,
+// so every value in the range (and only them) is a weighted sum of the bits.
+// The proof contains the encryption of every bit with a MembershipProof of
+// {0, 1}, and the ciphertext must be the weighted sum of them.
+type RangeProof struct {
+	Bits   []Ciphertext
+	Proofs []MembershipProof
+}
+
+// NewRangeProof returns a new RangeProof with the sizes of a proof for the
+// range [0, max], to be used in the definition of the circuits.
+func NewRangeProof(max uint64) RangeProof {
+	n := len(rangeWeights(max))
+	p := RangeProof{
+		Bits:   make([]Ciphertext, n),
+		Proofs: make([]MembershipProof, n),
+	}
+	for i := range p.Proofs {
+		p.Proofs[i] = NewMembershipProof(2)
+	}
+	return p
+}
+
+// Verify checks that the proof is a valid proof that the ciphertext encrypts
+// a value in [0, max] with the public key. It returns an error if max is zero,
+// the sizes of the proof do not match the range or the curve initialization
+// fails.
+func (p *RangeProof) Verify(
+	api frontend.API,
+	hFn utils.Hasher,
+	pubKey twistededwards.Point,
+	ciphertext Ciphertext,
+	max uint64,
+) error {
+	weights := rangeWeights(max)
+	if len(weights) == 0 {
+		return fmt.Errorf("empty range")
+	}
+	if len(p.Bits) != len(weights) || len(p.Proofs) != len(weights) {
+		return fmt.Errorf("mismatched number of bits (%d) and proofs (%d), expected %d",
+			len(p.Bits), len(p.Proofs), len(weights))
+	}
+	sum := NewCiphertext()
+	for i, w := range weights {
+		if err := p.Proofs[i].Verify(api, hFn, pubKey, p.Bits[i], 0, 1); err != nil {
+			return err
+		}
+		sum.Add(api, sum, new(Ciphertext).ScalarMul(api, &p.Bits[i], w))
+	}
+	sum.AssertIsEqual(api, &ciphertext)
+	return nil
+}
+
+// rangeWeights returns the weights of the bits of the values in [0, max]:
+// 1, 2, ..., 2^(n-2) and max - 2^(n-1) + 1, where n is the bit length of
+// max. It returns no weights if max is zero.
+func rangeWeights(max uint64) []uint64 {
+	var weights []uint64
+	for w := uint64(1); w <= max/2; w <<= 1 {
+		weights = append(weights, w)
+	}
+	if max > 0 {
+		// the sum of the previous weights is 2^(n-1) - 1
+		weights = append(weights, max-(uint64(1)<<len(weights))+1)
+	}
+	return weights
+}
@@ -0,0 +1,228 @@
+package elgamal
+
+import (
+	"fmt"
+	"maps"
+	"math/big"
+	"slices"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/std/algebra/native/twistededwards"
+	"github.com/consensys/gnark/test"
+	qt "github.com/frankban/quicktest"
+	iden3poseidon "github.com/iden3/go-iden3-crypto/poseidon"
+	"github.com/vocdoni/davinci-node/crypto/hash/poseidon"
+)
+
+// nativeMultiHash is the native counterpart of HashFn for any number of
+// inputs, since the transcripts of the membership proofs of more than two
+// values have more than 16 coordinates.
+func nativeMultiHash(inputs []*big.Int) (*big.Int, error) {
+	return poseidon.MultiPoseidon(inputs...)
+}
+
+// testMembershipValues are the values of the set of the membership test
+// circuit
+var testMembershipValues = []int{0, 1, 5}
+
+type testMembershipProofCircuit struct {
+	PubKey     twistededwards.Point `gnark:",public"`
+	Ciphertext Ciphertext           `gnark:",public"`
+	Proof      MembershipProof
+}
+
+func (c *testMembershipProofCircuit) Define(api frontend.API) error {
+	values := make([]frontend.Variable, len(testMembershipValues))
+	for i, v := range testMembershipValues {
+		values[i] = v
+	}
+	return c.Proof.Verify(api, HashFn, c.PubKey, c.Ciphertext, values...)
+}
+
+func TestMembershipProof(t *testing.T) {
+	c := qt.New(t)
+	_, pubKey, err := GenerateKey()
+	c.Assert(err, qt.IsNil)
+	values := make([]*big.Int, len(testMembershipValues))
+	for i, v := range testMembershipValues {
+		values[i] = big.NewInt(int64(v))
+	}
+	assert := test.NewAssert(t)
+	for _, msg := range values {
+		k, err := RandomK()
+		c.Assert(err, qt.IsNil)
+		ct := NewNativeCiphertext().Encrypt(pubKey, k, msg)
+		proof, err := ProveMembership(nativeMultiHash, pubKey, k, msg, ct, values...)
+		c.Assert(err, qt.IsNil)
+		c.Assert(proof.Verify(nativeMultiHash, pubKey, ct, values...), qt.IsNil)
+		// the proof is not valid for another set or another ciphertext
+		c.Assert(proof.Verify(nativeMultiHash, pubKey, ct, big.NewInt(0), big.NewInt(1), big.NewInt(6)), qt.IsNotNil)
+		other := NewNativeCiphertext().Encrypt(pubKey, k, big.NewInt(2))
+		c.Assert(proof.Verify(nativeMultiHash, pubKey, other, values...), qt.IsNotNil)
+
+		witness := &testMembershipProofCircuit{
+			PubKey:     PointFromNative(pubKey.Point()),
+			Ciphertext: CiphertextFromNative(ct),
+			Proof:      MembershipProofFromNative(proof),
+		}
+		invalid := *witness
+		invalid.Ciphertext = CiphertextFromNative(other)
+		assert.CheckCircuit(&testMembershipProofCircuit{Proof: NewMembershipProof(len(values))},
+			test.WithValidAssignment(witness),
+			test.WithInvalidAssignment(&invalid),
+			test.WithCurves(ecc.BN254),
+			test.WithBackends(backend.GROTH16),
+		)
+	}
+	// a message out of the set can not be proven
+	k, err := RandomK()
+	c.Assert(err, qt.IsNil)
+	msg := big.NewInt(2)
+	ct := NewNativeCiphertext().Encrypt(pubKey, k, msg)
+	_, err = ProveMembership(nativeMultiHash, pubKey, k, msg, ct, values...)
+	c.Assert(err, qt.IsNotNil)
+}
+
+type testMembershipWitnessValuesCircuit struct {
+	PubKey     twistededwards.Point `gnark:",public"`
+	Ciphertext Ciphertext           `gnark:",public"`
+	Values     []frontend.Variable
+	Proof      MembershipProof
+}
+
+func (c *testMembershipWitnessValuesCircuit) Define(api frontend.API) error {
+	return c.Proof.Verify(api, HashFn, c.PubKey, c.Ciphertext, c.Values...)
+}
+
+func TestMembershipProofWitnessValues(t *testing.T) {
+	c := qt.New(t)
+	_, pubKey, err := GenerateKey()
+	c.Assert(err, qt.IsNil)
+	values := []*big.Int{big.NewInt(0), big.NewInt(1), big.NewInt(5)}
+	k, err := RandomK()
+	c.Assert(err, qt.IsNil)
+	msg := values[1]
+	ct := NewNativeCiphertext().Encrypt(pubKey, k, msg)
+	proof, err := ProveMembership(nativeMultiHash, pubKey, k, msg, ct, values...)
+	c.Assert(err, qt.IsNil)
+	// the proof is not valid for a set that differs only in a value, which
+	// is a witness in the circuit
+	others := []*big.Int{big.NewInt(0), big.NewInt(2), big.NewInt(5)}
+	c.Assert(proof.Verify(nativeMultiHash, pubKey, ct, others...), qt.IsNotNil)
+
+	toVariables := func(values []*big.Int) []frontend.Variable {
+		res := make([]frontend.Variable, len(values))
+		for i, v := range values {
+			res[i] = v
+		}
+		return res
+	}
+	witness := &testMembershipWitnessValuesCircuit{
+		PubKey:     PointFromNative(pubKey.Point()),
+		Ciphertext: CiphertextFromNative(ct),
+		Values:     toVariables(values),
+		Proof:      MembershipProofFromNative(proof),
+	}
+	invalid := *witness
+	invalid.Values = toVariables(others)
+	assert := test.NewAssert(t)
+	assert.CheckCircuit(&testMembershipWitnessValuesCircuit{
+		Values: make([]frontend.Variable, len(values)),
+		Proof:  NewMembershipProof(len(values)),
+	},
+		test.WithValidAssignment(witness),
+		test.WithInvalidAssignment(&invalid),
+		test.WithCurves(ecc.BN254),
+		test.WithBackends(backend.GROTH16),
+	)
+}
+
+// testRangeMax is the max of the range of the test circuit
+const testRangeMax = 10
+
+type testRangeProofCircuit struct {
+	PubKey     twistededwards.Point `gnark:",public"`
+	Ciphertext Ciphertext           `gnark:",public"`
+	Proof      RangeProof
+	Max        uint64 `gnark:"-"`
+}
+
+func (c *testRangeProofCircuit) Define(api frontend.API) error {
+	return c.Proof.Verify(api, HashFn, c.PubKey, c.Ciphertext, c.Max)
+}
+
+func TestRangeProof(t *testing.T) {
+	c := qt.New(t)
+	// every value in the range is a weighted sum of the bits
+	for _, max := range []uint64{1, 2, 3, 4, 7, 10, 1000} {
+		weights := rangeWeights(max)
+		reachable := map[uint64]bool{0: true}
+		for _, w := range weights {
+			for _, v := range slices.Collect(maps.Keys(reachable)) {
+				reachable[v+w] = true
+			}
+		}
+		c.Assert(len(reachable), qt.Equals, int(max+1), qt.Commentf("max %d", max))
+		for v := range reachable {
+			c.Assert(v <= max, qt.IsTrue)
+		}
+	}
+	c.Assert(rangeWeights(0), qt.HasLen, 0)
+	c.Assert(rangeWeights(1<<64-1), qt.HasLen, 64)
+
+	_, pubKey, err := GenerateKey()
+	c.Assert(err, qt.IsNil)
+	for _, msg := range []int64{0, 7, testRangeMax} {
+		k, err := RandomK()
+		c.Assert(err, qt.IsNil)
+		ct := NewNativeCiphertext().Encrypt(pubKey, k, big.NewInt(msg))
+		proof, err := ProveRange(iden3poseidon.Hash, pubKey, k, big.NewInt(msg), testRangeMax)
+		c.Assert(err, qt.IsNil)
+		c.Assert(proof.Verify(iden3poseidon.Hash, pubKey, ct, testRangeMax), qt.IsNil)
+		c.Assert(proof.Verify(iden3poseidon.Hash, pubKey, ct, testRangeMax+1), qt.IsNotNil)
+		other := NewNativeCiphertext().Encrypt(pubKey, k, big.NewInt(msg+1))
+		c.Assert(proof.Verify(iden3poseidon.Hash, pubKey, other, testRangeMax), qt.IsNotNil)
+
+		witness := &testRangeProofCircuit{
+			PubKey:     PointFromNative(pubKey.Point()),
+			Ciphertext: CiphertextFromNative(ct),
+			Proof:      RangeProofFromNative(proof),
+		}
+		invalid := *witness
+		invalid.Ciphertext = CiphertextFromNative(other)
+		assert := test.NewAssert(t)
+		assert.CheckCircuit(&testRangeProofCircuit{Proof: NewRangeProof(testRangeMax), Max: testRangeMax},
+			test.WithValidAssignment(witness),
+			test.WithInvalidAssignment(&invalid),
+			test.WithCurves(ecc.BN254),
+			test.WithBackends(backend.GROTH16),
+		)
+	}
+	// the messages out of the range can not be proven
+	k, err := RandomK()
+	c.Assert(err, qt.IsNil)
+	_, err = ProveRange(iden3poseidon.Hash, pubKey, k, big.NewInt(testRangeMax+1), testRangeMax)
+	c.Assert(err, qt.IsNotNil)
+	_, err = ProveRange(iden3poseidon.Hash, pubKey, k, big.NewInt(-1), testRangeMax)
+	c.Assert(err, qt.IsNotNil)
+	_, err = ProveRange(iden3poseidon.Hash, pubKey, k, big.NewInt(0), 0)
+	c.Assert(err, qt.IsNotNil)
+}
+
+func TestRangeProofConstraints(t *testing.T) {
+	c := qt.New(t)
+	cs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder,
+		&testMembershipProofCircuit{Proof: NewMembershipProof(len(testMembershipValues))})
+	c.Assert(err, qt.IsNil)
+	fmt.Println("membership of", len(testMembershipValues), "values constrains", cs.GetNbConstraints())
+	for _, max := range []uint64{1, testRangeMax, 1 << 16} {
+		cs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder,
+			&testRangeProofCircuit{Proof: NewRangeProof(max), Max: max})
+		c.Assert(err, qt.IsNil)
+		fmt.Println("range [0,", max, "] constrains", cs.GetNbConstraints())
+	}
+}
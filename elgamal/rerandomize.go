@@ -0,0 +1,63 @@
+package elgamal
+
+import (
+	ecc_tweds "github.com/consensys/gnark-crypto/ecc/twistededwards"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/native/twistededwards"
+	"github.com/vocdoni/gnark-crypto-primitives/utils"
+)
+
+// Rerandomize returns a new ciphertext that encrypts the same message as z
+// with the public key provided, adding to z the encryption of zero with the
+// random r:
+//
+//	C1' = C1 + [r] * G
+//	C2' = C2 + [r] * pubKey
+//
+// z is not modified. It returns an error if the curve initialization fails.
+func (z *Ciphertext) Rerandomize(api frontend.API, pubKey twistededwards.Point, r frontend.Variable) (*Ciphertext, error) {
+	curve, err := twistededwards.NewEdCurve(api, ecc_tweds.BN254)
+	if err != nil {
+		return nil, err
+	}
+	curve.AssertIsOnCurve(pubKey)
+	return &Ciphertext{
+		C1: curve.Add(z.C1, FixedBaseScalarMulBN254(api, r)),
+		C2: curve.Add(z.C2, curve.ScalarMul(pubKey, r)),
+	}, nil
+}
+
+// RerandomizationProof is a non-interactive Chaum–Pedersen proof that a
+// ciphertext is a re-randomization of another one with the same public key,
+// that is, that C1' - C1 and C2' - C2 share the same discrete log r with
+// respect to G and the public key. It uses the transcript of DecryptionProof.
+type RerandomizationProof DecryptionProof
+
+// Verify checks that the proof is a valid proof that the ciphertext out is a
+// re-randomization of the ciphertext in with the public key provided:
+//
+//	z·G = A1 + e·(C1' - C1)
+//	z·P = A2 + e·(C2' - C2)
+//
+// where e is the Fiat-Shamir challenge and P is the public key. It returns an
+// error if the curve initialization fails.
+func (p *RerandomizationProof) Verify(
+	api frontend.API,
+	hFn utils.Hasher,
+	pubKey twistededwards.Point,
+	in, out Ciphertext,
+) error {
+	curve, err := twistededwards.NewEdCurve(api, ecc_tweds.BN254)
+	if err != nil {
+		return err
+	}
+	curve.AssertIsOnCurve(pubKey)
+	curve.AssertIsOnCurve(in.C1)
+	curve.AssertIsOnCurve(in.C2)
+	curve.AssertIsOnCurve(out.C1)
+	curve.AssertIsOnCurve(out.C2)
+	// R1 = C1' - C1 = [r] * G, R2 = C2' - C2 = [r] * P
+	R1 := curve.Add(out.C1, curve.Neg(in.C1))
+	R2 := curve.Add(out.C2, curve.Neg(in.C2))
+	return (*DecryptionProof)(p).verify(api, curve, hFn, R1, pubKey, R2)
+}
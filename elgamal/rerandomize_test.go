@@ -0,0 +1,129 @@
+package elgamal
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/profile"
+	"github.com/consensys/gnark/std/algebra/native/twistededwards"
+	"github.com/consensys/gnark/test"
+	qt "github.com/frankban/quicktest"
+	iden3poseidon "github.com/iden3/go-iden3-crypto/poseidon"
+)
+
+type testRerandomizeCircuit struct {
+	PubKey twistededwards.Point `gnark:",public"`
+	In     Ciphertext           `gnark:",public"`
+	Out    Ciphertext           `gnark:",public"`
+	R      frontend.Variable
+}
+
+func (c *testRerandomizeCircuit) Define(api frontend.API) error {
+	out, err := c.In.Rerandomize(api, c.PubKey, c.R)
+	if err != nil {
+		return err
+	}
+	out.AssertIsEqual(api, &c.Out)
+	return nil
+}
+
+type testRerandomizationProofCircuit struct {
+	PubKey twistededwards.Point `gnark:",public"`
+	In     Ciphertext           `gnark:",public"`
+	Out    Ciphertext           `gnark:",public"`
+	Proof  RerandomizationProof
+}
+
+func (c *testRerandomizationProofCircuit) Define(api frontend.API) error {
+	return c.Proof.Verify(api, HashFn, c.PubKey, c.In, c.Out)
+}
+
+func TestRerandomize(t *testing.T) {
+	c := qt.New(t)
+	privKey, pubKey, err := GenerateKey()
+	c.Assert(err, qt.IsNil)
+	k, err := RandomK()
+	c.Assert(err, qt.IsNil)
+	r, err := RandomK()
+	c.Assert(err, qt.IsNil)
+	msg := big.NewInt(9)
+	in := NewNativeCiphertext().Encrypt(pubKey, k, msg)
+	out := in.Rerandomize(pubKey, r)
+	// the re-randomized ciphertext is different but decrypts to the same
+	// message, and it is the encryption with k + r
+	c.Assert(out.Compress(), qt.Not(qt.Equals), in.Compress())
+	res, err := out.Decrypt(privKey, NewDiscreteLogTable(100))
+	c.Assert(err, qt.IsNil)
+	c.Assert(res.Cmp(msg), qt.Equals, 0)
+	c.Assert(out.Compress(), qt.Equals,
+		NewNativeCiphertext().Encrypt(pubKey, new(big.Int).Add(k, r), msg).Compress())
+
+	witness := &testRerandomizeCircuit{
+		PubKey: PointFromNative(pubKey.Point()),
+		In:     CiphertextFromNative(in),
+		Out:    CiphertextFromNative(out),
+		R:      r,
+	}
+	invalid := *witness
+	invalid.R = new(big.Int).Add(r, big.NewInt(1))
+
+	assert := test.NewAssert(t)
+	assert.CheckCircuit(&testRerandomizeCircuit{},
+		test.WithValidAssignment(witness),
+		test.WithInvalidAssignment(&invalid),
+		test.WithCurves(ecc.BN254),
+		test.WithBackends(backend.GROTH16),
+	)
+}
+
+func TestRerandomizationProof(t *testing.T) {
+	c := qt.New(t)
+	_, pubKey, err := GenerateKey()
+	c.Assert(err, qt.IsNil)
+	k, err := RandomK()
+	c.Assert(err, qt.IsNil)
+	r, err := RandomK()
+	c.Assert(err, qt.IsNil)
+	in := NewNativeCiphertext().Encrypt(pubKey, k, big.NewInt(9))
+	out := in.Rerandomize(pubKey, r)
+	// the encryption of another message with the same randomness
+	other := NewNativeCiphertext().Encrypt(pubKey, new(big.Int).Add(k, r), big.NewInt(10))
+
+	proof, err := ProveRerandomization(iden3poseidon.Hash, pubKey, r, in, out)
+	c.Assert(err, qt.IsNil)
+	c.Assert(proof.Verify(iden3poseidon.Hash, pubKey, in, out), qt.IsNil)
+	c.Assert(proof.Verify(iden3poseidon.Hash, pubKey, in, other), qt.IsNotNil)
+	_, err = ProveRerandomization(iden3poseidon.Hash, pubKey, r, in, other)
+	c.Assert(err, qt.IsNotNil)
+
+	p := profile.Start()
+	_, _ = frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &testRerandomizationProofCircuit{})
+	p.Stop()
+	fmt.Println("constrains", p.NbConstraints())
+
+	witness := &testRerandomizationProofCircuit{
+		PubKey: PointFromNative(pubKey.Point()),
+		In:     CiphertextFromNative(in),
+		Out:    CiphertextFromNative(out),
+		Proof:  RerandomizationProofFromNative(proof),
+	}
+	invalidOut := *witness
+	invalidOut.Out = CiphertextFromNative(other)
+	// the proof is not valid with the input and the output swapped
+	invalidSwap := *witness
+	invalidSwap.In, invalidSwap.Out = witness.Out, witness.In
+
+	assert := test.NewAssert(t)
+	assert.CheckCircuit(&testRerandomizationProofCircuit{},
+		test.WithValidAssignment(witness),
+		test.WithInvalidAssignment(&invalidOut),
+		test.WithInvalidAssignment(&invalidSwap),
+		test.WithCurves(ecc.BN254),
+		test.WithBackends(backend.GROTH16),
+	)
+}
@@ -0,0 +1,129 @@
+package elgamal
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/test"
+	qt "github.com/frankban/quicktest"
+)
+
+// testWeight is the constant weight of the test circuit
+const testWeight = 5
+
+type testScalarMulCircuit struct {
+	A, B   Ciphertext
+	Weight frontend.Variable
+	// natively computed ciphertexts
+	Weighted, ConstWeighted, NegConstWeighted, Diff Ciphertext
+}
+
+func (c *testScalarMulCircuit) Define(api frontend.API) error {
+	new(Ciphertext).ScalarMul(api, &c.A, c.Weight).AssertIsEqual(api, &c.Weighted)
+	new(Ciphertext).ScalarMul(api, &c.A, testWeight).AssertIsEqual(api, &c.ConstWeighted)
+	new(Ciphertext).ScalarMul(api, &c.A, -testWeight).AssertIsEqual(api, &c.NegConstWeighted)
+	new(Ciphertext).Sub(api, &c.A, &c.B).AssertIsEqual(api, &c.Diff)
+	return nil
+}
+
+func TestScalarMul(t *testing.T) {
+	c := qt.New(t)
+	privKey, pubKey, err := GenerateKey()
+	c.Assert(err, qt.IsNil)
+	k1, err := RandomK()
+	c.Assert(err, qt.IsNil)
+	k2, err := RandomK()
+	c.Assert(err, qt.IsNil)
+	m1, m2, weight := big.NewInt(30), big.NewInt(12), big.NewInt(17)
+
+	a := NewNativeCiphertext().Encrypt(pubKey, k1, m1)
+	b := NewNativeCiphertext().Encrypt(pubKey, k2, m2)
+	weighted := NewNativeCiphertext().ScalarMul(a, weight)
+	constWeighted := NewNativeCiphertext().ScalarMul(a, big.NewInt(testWeight))
+	diff := NewNativeCiphertext().Sub(a, b)
+	// the results decrypt to w·m and m1-m2
+	table := NewDiscreteLogTable(1000)
+	for ct, expected := range map[*NativeCiphertext]int64{
+		weighted:      17 * 30,
+		constWeighted: testWeight * 30,
+		diff:          30 - 12,
+	} {
+		m, err := ct.Decrypt(privKey, table)
+		c.Assert(err, qt.IsNil)
+		c.Assert(m.Int64(), qt.Equals, expected)
+	}
+	// a weight of zero gives the identity, and subtracting a ciphertext from
+	// itself too
+	c.Assert(NewNativeCiphertext().ScalarMul(a, big.NewInt(0)).Compress(), qt.Equals, NewNativeCiphertext().Compress())
+	// a negative weight gives the negation, so [w]a + [-w]a is the identity
+	negWeighted := NewNativeCiphertext().ScalarMul(a, new(big.Int).Neg(weight))
+	c.Assert(negWeighted.Compress(), qt.Equals, NewNativeCiphertext().Neg(weighted).Compress())
+	c.Assert(NewNativeCiphertext().Add(weighted, negWeighted).Compress(), qt.Equals, NewNativeCiphertext().Compress())
+	c.Assert(NewNativeCiphertext().Sub(a, a).Compress(), qt.Equals, NewNativeCiphertext().Compress())
+
+	witness := &testScalarMulCircuit{
+		A:                CiphertextFromNative(a),
+		B:                CiphertextFromNative(b),
+		Weight:           weight,
+		Weighted:         CiphertextFromNative(weighted),
+		ConstWeighted:    CiphertextFromNative(constWeighted),
+		NegConstWeighted: CiphertextFromNative(NewNativeCiphertext().Neg(constWeighted)),
+		Diff:             CiphertextFromNative(diff),
+	}
+	// a negative weight gives the negation in the circuit too
+	negWeight := *witness
+	negWeight.Weight = new(big.Int).Neg(weight)
+	negWeight.Weighted = CiphertextFromNative(negWeighted)
+	otherWeight := *witness
+	otherWeight.Weight = big.NewInt(18)
+	swapped := *witness
+	swapped.A, swapped.B = witness.B, witness.A
+
+	assert := test.NewAssert(t)
+	assert.CheckCircuit(&testScalarMulCircuit{},
+		test.WithValidAssignment(witness),
+		test.WithValidAssignment(&negWeight),
+		test.WithInvalidAssignment(&otherWeight),
+		test.WithInvalidAssignment(&swapped),
+		test.WithCurves(ecc.BN254),
+		test.WithBackends(backend.GROTH16),
+	)
+}
+
+type testScalarMulConstraintsCircuit struct {
+	A      Ciphertext
+	Weight frontend.Variable
+	// Const is the constant weight, or zero for the variable one
+	Const int `gnark:"-"`
+}
+
+func (c *testScalarMulConstraintsCircuit) Define(api frontend.API) error {
+	if c.Const != 0 {
+		new(Ciphertext).ScalarMul(api, &c.A, c.Const)
+		return nil
+	}
+	new(Ciphertext).ScalarMul(api, &c.A, c.Weight)
+	return nil
+}
+
+func TestScalarMulConstraints(t *testing.T) {
+	c := qt.New(t)
+	nbConstraints := map[int]int{}
+	for _, weight := range []int{0, testWeight, -testWeight} {
+		cs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &testScalarMulConstraintsCircuit{Const: weight})
+		c.Assert(err, qt.IsNil)
+		nbConstraints[weight] = cs.GetNbConstraints()
+		if weight == 0 {
+			fmt.Println("variable weight constrains", cs.GetNbConstraints())
+		} else {
+			fmt.Println("constant weight", weight, "constrains", cs.GetNbConstraints())
+		}
+	}
+	// a negative constant costs the same as its absolute value
+	c.Assert(nbConstraints[-testWeight], qt.Equals, nbConstraints[testWeight])
+}
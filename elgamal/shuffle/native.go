@@ -0,0 +1,89 @@
+package shuffle
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+
+	"github.com/iden3/go-iden3-crypto/babyjub"
+	"github.com/vocdoni/gnark-crypto-primitives/elgamal"
+)
+
+// NativeShuffle is a shuffle of ciphertexts computed natively: the input i
+// is sent to the output Permutation[i] and re-randomized with the random
+// scalar Randomness[Permutation[i]].
+type NativeShuffle struct {
+	Permutation []int
+	Randomness  []*big.Int
+}
+
+// NewNativeShuffle returns a new NativeShuffle of n ciphertexts with a random
+// permutation and random scalars.
+func NewNativeShuffle(n int) (*NativeShuffle, error) {
+	s := &NativeShuffle{
+		Permutation: make([]int, n),
+		Randomness:  make([]*big.Int, n),
+	}
+	// Fisher-Yates shuffle
+	for i := range s.Permutation {
+		s.Permutation[i] = i
+	}
+	for i := n - 1; i > 0; i-- {
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate permutation: %w", err)
+		}
+		s.Permutation[i], s.Permutation[j.Int64()] = s.Permutation[j.Int64()], s.Permutation[i]
+	}
+	for i := range s.Randomness {
+		r, err := elgamal.RandomK()
+		if err != nil {
+			return nil, err
+		}
+		s.Randomness[i] = r
+	}
+	return s, nil
+}
+
+// Apply returns the input ciphertexts shuffled with the public key provided.
+func (s *NativeShuffle) Apply(pubKey *babyjub.PublicKey, in []*elgamal.NativeCiphertext) ([]*elgamal.NativeCiphertext, error) {
+	if len(in) != len(s.Permutation) || len(in) != len(s.Randomness) {
+		return nil, fmt.Errorf("mismatched number of ciphertexts (%d), permutation (%d) and random scalars (%d)",
+			len(in), len(s.Permutation), len(s.Randomness))
+	}
+	out := make([]*elgamal.NativeCiphertext, len(in))
+	for i, o := range s.Permutation {
+		if o < 0 || o >= len(out) || out[o] != nil {
+			return nil, fmt.Errorf("invalid permutation")
+		}
+		out[o] = in[i].Rerandomize(pubKey, s.Randomness[o])
+	}
+	return out, nil
+}
+
+// Witness returns the circuit witness of the shuffle, computing the switches
+// of the permutation network.
+func (s *NativeShuffle) Witness() (Shuffle, error) {
+	switches, err := route(s.Permutation)
+	if err != nil {
+		return Shuffle{}, err
+	}
+	w := New(len(s.Permutation))
+	for i, sw := range switches {
+		w.Switches[i] = 0
+		if sw {
+			w.Switches[i] = 1
+		}
+	}
+	for i, r := range s.Randomness {
+		w.Randomness[i] = r
+	}
+	return w, nil
+}
+
+// CiphertextsFromNative converts native ciphertexts to circuit ciphertexts,
+// with the points in Reduced TwistedEdwards format. See
+// elgamal.CiphertextsFromNative.
+func CiphertextsFromNative(cts []*elgamal.NativeCiphertext) []elgamal.Ciphertext {
+	return elgamal.CiphertextsFromNative(cts)
+}
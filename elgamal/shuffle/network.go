@@ -0,0 +1,208 @@
+package shuffle
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/vocdoni/gnark-crypto-primitives/elgamal"
+)
+
+// The permutation network is an arbitrary size Waksman network (AS-Waksman),
+// defined recursively for n inputs:
+//
+//   - the left column has n/2 switches over the inputs (2i, 2i+1), which send
+//     one of them to the input i of the top subnetwork, of size n/2, and the
+//     other one to the input i of the bottom subnetwork, of size n - n/2. If n
+//     is odd, the last input goes directly to the last input of the bottom
+//     subnetwork.
+//   - the right column has a switch for every pair of outputs (2j, 2j+1) that
+//     receives the output j of both subnetworks, except the last pair if n is
+//     even, where the output n-2 is always the last output of the top
+//     subnetwork and the output n-1 the last output of the bottom one. If n is
+//     odd, the last output is the last output of the bottom subnetwork.
+//
+// A switch is straight if its value is 0, that is, the first input goes to
+// the top subnetwork (left column) or the top subnetwork goes to the first
+// output (right column), and crossed if its value is 1. The switches are
+// ordered by the left column, the top subnetwork, the bottom subnetwork and
+// the right column.
+
+// NumSwitches returns the number of switches of the permutation network of
+// n elements.
+func NumSwitches(n int) int {
+	if n < 2 {
+		return 0
+	}
+	half := n / 2
+	return half + rightSwitches(n) + NumSwitches(half) + NumSwitches(n-half)
+}
+
+// rightSwitches returns the number of switches of the right column of the
+// permutation network of n elements.
+func rightSwitches(n int) int {
+	if n%2 == 0 {
+		return n/2 - 1
+	}
+	return n / 2
+}
+
+// permute returns the ciphertexts provided permuted by the network with the
+// switches provided, and the switches that were not used.
+func permute(api frontend.API, in []elgamal.Ciphertext, switches []frontend.Variable) ([]elgamal.Ciphertext, []frontend.Variable) {
+	n := len(in)
+	if n < 2 {
+		return in, switches
+	}
+	half := n / 2
+	top := make([]elgamal.Ciphertext, half)
+	bottom := make([]elgamal.Ciphertext, n-half)
+	for i := range half {
+		api.AssertIsBoolean(switches[i])
+		top[i], bottom[i] = swap(api, switches[i], in[2*i], in[2*i+1])
+	}
+	if n%2 == 1 {
+		bottom[half] = in[n-1]
+	}
+	switches = switches[half:]
+	top, switches = permute(api, top, switches)
+	bottom, switches = permute(api, bottom, switches)
+	out := make([]elgamal.Ciphertext, n)
+	nRight := rightSwitches(n)
+	for j := range nRight {
+		api.AssertIsBoolean(switches[j])
+		out[2*j], out[2*j+1] = swap(api, switches[j], top[j], bottom[j])
+	}
+	if n%2 == 0 {
+		out[n-2], out[n-1] = top[half-1], bottom[half-1]
+	} else {
+		out[n-1] = bottom[half]
+	}
+	return out, switches[nRight:]
+}
+
+// swap returns (a, b) if s is 0 or (b, a) if s is 1.
+func swap(api frontend.API, s frontend.Variable, a, b elgamal.Ciphertext) (elgamal.Ciphertext, elgamal.Ciphertext) {
+	ca, cb := a.Serialize(), b.Serialize()
+	first, second := make([]frontend.Variable, len(ca)), make([]frontend.Variable, len(ca))
+	for i := range ca {
+		// first = a + s·(b - a), second = a + b - first
+		first[i] = api.Add(ca[i], api.Mul(s, api.Sub(cb[i], ca[i])))
+		second[i] = api.Sub(api.Add(ca[i], cb[i]), first[i])
+	}
+	return deserialize(first), deserialize(second)
+}
+
+// deserialize returns the ciphertext of the coordinates returned by
+// Ciphertext.Serialize.
+func deserialize(coords []frontend.Variable) elgamal.Ciphertext {
+	var ct elgamal.Ciphertext
+	ct.C1.X, ct.C1.Y = coords[0], coords[1]
+	ct.C2.X, ct.C2.Y = coords[2], coords[3]
+	return ct
+}
+
+// route returns the switches of the permutation network that sends the input
+// i to the output perm[i], coloring the inputs that go to the top (0) and
+// bottom (1) subnetworks, so the inputs of every switch of the left column
+// and the sources of the outputs of every switch of the right column go to
+// different subnetworks. The constraints are paths and cycles of even length,
+// so the coloring always exists.
+func route(perm []int) ([]bool, error) {
+	n := len(perm)
+	if n < 2 {
+		return nil, nil
+	}
+	inv := make([]int, n)
+	for i := range inv {
+		inv[i] = -1
+	}
+	for i, o := range perm {
+		if o < 0 || o >= n || inv[o] != -1 {
+			return nil, fmt.Errorf("invalid permutation")
+		}
+		inv[o] = i
+	}
+	color := make([]int, n)
+	for i := range color {
+		color[i] = -1
+	}
+	// partners returns the inputs that must go to the other subnetwork
+	partners := func(u int) []int {
+		var res []int
+		if n%2 == 0 || u != n-1 {
+			res = append(res, u^1)
+		}
+		if o := perm[u]; n%2 == 0 || o != n-1 {
+			res = append(res, inv[o^1])
+		}
+		return res
+	}
+	set := func(u, c int) error {
+		stack := []int{u}
+		color[u] = c
+		for len(stack) > 0 {
+			v := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			for _, w := range partners(v) {
+				switch color[w] {
+				case -1:
+					color[w] = 1 - color[v]
+					stack = append(stack, w)
+				case color[v]:
+					return fmt.Errorf("inconsistent routing of the input %d", w)
+				}
+			}
+		}
+		return nil
+	}
+	// the last output always comes from the bottom subnetwork, and so does
+	// the last input if n is odd
+	if err := set(inv[n-1], 1); err != nil {
+		return nil, err
+	}
+	if n%2 == 1 && color[n-1] == -1 {
+		if err := set(n-1, 1); err != nil {
+			return nil, err
+		}
+	}
+	for u := range n {
+		if color[u] == -1 {
+			if err := set(u, 0); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if n%2 == 1 && color[n-1] != 1 {
+		return nil, fmt.Errorf("inconsistent routing of the last input")
+	}
+	half := n / 2
+	nRight := rightSwitches(n)
+	left := make([]bool, half)
+	right := make([]bool, nRight)
+	topPerm := make([]int, half)
+	bottomPerm := make([]int, n-half)
+	for i := range left {
+		left[i] = color[2*i] == 1
+	}
+	for u, o := range perm {
+		if color[u] == 0 {
+			topPerm[u/2] = o / 2
+			if o/2 < nRight {
+				right[o/2] = o%2 == 1
+			}
+		} else {
+			bottomPerm[u/2] = o / 2
+		}
+	}
+	topSwitches, err := route(topPerm)
+	if err != nil {
+		return nil, err
+	}
+	bottomSwitches, err := route(bottomPerm)
+	if err != nil {
+		return nil, err
+	}
+	switches := append(left, topSwitches...)
+	switches = append(switches, bottomSwitches...)
+	return append(switches, right...), nil
+}
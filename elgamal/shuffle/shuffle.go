@@ -0,0 +1,64 @@
+// shuffle package implements a verifiable shuffle of ElGamal ciphertexts (the
+// step of a mixnet), to break the link between the voters and their ballots.
+// It proves that the output ciphertexts are a permutation of the input ones,
+// re-randomized with the same public key, so they decrypt to the same
+// messages in another order.
+//
+// The permutation is applied with an arbitrary size Waksman network, whose
+// switches are part of the private witness, and every permuted ciphertext is
+// re-randomized with a private random scalar (see
+// elgamal.Ciphertext.Rerandomize). The witness is computed natively with
+// NativeShuffle.
+package shuffle
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/native/twistededwards"
+	"github.com/vocdoni/gnark-crypto-primitives/elgamal"
+)
+
+// Shuffle is the private witness of a shuffle of N ciphertexts: the
+// switches of the permutation network and the random scalars used to
+// re-randomize the permuted ciphertexts.
+type Shuffle struct {
+	Switches   []frontend.Variable
+	Randomness []frontend.Variable
+}
+
+// New returns a new Shuffle with the sizes of the witness of a shuffle of n
+// ciphertexts, to be used in the definition of the circuits.
+func New(n int) Shuffle {
+	return Shuffle{
+		Switches:   make([]frontend.Variable, NumSwitches(n)),
+		Randomness: make([]frontend.Variable, n),
+	}
+}
+
+// Verify checks that the output ciphertexts are the input ones permuted by
+// the switches of the shuffle and re-randomized with its random scalars and
+// the public key provided. It returns an error if the sizes of the inputs,
+// the outputs and the witness do not match.
+func (s *Shuffle) Verify(api frontend.API, pubKey twistededwards.Point, in, out []elgamal.Ciphertext) error {
+	n := len(in)
+	if n == 0 {
+		return fmt.Errorf("no ciphertexts provided")
+	}
+	if len(out) != n || len(s.Randomness) != n {
+		return fmt.Errorf("mismatched number of inputs (%d), outputs (%d) and random scalars (%d)",
+			n, len(out), len(s.Randomness))
+	}
+	if len(s.Switches) != NumSwitches(n) {
+		return fmt.Errorf("invalid number of switches: %d, expected %d", len(s.Switches), NumSwitches(n))
+	}
+	permuted, _ := permute(api, in, s.Switches)
+	for i := range permuted {
+		res, err := permuted[i].Rerandomize(api, pubKey, s.Randomness[i])
+		if err != nil {
+			return err
+		}
+		res.AssertIsEqual(api, &out[i])
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package shuffle
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/std/algebra/native/twistededwards"
+	"github.com/consensys/gnark/test"
+	qt "github.com/frankban/quicktest"
+	"github.com/vocdoni/gnark-crypto-primitives/elgamal"
+)
+
+type testPermuteCircuit struct {
+	In       []elgamal.Ciphertext
+	Out      []elgamal.Ciphertext
+	Switches []frontend.Variable
+}
+
+func (c *testPermuteCircuit) Define(api frontend.API) error {
+	out, rest := permute(api, c.In, c.Switches)
+	if len(rest) != 0 {
+		return fmt.Errorf("%d switches not used", len(rest))
+	}
+	for i := range out {
+		out[i].AssertIsEqual(api, &c.Out[i])
+	}
+	return nil
+}
+
+// testCiphertext returns a ciphertext with the coordinates derived from i, to
+// test the permutation network without curve operations.
+func testCiphertext(i int) elgamal.Ciphertext {
+	var ct elgamal.Ciphertext
+	ct.C1.X, ct.C1.Y = 4*i, 4*i+1
+	ct.C2.X, ct.C2.Y = 4*i+2, 4*i+3
+	return ct
+}
+
+func TestPermutationNetwork(t *testing.T) {
+	c := qt.New(t)
+	field := ecc.BN254.ScalarField()
+	for n := 1; n <= 17; n++ {
+		for range 10 {
+			s, err := NewNativeShuffle(n)
+			c.Assert(err, qt.IsNil)
+			w, err := s.Witness()
+			c.Assert(err, qt.IsNil)
+			circuit := &testPermuteCircuit{
+				In:       make([]elgamal.Ciphertext, n),
+				Out:      make([]elgamal.Ciphertext, n),
+				Switches: make([]frontend.Variable, NumSwitches(n)),
+			}
+			witness := &testPermuteCircuit{
+				In:       make([]elgamal.Ciphertext, n),
+				Out:      make([]elgamal.Ciphertext, n),
+				Switches: w.Switches,
+			}
+			for i, o := range s.Permutation {
+				witness.In[i] = testCiphertext(i)
+				witness.Out[o] = testCiphertext(i)
+			}
+			c.Assert(test.IsSolved(circuit, witness, field), qt.IsNil, qt.Commentf("n=%d perm=%v", n, s.Permutation))
+			if n < 2 {
+				continue
+			}
+			// flipping a switch changes the permutation
+			flipped := *witness
+			flipped.Switches = append([]frontend.Variable{}, w.Switches...)
+			flipped.Switches[0] = 1 - w.Switches[0].(int)
+			c.Assert(test.IsSolved(circuit, &flipped, field), qt.IsNotNil)
+		}
+	}
+	// the identity and the reverse permutations
+	for _, n := range []int{2, 7, 8} {
+		for _, reverse := range []bool{false, true} {
+			perm := make([]int, n)
+			for i := range perm {
+				perm[i] = i
+				if reverse {
+					perm[i] = n - 1 - i
+				}
+			}
+			_, err := route(perm)
+			c.Assert(err, qt.IsNil)
+		}
+	}
+	// invalid permutations
+	_, err := route([]int{0, 0, 1})
+	c.Assert(err, qt.IsNotNil)
+	_, err = route([]int{0, 3, 1})
+	c.Assert(err, qt.IsNotNil)
+}
+
+const testShuffleSize = 5
+
+type testShuffleCircuit struct {
+	PubKey  twistededwards.Point `gnark:",public"`
+	In      []elgamal.Ciphertext `gnark:",public"`
+	Out     []elgamal.Ciphertext `gnark:",public"`
+	Shuffle Shuffle
+}
+
+func newTestShuffleCircuit(n int) *testShuffleCircuit {
+	return &testShuffleCircuit{
+		In:      make([]elgamal.Ciphertext, n),
+		Out:     make([]elgamal.Ciphertext, n),
+		Shuffle: New(n),
+	}
+}
+
+func (c *testShuffleCircuit) Define(api frontend.API) error {
+	return c.Shuffle.Verify(api, c.PubKey, c.In, c.Out)
+}
+
+func TestShuffle(t *testing.T) {
+	c := qt.New(t)
+	privKey, pubKey, err := elgamal.GenerateKey()
+	c.Assert(err, qt.IsNil)
+	in := make([]*elgamal.NativeCiphertext, testShuffleSize)
+	for i := range in {
+		k, err := elgamal.RandomK()
+		c.Assert(err, qt.IsNil)
+		in[i] = elgamal.NewNativeCiphertext().Encrypt(pubKey, k, big.NewInt(int64(i+1)))
+	}
+	s, err := NewNativeShuffle(testShuffleSize)
+	c.Assert(err, qt.IsNil)
+	out, err := s.Apply(pubKey, in)
+	c.Assert(err, qt.IsNil)
+	// the outputs decrypt to the permuted messages
+	table := elgamal.NewDiscreteLogTable(testShuffleSize)
+	for i, o := range s.Permutation {
+		m, err := out[o].Decrypt(privKey, table)
+		c.Assert(err, qt.IsNil)
+		c.Assert(m.Int64(), qt.Equals, int64(i+1))
+	}
+	w, err := s.Witness()
+	c.Assert(err, qt.IsNil)
+
+	witness := &testShuffleCircuit{
+		PubKey:  elgamal.PointFromNative(pubKey.Point()),
+		In:      CiphertextsFromNative(in),
+		Out:     CiphertextsFromNative(out),
+		Shuffle: w,
+	}
+	// two outputs swapped
+	swapped := *witness
+	swapped.Out = CiphertextsFromNative(out)
+	swapped.Out[0], swapped.Out[1] = swapped.Out[1], swapped.Out[0]
+	// an output replaced by the encryption of another message
+	k, err := elgamal.RandomK()
+	c.Assert(err, qt.IsNil)
+	replaced := *witness
+	replaced.Out = CiphertextsFromNative(out)
+	replaced.Out[2] = elgamal.CiphertextFromNative(elgamal.NewNativeCiphertext().Encrypt(pubKey, k, big.NewInt(100)))
+
+	assert := test.NewAssert(t)
+	assert.CheckCircuit(newTestShuffleCircuit(testShuffleSize),
+		test.WithValidAssignment(witness),
+		test.WithInvalidAssignment(&swapped),
+		test.WithInvalidAssignment(&replaced),
+		test.WithCurves(ecc.BN254),
+		test.WithBackends(backend.GROTH16),
+	)
+}
+
+func TestShuffleConstraints(t *testing.T) {
+	c := qt.New(t)
+	for _, n := range []int{2, 8, 32, 128} {
+		cs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, newTestShuffleCircuit(n))
+		c.Assert(err, qt.IsNil)
+		fmt.Println("n", n, "switches", NumSwitches(n), "constrains", cs.GetNbConstraints())
+	}
+}
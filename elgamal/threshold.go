@@ -0,0 +1,132 @@
+package elgamal
+
+import (
+	"fmt"
+	"math/big"
+
+	ecc_tweds "github.com/consensys/gnark-crypto/ecc/twistededwards"
+	"github.com/consensys/gnark/constraint/solver"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/native/twistededwards"
+	"github.com/vocdoni/gnark-crypto-primitives/ecc/msm"
+	"github.com/vocdoni/gnark-crypto-primitives/utils"
+)
+
+func init() { solver.RegisterHint(LagrangeHint) }
+
+// LagrangeHint receives the indexes of the trustees that decrypt and returns
+// their Lagrange coefficients at 0, modulo the order of the subgroup (see
+// LagrangeCoefficient).
+var LagrangeHint solver.Hint = func(_ *big.Int, in, out []*big.Int) error {
+	indexes := make([]int, len(in))
+	for i, index := range in {
+		if !index.IsInt64() || index.Int64() < 1 || index.Int64() >= 1<<31 {
+			return fmt.Errorf("invalid trustee index: %s", index)
+		}
+		indexes[i] = int(index.Int64())
+	}
+	for i, index := range indexes {
+		lambda, err := LagrangeCoefficient(index, indexes)
+		if err != nil {
+			return err
+		}
+		out[i].Set(lambda)
+	}
+	return nil
+}
+
+// DecryptionShare is the partial decryption of a ciphertext by the trustee
+// with the index and the verification key provided, D = [keyShare] * C1,
+// with a proof that D and the verification key, [keyShare] * G, share the
+// same discrete log.
+type DecryptionShare struct {
+	Index           frontend.Variable
+	VerificationKey twistededwards.Point
+	D               twistededwards.Point
+	Proof           DecryptionProof
+}
+
+// Verify checks that the decryption share is the partial decryption of the
+// ciphertext C1 provided by the trustee of its verification key. It returns
+// an error if the curve initialization fails.
+func (s *DecryptionShare) Verify(api frontend.API, hFn utils.Hasher, C1 twistededwards.Point) error {
+	curve, err := twistededwards.NewEdCurve(api, ecc_tweds.BN254)
+	if err != nil {
+		return err
+	}
+	curve.AssertIsOnCurve(s.VerificationKey)
+	curve.AssertIsOnCurve(s.D)
+	return s.Proof.verify(api, curve, hFn, s.VerificationKey, C1, s.D)
+}
+
+// AssertThresholdDecrypt checks that the ciphertext, encrypted with the
+// public key of a distributed key generation, decrypts to the message msg
+// with the decryption shares of a threshold of trustees. It verifies the
+// proof of every share and checks:
+//
+//	Σ λ_i·Y_i = P
+//	[msg]·G + Σ λ_i·D_i = C2
+//
+// where λ_i are the Lagrange coefficients of the indexes of the shares, Y_i
+// the verification keys of the trustees and P the public key. The
+// coefficients are provided by LagrangeHint and constrained by the first
+// equation, which holds for the Lagrange coefficients of any threshold of
+// valid verification keys, and guarantees that Σ λ_i·D_i = [privKey]·C1 for
+// the shares with valid proofs. The verification keys should be constrained
+// by the caller to identify the trustees, but it is not required to check
+// the message.
+func AssertThresholdDecrypt(
+	api frontend.API,
+	hFn utils.Hasher,
+	pubKey twistededwards.Point,
+	ciphertext Ciphertext,
+	shares []DecryptionShare,
+	msg frontend.Variable,
+) error {
+	if len(shares) == 0 {
+		return fmt.Errorf("no decryption shares provided")
+	}
+	curve, err := twistededwards.NewEdCurve(api, ecc_tweds.BN254)
+	if err != nil {
+		return err
+	}
+	curve.AssertIsOnCurve(pubKey)
+	curve.AssertIsOnCurve(ciphertext.C1)
+	curve.AssertIsOnCurve(ciphertext.C2)
+	indexes := make([]frontend.Variable, len(shares))
+	keys := make([]twistededwards.Point, len(shares))
+	for i, s := range shares {
+		if err := s.Verify(api, hFn, ciphertext.C1); err != nil {
+			return err
+		}
+		indexes[i] = s.Index
+		keys[i] = s.VerificationKey
+	}
+	lambdas, err := api.Compiler().NewHint(LagrangeHint, len(shares), indexes...)
+	if err != nil {
+		return err
+	}
+	// Σ λ_i·Y_i == P
+	combinedKey, err := msm.MultiScalarMul(curve, keys, lambdas)
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(combinedKey.X, pubKey.X)
+	api.AssertIsEqual(combinedKey.Y, pubKey.Y)
+	// [msg]·G + Σ λ_i·D_i == C2, with G as a constant to use the fixed-base
+	// tables
+	base := curve.Params().Base
+	points := []twistededwards.Point{{X: base[0], Y: base[1]}}
+	scalars := []frontend.Variable{msg}
+	for i, s := range shares {
+		points = append(points, s.D)
+		scalars = append(scalars, lambdas[i])
+	}
+	C2, err := msm.MultiScalarMul(curve, points, scalars)
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(C2.X, ciphertext.C2.X)
+	api.AssertIsEqual(C2.Y, ciphertext.C2.Y)
+	return nil
+}
@@ -0,0 +1,159 @@
+package elgamal
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/profile"
+	"github.com/consensys/gnark/std/algebra/native/twistededwards"
+	"github.com/consensys/gnark/test"
+	qt "github.com/frankban/quicktest"
+	"github.com/iden3/go-iden3-crypto/babyjub"
+	iden3poseidon "github.com/iden3/go-iden3-crypto/poseidon"
+)
+
+const (
+	testThreshold   = 3
+	testNumTrustees = 5
+)
+
+type testThresholdDecryptCircuit struct {
+	PubKey     twistededwards.Point `gnark:",public"`
+	Ciphertext Ciphertext           `gnark:",public"`
+	Msg        frontend.Variable    `gnark:",public"`
+	Shares     [testThreshold]DecryptionShare
+}
+
+func (c *testThresholdDecryptCircuit) Define(api frontend.API) error {
+	return AssertThresholdDecrypt(api, HashFn, c.PubKey, c.Ciphertext, c.Shares[:], c.Msg)
+}
+
+// testDKG runs the distributed key generation among the trustees and returns
+// the public key, the shares of the private key and the verification keys of
+// the trustees, indexed from 1.
+func testDKG(c *qt.C) (*babyjub.PublicKey, map[int]*big.Int, map[int]*babyjub.Point) {
+	dealers := make([]*Dealer, testNumTrustees)
+	commitments := make([][]*babyjub.Point, testNumTrustees)
+	for i := range dealers {
+		var err error
+		dealers[i], err = NewDealer(i+1, testThreshold)
+		c.Assert(err, qt.IsNil)
+		commitments[i] = dealers[i].Commitments
+	}
+	keyShares := map[int]*big.Int{}
+	verificationKeys := map[int]*babyjub.Point{}
+	for index := 1; index <= testNumTrustees; index++ {
+		shares := make([]*big.Int, 0, testNumTrustees)
+		for _, d := range dealers {
+			share, err := d.Share(index)
+			c.Assert(err, qt.IsNil)
+			c.Assert(VerifyShare(d.Commitments, index, share), qt.IsNil)
+			// a wrong share must be detected
+			wrong := new(big.Int).Add(share, big.NewInt(1))
+			c.Assert(VerifyShare(d.Commitments, index, wrong), qt.IsNotNil)
+			shares = append(shares, share)
+		}
+		keyShares[index] = CombineShares(shares...)
+		verificationKeys[index] = VerificationKey(index, commitments...)
+		c.Assert(verificationKeys[index].Compress(), qt.Equals,
+			babyjub.NewPoint().Mul(keyShares[index], babyjub.B8).Compress())
+	}
+	return DKGPublicKey(commitments...), keyShares, verificationKeys
+}
+
+func TestThresholdDecryptNative(t *testing.T) {
+	c := qt.New(t)
+	pubKey, keyShares, verificationKeys := testDKG(c)
+	k, err := RandomK()
+	c.Assert(err, qt.IsNil)
+	msg := big.NewInt(1234)
+	ct := NewNativeCiphertext().Encrypt(pubKey, k, msg)
+	table := NewDiscreteLogTable(1 << 16)
+
+	decryptionShares := map[int]*NativeDecryptionShare{}
+	for index, keyShare := range keyShares {
+		share, err := ProveDecryptionShare(iden3poseidon.Hash, index, keyShare, ct)
+		c.Assert(err, qt.IsNil)
+		c.Assert(share.Verify(iden3poseidon.Hash, verificationKeys[index], ct), qt.IsNil)
+		// the share must not be valid for another trustee
+		other := index%testNumTrustees + 1
+		c.Assert(share.Verify(iden3poseidon.Hash, verificationKeys[other], ct), qt.IsNotNil)
+		decryptionShares[index] = share
+	}
+	// any threshold of trustees decrypts the message
+	for _, indexes := range [][]int{{1, 2, 3}, {1, 3, 5}, {5, 2, 4}, {1, 2, 3, 4, 5}} {
+		shares := make([]*NativeDecryptionShare, len(indexes))
+		for i, index := range indexes {
+			shares[i] = decryptionShares[index]
+		}
+		res, err := CombineDecryptionShares(ct, shares, table)
+		c.Assert(err, qt.IsNil)
+		c.Assert(res.Cmp(msg), qt.Equals, 0)
+	}
+	// less than the threshold does not
+	_, err = CombineDecryptionShares(ct, []*NativeDecryptionShare{decryptionShares[1], decryptionShares[2]}, table)
+	c.Assert(err, qt.IsNotNil)
+	// duplicated shares are rejected
+	_, err = CombineDecryptionShares(ct, []*NativeDecryptionShare{decryptionShares[1], decryptionShares[1], decryptionShares[2]}, table)
+	c.Assert(err, qt.IsNotNil)
+}
+
+func TestThresholdDecrypt(t *testing.T) {
+	c := qt.New(t)
+	pubKey, keyShares, verificationKeys := testDKG(c)
+	k, err := RandomK()
+	c.Assert(err, qt.IsNil)
+	msg := big.NewInt(1234)
+	ct := NewNativeCiphertext().Encrypt(pubKey, k, msg)
+
+	p := profile.Start()
+	_, _ = frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &testThresholdDecryptCircuit{})
+	p.Stop()
+	fmt.Println("constrains", p.NbConstraints())
+
+	witness := func(indexes ...int) *testThresholdDecryptCircuit {
+		w := &testThresholdDecryptCircuit{
+			PubKey:     PointFromNative(pubKey.Point()),
+			Ciphertext: CiphertextFromNative(ct),
+			Msg:        msg,
+		}
+		for i, index := range indexes {
+			share, err := ProveDecryptionShare(iden3poseidon.Hash, index, keyShares[index], ct)
+			c.Assert(err, qt.IsNil)
+			w.Shares[i] = DecryptionShareFromNative(share, verificationKeys[index])
+		}
+		return w
+	}
+	// another message
+	invalidMsg := witness(2, 3, 5)
+	invalidMsg.Msg = big.NewInt(1235)
+	// a share with a wrong index, so the Lagrange coefficients are wrong
+	invalidIndex := witness(2, 3, 5)
+	invalidIndex.Shares[2].Index = 4
+	// a share of another trustee with the verification key of the replaced one
+	invalidKey := witness(2, 3, 5)
+	invalidKey.Shares[2].VerificationKey = PointFromNative(verificationKeys[4])
+	// a share of another ciphertext
+	otherCt := NewNativeCiphertext().Encrypt(pubKey, big.NewInt(7), msg)
+	otherShare, err := ProveDecryptionShare(iden3poseidon.Hash, 5, keyShares[5], otherCt)
+	c.Assert(err, qt.IsNil)
+	invalidShare := witness(2, 3, 5)
+	invalidShare.Shares[2] = DecryptionShareFromNative(otherShare, verificationKeys[5])
+
+	assert := test.NewAssert(t)
+	assert.CheckCircuit(&testThresholdDecryptCircuit{},
+		test.WithValidAssignment(witness(1, 2, 3)),
+		test.WithValidAssignment(witness(5, 1, 4)),
+		test.WithInvalidAssignment(invalidMsg),
+		test.WithInvalidAssignment(invalidIndex),
+		test.WithInvalidAssignment(invalidKey),
+		test.WithInvalidAssignment(invalidShare),
+		test.WithCurves(ecc.BN254),
+		test.WithBackends(backend.GROTH16),
+	)
+}
@@ -0,0 +1,152 @@
+package elgamal
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/std/algebra/native/twistededwards"
+	"github.com/consensys/gnark/test"
+	qt "github.com/frankban/quicktest"
+	"github.com/vocdoni/gnark-crypto-primitives/hash/native/bn254/poseidon"
+)
+
+const testVectorSize = 4
+
+type testCiphertextsCircuit struct {
+	PubKey twistededwards.Point `gnark:",public"`
+	K      frontend.Variable
+	Msgs   [testVectorSize]frontend.Variable
+	Sel    frontend.Variable
+	// natively computed ciphertexts
+	Encrypted, Other, Sum Ciphertexts
+}
+
+func (c *testCiphertextsCircuit) Define(api frontend.API) error {
+	encrypted, err := NewCiphertexts(testVectorSize).Encrypt(api, c.PubKey, c.K, c.Msgs[:]...)
+	if err != nil {
+		return err
+	}
+	encrypted.AssertIsEqual(api, c.Encrypted)
+	NewCiphertexts(testVectorSize).Add(api, encrypted, c.Other).AssertIsEqual(api, c.Sum)
+	// Sel is always 1, so the selected vector is the encrypted one
+	selected := NewCiphertexts(testVectorSize).Select(api, c.Sel, c.Encrypted, c.Other)
+	selected.AssertIsEqual(api, encrypted)
+	api.AssertIsEqual(len(encrypted.Serialize()), 4*testVectorSize)
+	return nil
+}
+
+func newTestCiphertextsCircuit() *testCiphertextsCircuit {
+	return &testCiphertextsCircuit{
+		Encrypted: NewCiphertexts(testVectorSize),
+		Other:     NewCiphertexts(testVectorSize),
+		Sum:       NewCiphertexts(testVectorSize),
+	}
+}
+
+func TestCiphertexts(t *testing.T) {
+	c := qt.New(t)
+	privKey, pubKey, err := GenerateKey()
+	c.Assert(err, qt.IsNil)
+	k, err := RandomK()
+	c.Assert(err, qt.IsNil)
+	k2, err := RandomK()
+	c.Assert(err, qt.IsNil)
+	msgs := make([]*big.Int, testVectorSize)
+	others := make([]*big.Int, testVectorSize)
+	for i := range msgs {
+		msgs[i] = big.NewInt(int64(i * 10))
+		others[i] = big.NewInt(int64(i + 1))
+	}
+	encrypted, err := NewNativeCiphertexts(testVectorSize).Encrypt(pubKey, k, msgs...)
+	c.Assert(err, qt.IsNil)
+	other, err := NewNativeCiphertexts(testVectorSize).Encrypt(pubKey, k2, others...)
+	c.Assert(err, qt.IsNil)
+	sum, err := NewNativeCiphertexts(testVectorSize).Add(encrypted, other)
+	c.Assert(err, qt.IsNil)
+	// every field is the encryption with the random Poseidon(k, i) and
+	// decrypts to the sum of the messages
+	table := NewDiscreteLogTable(100)
+	for i := range encrypted {
+		ki, err := FieldK(k, i)
+		c.Assert(err, qt.IsNil)
+		c.Assert(encrypted[i].Compress(), qt.Equals, NewNativeCiphertext().Encrypt(pubKey, ki, msgs[i]).Compress())
+		m, err := sum[i].Decrypt(privKey, table)
+		c.Assert(err, qt.IsNil)
+		c.Assert(m.Int64(), qt.Equals, int64(11*i+1))
+	}
+	_, err = NewNativeCiphertexts(testVectorSize).Encrypt(pubKey, k, msgs[1:]...)
+	c.Assert(err, qt.IsNotNil)
+
+	witness := &testCiphertextsCircuit{
+		PubKey:    PointFromNative(pubKey.Point()),
+		K:         k,
+		Sel:       1,
+		Encrypted: CiphertextsFromNative(encrypted),
+		Other:     CiphertextsFromNative(other),
+		Sum:       CiphertextsFromNative(sum),
+	}
+	for i, m := range msgs {
+		witness.Msgs[i] = m
+	}
+	// fields swapped
+	swapped := *witness
+	swapped.Encrypted = CiphertextsFromNative(encrypted)
+	swapped.Encrypted[0], swapped.Encrypted[1] = swapped.Encrypted[1], swapped.Encrypted[0]
+	// other random
+	otherK := *witness
+	otherK.K = k2
+
+	assert := test.NewAssert(t)
+	assert.CheckCircuit(newTestCiphertextsCircuit(),
+		test.WithValidAssignment(witness),
+		test.WithInvalidAssignment(&swapped),
+		test.WithInvalidAssignment(&otherK),
+		test.WithCurves(ecc.BN254),
+		test.WithBackends(backend.GROTH16),
+	)
+}
+
+type testEncryptFieldsCircuit struct {
+	PubKey twistededwards.Point `gnark:",public"`
+	K      frontend.Variable
+	Msgs   []frontend.Variable
+	Naive  bool `gnark:"-"`
+}
+
+func (c *testEncryptFieldsCircuit) Define(api frontend.API) error {
+	if !c.Naive {
+		_, err := NewCiphertexts(len(c.Msgs)).Encrypt(api, c.PubKey, c.K, c.Msgs...)
+		return err
+	}
+	for i, m := range c.Msgs {
+		ki, err := poseidon.Hash(api, c.K, i)
+		if err != nil {
+			return err
+		}
+		if _, err := new(Ciphertext).Encrypt(api, c.PubKey, ki, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestCiphertextsConstraints(t *testing.T) {
+	c := qt.New(t)
+	for _, n := range []int{1, 8, 16} {
+		vector, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder,
+			&testEncryptFieldsCircuit{Msgs: make([]frontend.Variable, n)})
+		c.Assert(err, qt.IsNil)
+		naive, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder,
+			&testEncryptFieldsCircuit{Msgs: make([]frontend.Variable, n), Naive: true})
+		c.Assert(err, qt.IsNil)
+		fmt.Println("fields", n, "vector constrains", vector.GetNbConstraints(), "naive constrains", naive.GetNbConstraints())
+		if n > 1 {
+			c.Assert(vector.GetNbConstraints() < naive.GetNbConstraints(), qt.IsTrue)
+		}
+	}
+}
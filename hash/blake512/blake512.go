@@ -0,0 +1,152 @@
+// blake512 package contains the implementation of the BLAKE-512 hash function
+// (the SHA-3 finalist, not BLAKE2b) in Gnark, compatible with
+// github.com/dchest/blake512, which is the implementation that Iden3 uses to
+// derive the BabyJubJub scalars from the private keys.
+//
+// The length of the message is fixed at circuit compile time, so the padding
+// and the counters of every block are constants and only the compression
+// function is computed in the circuit, using 64-bit words.
+//
+// Read more about this here: https://www.aumasson.jp/blake/blake.pdf
+package blake512
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/uints"
+	"github.com/vocdoni/gnark-crypto-primitives/utils"
+)
+
+const (
+	// Size is the size of a BLAKE-512 digest in bytes.
+	Size = 64
+	// BlockSize is the block size of BLAKE-512 in bytes.
+	BlockSize = 128
+	// rounds is the number of rounds of the compression function.
+	rounds = 16
+)
+
+var (
+	// iv is the initial chain value, the same as SHA-512.
+	iv = [8]uint64{
+		0x6A09E667F3BCC908, 0xBB67AE8584CAA73B, 0x3C6EF372FE94F82B, 0xA54FF53A5F1D36F1,
+		0x510E527FADE682D1, 0x9B05688C2B3E6C1F, 0x1F83D9ABFB41BD6B, 0x5BE0CD19137E2179,
+	}
+	// cst are the constants of the compression function, the first digits
+	// of pi.
+	cst = [16]uint64{
+		0x243F6A8885A308D3, 0x13198A2E03707344, 0xA4093822299F31D0, 0x082EFA98EC4E6C89,
+		0x452821E638D01377, 0xBE5466CF34E90C6C, 0xC0AC29B7C97C50DD, 0x3F84D5B5B5470917,
+		0x9216D5D98979FB1B, 0xD1310BA698DFB5AC, 0x2FFD72DBD01ADFB7, 0xB8E1AFED6A267E96,
+		0xBA7C9045F12C7F99, 0x24A19947B3916CF7, 0x0801F2E2858EFC16, 0x636920D871574E69,
+	}
+	// sigma are the permutations of the message words of every round.
+	sigma = [10][16]int{
+		{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+		{14, 10, 4, 8, 9, 15, 13, 6, 1, 12, 0, 2, 11, 7, 5, 3},
+		{11, 8, 12, 0, 5, 2, 15, 13, 10, 14, 3, 6, 7, 1, 9, 4},
+		{7, 9, 3, 1, 13, 12, 11, 14, 2, 6, 5, 10, 4, 0, 15, 8},
+		{9, 0, 5, 7, 2, 4, 10, 15, 14, 1, 11, 12, 6, 8, 3, 13},
+		{2, 12, 6, 10, 0, 11, 8, 3, 4, 13, 7, 5, 15, 14, 1, 9},
+		{12, 5, 1, 15, 14, 13, 4, 10, 0, 7, 6, 3, 9, 2, 8, 11},
+		{13, 11, 7, 14, 12, 1, 3, 9, 5, 0, 15, 4, 8, 6, 2, 10},
+		{6, 15, 14, 9, 11, 3, 0, 8, 12, 2, 13, 7, 1, 4, 10, 5},
+		{10, 2, 8, 4, 7, 6, 1, 5, 15, 11, 9, 14, 3, 12, 13, 0},
+	}
+	// gIndexes are the indexes of the state words that every G function of a
+	// round mixes, first the columns and then the diagonals.
+	gIndexes = [8][4]int{
+		{0, 4, 8, 12}, {1, 5, 9, 13}, {2, 6, 10, 14}, {3, 7, 11, 15},
+		{0, 5, 10, 15}, {1, 6, 11, 12}, {2, 7, 8, 13}, {3, 4, 9, 14},
+	}
+)
+
+// Sum returns the BLAKE-512 digest of msg, as a 64-byte slice.
+func Sum(api frontend.API, msg utils.Bytes) (utils.Bytes, error) {
+	uapi, err := uints.New[uints.U64](api)
+	if err != nil {
+		return nil, err
+	}
+	padded := append(append(utils.Bytes{}, msg...), uints.NewU8Array(padding(len(msg)))...)
+	msgBits := uint64(len(msg)) * 8
+
+	h := uints.NewU64Array(iv[:])
+	for b := 0; b < len(padded)/BlockSize; b++ {
+		block := padded[b*BlockSize : (b+1)*BlockSize]
+		// the counter is the number of message bits up to the end of the
+		// block, or zero if the block contains only padding
+		var t uint64
+		if b*BlockSize < len(msg) {
+			t = min(msgBits, uint64(b+1)*BlockSize*8)
+		}
+		h = compress(uapi, h, block, t)
+	}
+	digest := make(utils.Bytes, 0, Size)
+	for _, w := range h {
+		digest = append(digest, uapi.UnpackMSB(w)...)
+	}
+	return digest, nil
+}
+
+// compress applies the compression function to the block with the chain
+// value h and the counter t, and returns the new chain value. The salt is
+// always zero.
+func compress(uapi *uints.BinaryField[uints.U64], h []uints.U64, block utils.Bytes, t uint64) []uints.U64 {
+	var m [16]uints.U64
+	for i := range m {
+		m[i] = uapi.PackMSB(block[i*8 : (i+1)*8]...)
+	}
+	// v = h ‖ cst[0..3] ‖ (cst[4..5] ⊕ t) ‖ cst[6..7], the high word of the
+	// 128-bit counter is always zero
+	var v [16]uints.U64
+	copy(v[:8], h)
+	for i := range 8 {
+		c := cst[i]
+		if i == 4 || i == 5 {
+			c ^= t
+		}
+		v[8+i] = uints.NewU64(c)
+	}
+	for r := range rounds {
+		s := sigma[r%10]
+		for i, idx := range gIndexes {
+			a, b, c, d := idx[0], idx[1], idx[2], idx[3]
+			v[a] = uapi.Add(v[a], v[b], uapi.Xor(m[s[2*i]], uints.NewU64(cst[s[2*i+1]])))
+			v[d] = rotr(uapi, uapi.Xor(v[d], v[a]), 32)
+			v[c] = uapi.Add(v[c], v[d])
+			v[b] = rotr(uapi, uapi.Xor(v[b], v[c]), 25)
+			v[a] = uapi.Add(v[a], v[b], uapi.Xor(m[s[2*i+1]], uints.NewU64(cst[s[2*i]])))
+			v[d] = rotr(uapi, uapi.Xor(v[d], v[a]), 16)
+			v[c] = uapi.Add(v[c], v[d])
+			v[b] = rotr(uapi, uapi.Xor(v[b], v[c]), 11)
+		}
+	}
+	res := make([]uints.U64, 8)
+	for i := range res {
+		res[i] = uapi.Xor(h[i], v[i], v[i+8])
+	}
+	return res
+}
+
+// rotr rotates the word a to the right by c bits.
+func rotr(uapi *uints.BinaryField[uints.U64], a uints.U64, c int) uints.U64 {
+	return uapi.Lrot(a, 64-c)
+}
+
+// padding returns the padding bytes for a message of msgLen bytes: a one
+// bit, zeros, a one bit and the 128-bit big-endian length of the message in
+// bits, up to a multiple of the block size.
+func padding(msgLen int) []byte {
+	// 1 byte for the first bit and 16 bytes for the length
+	padLen := BlockSize - (msgLen+17)%BlockSize + 17
+	if padLen > BlockSize+16 {
+		padLen -= BlockSize
+	}
+	pad := make([]byte, padLen)
+	pad[0] = 0x80
+	pad[padLen-17] |= 0x01
+	msgBits := uint64(msgLen) * 8
+	for i := range 8 {
+		pad[padLen-1-i] = byte(msgBits >> (8 * i))
+	}
+	return pad
+}
@@ -0,0 +1,83 @@
+package blake512
+
+import (
+	"crypto/rand"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/profile"
+	"github.com/consensys/gnark/std/math/uints"
+	"github.com/consensys/gnark/test"
+	qt "github.com/frankban/quicktest"
+	"github.com/iden3/go-iden3-crypto/babyjub"
+	"github.com/vocdoni/gnark-crypto-primitives/utils"
+)
+
+type testBlake512Circuit struct {
+	Msg    utils.Bytes
+	Digest [Size]uints.U8 `gnark:",public"`
+}
+
+func (c *testBlake512Circuit) Define(api frontend.API) error {
+	digest, err := Sum(api, c.Msg)
+	if err != nil {
+		return err
+	}
+	digest.AssertIsEqual(api, c.Digest[:])
+	return nil
+}
+
+// testBlake512Assignment returns the circuit and a witness for a random
+// message of msgLen bytes and its digest, calculated with the Iden3 helper.
+func testBlake512Assignment(msgLen int) (*testBlake512Circuit, *testBlake512Circuit, error) {
+	msg := make([]byte, msgLen)
+	if _, err := rand.Read(msg); err != nil {
+		return nil, nil, err
+	}
+	circuit := &testBlake512Circuit{Msg: make(utils.Bytes, msgLen)}
+	witness := &testBlake512Circuit{
+		Msg:    utils.BytesFromSlice(msg, msgLen),
+		Digest: [Size]uints.U8(utils.BytesFromSlice(babyjub.Blake512(msg), Size)),
+	}
+	return circuit, witness, nil
+}
+
+func TestBlake512(t *testing.T) {
+	c := qt.New(t)
+	// profiling the circuit compilation for a private key
+	circuit, witness, err := testBlake512Assignment(32)
+	c.Assert(err, qt.IsNil)
+	p := profile.Start()
+	now := time.Now()
+	_, _ = frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, circuit)
+	fmt.Println("elapsed", time.Since(now))
+	p.Stop()
+	fmt.Println("constrains", p.NbConstraints())
+	// a different digest must fail
+	invalid := *witness
+	invalid.Digest[0] = uints.NewU8(witness.Digest[0].Val.(uint8) ^ 0xff)
+
+	assert := test.NewAssert(t)
+	assert.CheckCircuit(circuit,
+		test.WithValidAssignment(witness),
+		test.WithInvalidAssignment(&invalid),
+		test.WithCurves(ecc.BN254),
+		test.WithBackends(backend.GROTH16),
+	)
+}
+
+func TestBlake512Padding(t *testing.T) {
+	c := qt.New(t)
+	// lengths that cover the empty message, the single padding byte and the
+	// padding in a block without message bits
+	for _, msgLen := range []int{0, 1, 111, 112, 128, 200} {
+		circuit, witness, err := testBlake512Assignment(msgLen)
+		c.Assert(err, qt.IsNil)
+		c.Assert(test.IsSolved(circuit, witness, ecc.BN254.ScalarField()), qt.IsNil, qt.Commentf("length %d", msgLen))
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/vocdoni/gnark-crypto-primitives/hash"
 	"github.com/vocdoni/gnark-crypto-primitives/hash/native/bn254/mimc7"
 	"github.com/vocdoni/gnark-crypto-primitives/hash/native/bn254/poseidon"
+	"github.com/vocdoni/gnark-crypto-primitives/hash/native/mimc"
 )
 
 // MiMC7 returns a new instance of the MiMC7 hash function to be used in
@@ -18,3 +19,10 @@ func MiMC7(api frontend.API) (hash.Hash[frontend.Variable], error) {
 func Poseidon(api frontend.API) (hash.Hash[frontend.Variable], error) {
 	return poseidon.New(api)
 }
+
+// MiMC returns a new instance of the gnark MiMC hash function for the native
+// field of the circuit curve, compatible with the gnark-crypto MiMC of the
+// same field.
+func MiMC(api frontend.API) (hash.Hash[frontend.Variable], error) {
+	return mimc.New(api)
+}
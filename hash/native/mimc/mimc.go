@@ -0,0 +1,64 @@
+// mimc package wraps the gnark MiMC hash function to implement the common
+// hash.Hash interface. Unlike MiMC7, it is defined for every curve supported
+// by gnark (BN254, BLS12-377, BLS12-381, BW6-761...), using the parameters of
+// the circuit native field, so it is compatible with the gnark-crypto MiMC
+// implementation of the same field.
+package mimc
+
+import (
+	"github.com/consensys/gnark/frontend"
+	gmimc "github.com/consensys/gnark/std/hash/mimc"
+)
+
+// MiMC struct wraps the gnark MiMC hash function and keeps track of the
+// number of inputs written.
+type MiMC struct {
+	api    frontend.API
+	h      gmimc.MiMC
+	inputs int
+}
+
+// New returns a new MiMC object for the native field of the circuit.
+func New(api frontend.API) (*MiMC, error) {
+	h, err := gmimc.NewMiMC(api)
+	if err != nil {
+		return nil, err
+	}
+	return &MiMC{api: api, h: h}, nil
+}
+
+// Write adds the provided inputs to the hash state.
+func (h *MiMC) Write(data ...frontend.Variable) {
+	h.h.Write(data...)
+	h.inputs += len(data)
+}
+
+// Reset resets the hash state, removing all written inputs.
+func (h *MiMC) Reset() {
+	h.h.Reset()
+	h.inputs = 0
+}
+
+// Sum returns the hash of the inputs written.
+func (h *MiMC) Sum() frontend.Variable {
+	return h.h.Sum()
+}
+
+// WriteSucceeded returns true if at least one input has been written.
+func (h *MiMC) WriteSucceeded() bool {
+	return h.inputs > 0
+}
+
+// SumIsEqual returns 1 if the hash of the inputs written is equal to the
+// expected value, 0 otherwise.
+func (h *MiMC) SumIsEqual(expected frontend.Variable) frontend.Variable {
+	res := h.Sum()
+	return h.api.IsZero(h.api.Sub(res, expected))
+}
+
+// AssertSumIsEqual asserts that the hash of the inputs written is equal to
+// the expected value.
+func (h *MiMC) AssertSumIsEqual(expected frontend.Variable) {
+	flag := h.SumIsEqual(expected)
+	h.api.AssertIsEqual(flag, 1)
+}
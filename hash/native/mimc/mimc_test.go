@@ -0,0 +1,54 @@
+package mimc
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr/mimc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	qt "github.com/frankban/quicktest"
+	"github.com/vocdoni/davinci-node/util"
+)
+
+type testMiMCCircuit struct {
+	Hash      frontend.Variable `gnark:",public"`
+	Preimages [2]frontend.Variable
+}
+
+func (circuit *testMiMCCircuit) Define(api frontend.API) error {
+	h, err := New(api)
+	if err != nil {
+		return err
+	}
+	h.Write(circuit.Preimages[:]...)
+	h.AssertSumIsEqual(circuit.Hash)
+	return nil
+}
+
+func TestMiMC(t *testing.T) {
+	c := qt.New(t)
+	// hash two random inputs with the gnark-crypto BLS12-377 MiMC
+	var inputs [2]frontend.Variable
+	native := mimc.NewMiMC()
+	for i := range inputs {
+		var e fr.Element
+		e.SetBigInt(new(big.Int).SetBytes(util.RandomBytes(31)))
+		b := e.Bytes()
+		_, err := native.Write(b[:])
+		c.Assert(err, qt.IsNil)
+		inputs[i] = e.BigInt(new(big.Int))
+	}
+	hash := new(big.Int).SetBytes(native.Sum(nil))
+
+	assert := test.NewAssert(t)
+	assert.CheckCircuit(&testMiMCCircuit{},
+		test.WithValidAssignment(&testMiMCCircuit{Hash: hash, Preimages: inputs}),
+		test.WithInvalidAssignment(&testMiMCCircuit{Hash: new(big.Int).Add(hash, big.NewInt(1)), Preimages: inputs}),
+		test.WithCurves(ecc.BLS12_377),
+		test.WithBackends(backend.GROTH16),
+	)
+}
@@ -0,0 +1,134 @@
+// sha512 package contains the implementation of the SHA-512 hash function
+// (FIPS 180-4) in Gnark, compatible with crypto/sha512.
+//
+// The length of the message is fixed at circuit compile time, so the padding
+// is constant and only the compression function is computed in the circuit,
+// using 64-bit words.
+package sha512
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/uints"
+	"github.com/vocdoni/gnark-crypto-primitives/utils"
+)
+
+const (
+	// Size is the size of a SHA-512 digest in bytes.
+	Size = 64
+	// BlockSize is the block size of SHA-512 in bytes.
+	BlockSize = 128
+	// rounds is the number of rounds of the compression function.
+	rounds = 80
+)
+
+var (
+	// iv is the initial hash value.
+	iv = [8]uint64{
+		0x6A09E667F3BCC908, 0xBB67AE8584CAA73B, 0x3C6EF372FE94F82B, 0xA54FF53A5F1D36F1,
+		0x510E527FADE682D1, 0x9B05688C2B3E6C1F, 0x1F83D9ABFB41BD6B, 0x5BE0CD19137E2179,
+	}
+	// k are the round constants, the first 64 bits of the fractional parts of
+	// the cube roots of the first 80 primes.
+	k = [rounds]uint64{
+		0x428A2F98D728AE22, 0x7137449123EF65CD, 0xB5C0FBCFEC4D3B2F, 0xE9B5DBA58189DBBC,
+		0x3956C25BF348B538, 0x59F111F1B605D019, 0x923F82A4AF194F9B, 0xAB1C5ED5DA6D8118,
+		0xD807AA98A3030242, 0x12835B0145706FBE, 0x243185BE4EE4B28C, 0x550C7DC3D5FFB4E2,
+		0x72BE5D74F27B896F, 0x80DEB1FE3B1696B1, 0x9BDC06A725C71235, 0xC19BF174CF692694,
+		0xE49B69C19EF14AD2, 0xEFBE4786384F25E3, 0x0FC19DC68B8CD5B5, 0x240CA1CC77AC9C65,
+		0x2DE92C6F592B0275, 0x4A7484AA6EA6E483, 0x5CB0A9DCBD41FBD4, 0x76F988DA831153B5,
+		0x983E5152EE66DFAB, 0xA831C66D2DB43210, 0xB00327C898FB213F, 0xBF597FC7BEEF0EE4,
+		0xC6E00BF33DA88FC2, 0xD5A79147930AA725, 0x06CA6351E003826F, 0x142929670A0E6E70,
+		0x27B70A8546D22FFC, 0x2E1B21385C26C926, 0x4D2C6DFC5AC42AED, 0x53380D139D95B3DF,
+		0x650A73548BAF63DE, 0x766A0ABB3C77B2A8, 0x81C2C92E47EDAEE6, 0x92722C851482353B,
+		0xA2BFE8A14CF10364, 0xA81A664BBC423001, 0xC24B8B70D0F89791, 0xC76C51A30654BE30,
+		0xD192E819D6EF5218, 0xD69906245565A910, 0xF40E35855771202A, 0x106AA07032BBD1B8,
+		0x19A4C116B8D2D0C8, 0x1E376C085141AB53, 0x2748774CDF8EEB99, 0x34B0BCB5E19B48A8,
+		0x391C0CB3C5C95A63, 0x4ED8AA4AE3418ACB, 0x5B9CCA4F7763E373, 0x682E6FF3D6B2B8A3,
+		0x748F82EE5DEFB2FC, 0x78A5636F43172F60, 0x84C87814A1F0AB72, 0x8CC702081A6439EC,
+		0x90BEFFFA23631E28, 0xA4506CEBDE82BDE9, 0xBEF9A3F7B2C67915, 0xC67178F2E372532B,
+		0xCA273ECEEA26619C, 0xD186B8C721C0C207, 0xEADA7DD6CDE0EB1E, 0xF57D4F7FEE6ED178,
+		0x06F067AA72176FBA, 0x0A637DC5A2C898A6, 0x113F9804BEF90DAE, 0x1B710B35131C471B,
+		0x28DB77F523047D84, 0x32CAAB7B40C72493, 0x3C9EBE0A15C9BEBC, 0x431D67C49C100D4C,
+		0x4CC5D4BECB3E42B6, 0x597F299CFC657E2A, 0x5FCB6FAB3AD6FAEC, 0x6C44198C4A475817,
+	}
+)
+
+// Sum returns the SHA-512 digest of msg, as a 64-byte slice.
+func Sum(api frontend.API, msg utils.Bytes) (utils.Bytes, error) {
+	uapi, err := uints.New[uints.U64](api)
+	if err != nil {
+		return nil, err
+	}
+	padded := append(append(utils.Bytes{}, msg...), uints.NewU8Array(padding(len(msg)))...)
+
+	h := uints.NewU64Array(iv[:])
+	for b := 0; b < len(padded)/BlockSize; b++ {
+		h = compress(uapi, h, padded[b*BlockSize:(b+1)*BlockSize])
+	}
+	digest := make(utils.Bytes, 0, Size)
+	for _, w := range h {
+		digest = append(digest, uapi.UnpackMSB(w)...)
+	}
+	return digest, nil
+}
+
+// compress applies the compression function to the block with the hash
+// value h, and returns the new hash value.
+func compress(uapi *uints.BinaryField[uints.U64], h []uints.U64, block utils.Bytes) []uints.U64 {
+	// message schedule
+	var w [rounds]uints.U64
+	for i := range 16 {
+		w[i] = uapi.PackMSB(block[i*8 : (i+1)*8]...)
+	}
+	for i := 16; i < rounds; i++ {
+		// σ0 = rotr(w, 1) ⊕ rotr(w, 8) ⊕ shr(w, 7)
+		s0 := uapi.Xor(rotr(uapi, w[i-15], 1), rotr(uapi, w[i-15], 8), uapi.Rshift(w[i-15], 7))
+		// σ1 = rotr(w, 19) ⊕ rotr(w, 61) ⊕ shr(w, 6)
+		s1 := uapi.Xor(rotr(uapi, w[i-2], 19), rotr(uapi, w[i-2], 61), uapi.Rshift(w[i-2], 6))
+		w[i] = uapi.Add(w[i-16], s0, w[i-7], s1)
+	}
+	a, b, c, d, e, f, g, hh := h[0], h[1], h[2], h[3], h[4], h[5], h[6], h[7]
+	for i := range rounds {
+		// Σ1 = rotr(e, 14) ⊕ rotr(e, 18) ⊕ rotr(e, 41)
+		S1 := uapi.Xor(rotr(uapi, e, 14), rotr(uapi, e, 18), rotr(uapi, e, 41))
+		// ch = (e ∧ f) ⊕ (¬e ∧ g)
+		ch := uapi.Xor(uapi.And(e, f), uapi.And(uapi.Not(e), g))
+		t1 := uapi.Add(hh, S1, ch, uints.NewU64(k[i]), w[i])
+		// Σ0 = rotr(a, 28) ⊕ rotr(a, 34) ⊕ rotr(a, 39)
+		S0 := uapi.Xor(rotr(uapi, a, 28), rotr(uapi, a, 34), rotr(uapi, a, 39))
+		// maj = (a ∧ b) ⊕ (a ∧ c) ⊕ (b ∧ c)
+		maj := uapi.Xor(uapi.And(a, b), uapi.And(a, c), uapi.And(b, c))
+		t2 := uapi.Add(S0, maj)
+		hh, g, f = g, f, e
+		e = uapi.Add(d, t1)
+		d, c, b = c, b, a
+		a = uapi.Add(t1, t2)
+	}
+	return []uints.U64{
+		uapi.Add(h[0], a), uapi.Add(h[1], b), uapi.Add(h[2], c), uapi.Add(h[3], d),
+		uapi.Add(h[4], e), uapi.Add(h[5], f), uapi.Add(h[6], g), uapi.Add(h[7], hh),
+	}
+}
+
+// rotr rotates the word a to the right by c bits.
+func rotr(uapi *uints.BinaryField[uints.U64], a uints.U64, c int) uints.U64 {
+	return uapi.Lrot(a, 64-c)
+}
+
+// padding returns the padding bytes for a message of msgLen bytes: a one
+// bit, zeros and the 128-bit big-endian length of the message in bits, up to
+// a multiple of the block size.
+func padding(msgLen int) []byte {
+	// 1 byte for the first bit and 16 bytes for the length
+	padLen := BlockSize - (msgLen+17)%BlockSize + 17
+	if padLen > BlockSize+16 {
+		padLen -= BlockSize
+	}
+	pad := make([]byte, padLen)
+	pad[0] = 0x80
+	msgBits := uint64(msgLen) * 8
+	for i := range 8 {
+		pad[padLen-1-i] = byte(msgBits >> (8 * i))
+	}
+	return pad
+}
@@ -0,0 +1,24 @@
+package rsa
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark/constraint/solver"
+	"github.com/consensys/gnark/std/math/emulated"
+)
+
+func init() { solver.RegisterHint(EncryptHint) }
+
+// EncryptHint computes the RSA public operation s^65537 mod n, where in[0]
+// is the signature s and in[1] is the modulus n. It returns the encoded
+// message of the signature.
+var EncryptHint solver.Hint = func(nativeMod *big.Int, in, out []*big.Int) error {
+	return emulated.UnwrapHint(in, out, func(_ *big.Int, in, out []*big.Int) error {
+		if in[1].Sign() == 0 {
+			return fmt.Errorf("invalid zero modulus")
+		}
+		out[0].Exp(in[0], big.NewInt(PublicExponent), in[1])
+		return nil
+	})
+}
@@ -0,0 +1,34 @@
+package rsa
+
+import (
+	"crypto/rsa"
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark/std/math/emulated"
+)
+
+// PublicKeyFromNative converts a crypto/rsa public key into a PublicKey
+// assignment. The public exponent must be 65537 and the modulus must have
+// the size defined by T.
+func PublicKeyFromNative[T emulated.FieldParams](pk *rsa.PublicKey) (PublicKey[T], error) {
+	if pk == nil || pk.N == nil {
+		return PublicKey[T]{}, fmt.Errorf("invalid public key")
+	}
+	if pk.E != PublicExponent {
+		return PublicKey[T]{}, fmt.Errorf("unsupported public exponent: %d", pk.E)
+	}
+	if bitLen := modulusLen[T]() * 8; pk.N.BitLen() != bitLen {
+		return PublicKey[T]{}, fmt.Errorf("invalid modulus size: %d bits, expected %d", pk.N.BitLen(), bitLen)
+	}
+	return PublicKey[T]{N: emulated.ValueOf[T](pk.N)}, nil
+}
+
+// SignatureFromNative converts a PKCS #1 v1.5 or PSS signature, as returned
+// by crypto/rsa, into a Signature assignment.
+func SignatureFromNative[T emulated.FieldParams](sig []byte) (Signature[T], error) {
+	if len(sig) != modulusLen[T]() {
+		return Signature[T]{}, fmt.Errorf("invalid signature length: %d", len(sig))
+	}
+	return Signature[T]{S: emulated.ValueOf[T](new(big.Int).SetBytes(sig))}, nil
+}
@@ -0,0 +1,23 @@
+package rsa
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark/std/math/emulated/emparams"
+)
+
+// Mod2048 provides type parametrization for the emulated arithmetic with
+// RSA-2048 moduli, with 32 limbs of 64 bits. The modulus of the type is
+// 2^2048-1, but the operations are done modulo the modulus of the public key.
+type Mod2048 struct{}
+
+func (Mod2048) NbLimbs() uint     { return 32 }
+func (Mod2048) BitsPerLimb() uint { return 64 }
+func (Mod2048) IsPrime() bool     { return false }
+func (Mod2048) Modulus() *big.Int {
+	return new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 2048), big.NewInt(1))
+}
+
+// Mod4096 provides type parametrization for the emulated arithmetic with
+// RSA-4096 moduli, with 64 limbs of 64 bits.
+type Mod4096 = emparams.Mod1e4096
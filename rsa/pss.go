@@ -0,0 +1,125 @@
+package rsa
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/bits"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/std/math/uints"
+	"github.com/vocdoni/gnark-crypto-primitives/utils"
+)
+
+// VerifyPSS asserts that sig is a valid PSS signature of the SHA-256 digest
+// of msg by the public key, using MGF1 with SHA-256 and a salt of saltLen
+// bytes. The salt length is fixed when the circuit is compiled, signatures
+// generated with rsa.PSSSaltLengthEqualsHash use HashSize.
+func (pk PublicKey[T]) VerifyPSS(api frontend.API, sig *Signature[T], msg utils.Bytes, saltLen int) error {
+	digest, err := hash(api, msg)
+	if err != nil {
+		return err
+	}
+	return pk.VerifyPSSDigest(api, sig, digest, saltLen)
+}
+
+// VerifyPSSDigest asserts that sig is a valid PSS signature of the provided
+// SHA-256 digest by the public key, with a salt of saltLen bytes. The
+// encoded message is provided by a hint, it is checked to be the signature
+// raised to the public exponent and then decoded in the circuit following
+// the EMSA-PSS verification of RFC 8017.
+func (pk PublicKey[T]) VerifyPSSDigest(api frontend.API, sig *Signature[T], digest utils.Bytes, saltLen int) error {
+	if len(digest) != HashSize {
+		return fmt.Errorf("invalid digest length: %d", len(digest))
+	}
+	if saltLen < 0 {
+		return fmt.Errorf("invalid salt length: %d", saltLen)
+	}
+	f, err := emulated.NewField[T](api)
+	if err != nil {
+		return fmt.Errorf("error initializing the emulated field: %w", err)
+	}
+	// the modulus has exactly 8·k bits, so the encoded message has k bytes
+	// and its most significant bit must be zero
+	k := modulusLen[T]()
+	if k < HashSize+saltLen+2 {
+		return fmt.Errorf("modulus too short for the salt length %d", saltLen)
+	}
+	m := pk.encrypt(api, f, sig)
+	res, err := f.NewHint(EncryptHint, 1, &sig.S, &pk.N)
+	if err != nil {
+		return fmt.Errorf("error computing the encoded message: %w", err)
+	}
+	emBits := f.ToBits(res[0])
+	api.AssertIsEqual(emBits[len(emBits)-1], 0)
+	// the encoded message is lower than 2^(8·k-1) and then lower than the
+	// modulus, so it is the unique value equal to m modulo N
+	f.ModAssertIsEqual(m, res[0], &pk.N)
+	// em[i] returns the bits of the i-th byte of the encoded message
+	em := func(i int) []frontend.Variable {
+		return emBits[(k-1-i)*8 : (k-i)*8]
+	}
+	// the last byte must be 0xbc
+	assertByteBits(api, em(k-1), 0xbc)
+	// split the encoded message into maskedDB ‖ H ‖ 0xbc
+	dbLen := k - HashSize - 1
+	h := make(utils.Bytes, HashSize)
+	for i := range h {
+		h[i] = uints.U8{Val: bits.FromBinary(api, em(dbLen+i))}
+	}
+	mask, err := mgf1(api, h, dbLen)
+	if err != nil {
+		return err
+	}
+	// DB = maskedDB xor MGF1(H), clearing its most significant bit, must be
+	// PS ‖ 0x01 ‖ salt, where PS is a sequence of zeros
+	psLen := dbLen - saltLen - 1
+	salt := make(utils.Bytes, 0, saltLen)
+	for i := range dbLen {
+		maskBits := bits.ToBinary(api, mask[i].Val, bits.WithNbDigits(8))
+		db := make([]frontend.Variable, 8)
+		for j, b := range em(i) {
+			db[j] = api.Xor(b, maskBits[j])
+		}
+		if i == 0 {
+			db[7] = 0
+		}
+		switch {
+		case i < psLen:
+			assertByteBits(api, db, 0x00)
+		case i == psLen:
+			assertByteBits(api, db, 0x01)
+		default:
+			salt = append(salt, uints.U8{Val: bits.FromBinary(api, db)})
+		}
+	}
+	// H must be SHA-256(0x00 * 8 ‖ digest ‖ salt)
+	expected, err := hash(api, utils.BytesFromSlice(make([]byte, 8), 8), digest, salt)
+	if err != nil {
+		return err
+	}
+	h.AssertIsEqual(api, expected)
+	return nil
+}
+
+// mgf1 returns the first length bytes of the MGF1 mask generation function
+// of the seed with SHA-256.
+func mgf1(api frontend.API, seed utils.Bytes, length int) (utils.Bytes, error) {
+	res := make(utils.Bytes, 0, length+HashSize)
+	for counter := 0; len(res) < length; counter++ {
+		c := []byte{byte(counter >> 24), byte(counter >> 16), byte(counter >> 8), byte(counter)}
+		block, err := hash(api, seed, utils.BytesFromSlice(c, len(c)))
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, block...)
+	}
+	return res[:length], nil
+}
+
+// assertByteBits asserts that the bits of a byte, starting from the least
+// significant one, are equal to the bits of the constant b.
+func assertByteBits(api frontend.API, bs []frontend.Variable, b byte) {
+	for i, v := range bs {
+		api.AssertIsEqual(v, (b>>i)&1)
+	}
+}
@@ -0,0 +1,146 @@
+// rsa package implements the verification of RSA signatures in gnark using
+// the emulated arithmetic with a variable modulus, to support credentials
+// signed with RSA keys such as DKIM signatures of emails or RS256 JSON Web
+// Tokens. Only the public exponent 65537 and SHA-256 digests are supported,
+// with the PKCS #1 v1.5 and PSS encodings, matching crypto/rsa.VerifyPKCS1v15
+// and crypto/rsa.VerifyPSS.
+//
+// The size of the modulus is defined by the type parameter (Mod2048 or
+// Mod4096) and the circuit complexity depends on it, not on the actual
+// modulus. The public key modulus must have exactly that size, which is
+// checked in the circuit.
+package rsa
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash/sha2"
+	"github.com/consensys/gnark/std/math/bits"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/vocdoni/gnark-crypto-primitives/utils"
+)
+
+const (
+	// PublicExponent is the only public exponent supported by the verifier.
+	PublicExponent = 65537
+	// HashSize is the size of the SHA-256 digests in bytes.
+	HashSize = sha256.Size
+)
+
+// sha256Prefix is the DER encoding of the DigestInfo of a SHA-256 digest,
+// without the digest, as defined in RFC 8017.
+var sha256Prefix = []byte{
+	0x30, 0x31, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03,
+	0x04, 0x02, 0x01, 0x05, 0x00, 0x04, 0x20,
+}
+
+// PublicKey represents a RSA public key with the exponent 65537 and a
+// modulus N of the size defined by T.
+type PublicKey[T emulated.FieldParams] struct {
+	N emulated.Element[T]
+}
+
+// Signature represents a RSA signature as an integer S, that must be lower
+// than the modulus of the public key.
+type Signature[T emulated.FieldParams] struct {
+	S emulated.Element[T]
+}
+
+// VerifyPKCS1v15 asserts that sig is a valid PKCS #1 v1.5 signature of the
+// SHA-256 digest of msg by the public key.
+func (pk PublicKey[T]) VerifyPKCS1v15(api frontend.API, sig *Signature[T], msg utils.Bytes) error {
+	digest, err := hash(api, msg)
+	if err != nil {
+		return err
+	}
+	return pk.VerifyPKCS1v15Digest(api, sig, digest)
+}
+
+// VerifyPKCS1v15Digest asserts that sig is a valid PKCS #1 v1.5 signature of
+// the provided SHA-256 digest by the public key. The encoded message
+// 0x00 ‖ 0x01 ‖ 0xff... ‖ 0x00 ‖ DigestInfo ‖ digest is composed in the
+// circuit and compared with the signature raised to the public exponent.
+func (pk PublicKey[T]) VerifyPKCS1v15Digest(api frontend.API, sig *Signature[T], digest utils.Bytes) error {
+	if len(digest) != HashSize {
+		return fmt.Errorf("invalid digest length: %d", len(digest))
+	}
+	f, err := emulated.NewField[T](api)
+	if err != nil {
+		return fmt.Errorf("error initializing the emulated field: %w", err)
+	}
+	k := modulusLen[T]()
+	tLen := len(sha256Prefix) + HashSize
+	if k < tLen+11 {
+		return fmt.Errorf("modulus too short for the encoded message")
+	}
+	// compose the expected encoded message, the padding is constant
+	em := make(utils.Bytes, 0, k)
+	em = append(em, utils.BytesFromSlice([]byte{0x00, 0x01}, 2)...)
+	for range k - tLen - 3 {
+		em = append(em, utils.BytesFromSlice([]byte{0xff}, 1)...)
+	}
+	em = append(em, utils.BytesFromSlice([]byte{0x00}, 1)...)
+	em = append(em, utils.BytesFromSlice(sha256Prefix, len(sha256Prefix))...)
+	em = append(em, digest...)
+	// the encoded message is lower than the modulus, so the comparison
+	// modulo N is unique
+	m := pk.encrypt(api, f, sig)
+	f.ModAssertIsEqual(m, f.FromBits(bytesToBits(api, em)...), &pk.N)
+	return nil
+}
+
+// encrypt checks that the modulus has the expected size and that the
+// signature is lower than it, and returns S^65537 mod N.
+func (pk PublicKey[T]) encrypt(api frontend.API, f *emulated.Field[T], sig *Signature[T]) *emulated.Element[T] {
+	nBits := f.ToBits(&pk.N)
+	api.AssertIsEqual(nBits[len(nBits)-1], 1)
+	assertIsLess(api, f.ToBits(&sig.S), nBits)
+	// 65537 = 2^16 + 1
+	res := &sig.S
+	for range 16 {
+		res = f.ModMul(res, res, &pk.N)
+	}
+	return f.ModMul(res, &sig.S, &pk.N)
+}
+
+// modulusLen returns the size in bytes of the modulus defined by T.
+func modulusLen[T emulated.FieldParams]() int {
+	var params T
+	return int(params.NbLimbs()*params.BitsPerLimb()) / 8
+}
+
+// hash returns the SHA-256 digest of the data.
+func hash(api frontend.API, data ...utils.Bytes) (utils.Bytes, error) {
+	hasher, err := sha2.New(api)
+	if err != nil {
+		return nil, fmt.Errorf("error initializing the SHA-256 hasher: %w", err)
+	}
+	for _, d := range data {
+		hasher.Write(d)
+	}
+	return hasher.Sum(), nil
+}
+
+// bytesToBits returns the bits of the big-endian integer represented by b,
+// starting from the least significant one.
+func bytesToBits(api frontend.API, b utils.Bytes) []frontend.Variable {
+	res := make([]frontend.Variable, 0, 8*len(b))
+	for i := len(b) - 1; i >= 0; i-- {
+		res = append(res, bits.ToBinary(api, b[i].Val, bits.WithNbDigits(8))...)
+	}
+	return res
+}
+
+// assertIsLess asserts that the integer represented by the bits a is lower
+// than the integer represented by the bits b, both of the same length and
+// starting from the least significant bit.
+func assertIsLess(api frontend.API, a, b []frontend.Variable) {
+	var lt frontend.Variable = 0
+	for i := range a {
+		// the most significant different bit decides the comparison
+		lt = api.Select(api.Xor(a[i], b[i]), b[i], lt)
+	}
+	api.AssertIsEqual(lt, 1)
+}
@@ -0,0 +1,156 @@
+package rsa
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/profile"
+	"github.com/consensys/gnark/std/math/uints"
+	"github.com/consensys/gnark/test"
+	qt "github.com/frankban/quicktest"
+	"github.com/vocdoni/gnark-crypto-primitives/utils"
+)
+
+const testMsgLen = 64
+
+type testPKCS1v15Circuit struct {
+	PublicKey PublicKey[Mod2048]
+	Signature Signature[Mod2048]
+	Message   [testMsgLen]uints.U8
+}
+
+func (c *testPKCS1v15Circuit) Define(api frontend.API) error {
+	return c.PublicKey.VerifyPKCS1v15(api, &c.Signature, c.Message[:])
+}
+
+type testPSSCircuit struct {
+	PublicKey PublicKey[Mod2048]
+	Signature Signature[Mod2048]
+	Message   [testMsgLen]uints.U8
+}
+
+func (c *testPSSCircuit) Define(api frontend.API) error {
+	return c.PublicKey.VerifyPSS(api, &c.Signature, c.Message[:], HashSize)
+}
+
+func printConstraints(c *qt.C, circuit frontend.Circuit) {
+	p := profile.Start()
+	now := time.Now()
+	_, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, circuit)
+	c.Assert(err, qt.IsNil)
+	fmt.Println("elapsed", time.Since(now))
+	p.Stop()
+	fmt.Println("constrains", p.NbConstraints())
+}
+
+func testKeyAndMessage(c *qt.C) (*rsa.PrivateKey, []byte, []byte) {
+	privKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	c.Assert(err, qt.IsNil)
+	msg := make([]byte, testMsgLen)
+	_, err = rand.Read(msg)
+	c.Assert(err, qt.IsNil)
+	digest := sha256.Sum256(msg)
+	return privKey, msg, digest[:]
+}
+
+func TestVerifyPKCS1v15(t *testing.T) {
+	c := qt.New(t)
+	privKey, msg, digest := testKeyAndMessage(c)
+	sig, err := rsa.SignPKCS1v15(rand.Reader, privKey, crypto.SHA256, digest)
+	c.Assert(err, qt.IsNil)
+	c.Assert(rsa.VerifyPKCS1v15(&privKey.PublicKey, crypto.SHA256, digest, sig), qt.IsNil)
+
+	printConstraints(c, &testPKCS1v15Circuit{})
+
+	pubKey, err := PublicKeyFromNative[Mod2048](&privKey.PublicKey)
+	c.Assert(err, qt.IsNil)
+	signature, err := SignatureFromNative[Mod2048](sig)
+	c.Assert(err, qt.IsNil)
+	witness := &testPKCS1v15Circuit{
+		PublicKey: pubKey,
+		Signature: signature,
+		Message:   [testMsgLen]uints.U8(utils.BytesFromSlice(msg, testMsgLen)),
+	}
+	c.Assert(test.IsSolved(&testPKCS1v15Circuit{}, witness, ecc.BN254.ScalarField()), qt.IsNil)
+	// a different message must fail
+	invalidMsg := *witness
+	invalidMsg.Message[0] = uints.NewU8(msg[0] ^ 0xff)
+	c.Assert(test.IsSolved(&testPKCS1v15Circuit{}, &invalidMsg, ecc.BN254.ScalarField()), qt.IsNotNil)
+	// S + N is equal modulo N but must be rejected, like crypto/rsa does
+	// (only when it fits in 2048 bits)
+	invalidS := *witness
+	sPlusN := new(big.Int).Add(new(big.Int).SetBytes(sig), privKey.N)
+	if sPlusN.BitLen() <= 2048 {
+		invalidS.Signature, err = SignatureFromNative[Mod2048](sPlusN.FillBytes(make([]byte, 256)))
+		c.Assert(err, qt.IsNil)
+		c.Assert(test.IsSolved(&testPKCS1v15Circuit{}, &invalidS, ecc.BN254.ScalarField()), qt.IsNotNil)
+	}
+	// a PSS signature must fail
+	pssSig, err := rsa.SignPSS(rand.Reader, privKey, crypto.SHA256, digest, nil)
+	c.Assert(err, qt.IsNil)
+	invalidPadding := *witness
+	invalidPadding.Signature, err = SignatureFromNative[Mod2048](pssSig)
+	c.Assert(err, qt.IsNil)
+	c.Assert(test.IsSolved(&testPKCS1v15Circuit{}, &invalidPadding, ecc.BN254.ScalarField()), qt.IsNotNil)
+}
+
+func TestVerifyPSS(t *testing.T) {
+	c := qt.New(t)
+	privKey, msg, digest := testKeyAndMessage(c)
+	opts := &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}
+	sig, err := rsa.SignPSS(rand.Reader, privKey, crypto.SHA256, digest, opts)
+	c.Assert(err, qt.IsNil)
+	c.Assert(rsa.VerifyPSS(&privKey.PublicKey, crypto.SHA256, digest, sig, opts), qt.IsNil)
+
+	printConstraints(c, &testPSSCircuit{})
+
+	pubKey, err := PublicKeyFromNative[Mod2048](&privKey.PublicKey)
+	c.Assert(err, qt.IsNil)
+	signature, err := SignatureFromNative[Mod2048](sig)
+	c.Assert(err, qt.IsNil)
+	witness := &testPSSCircuit{
+		PublicKey: pubKey,
+		Signature: signature,
+		Message:   [testMsgLen]uints.U8(utils.BytesFromSlice(msg, testMsgLen)),
+	}
+	c.Assert(test.IsSolved(&testPSSCircuit{}, witness, ecc.BN254.ScalarField()), qt.IsNil)
+	// a different message must fail
+	invalidMsg := *witness
+	invalidMsg.Message[0] = uints.NewU8(msg[0] ^ 0xff)
+	c.Assert(test.IsSolved(&testPSSCircuit{}, &invalidMsg, ecc.BN254.ScalarField()), qt.IsNotNil)
+	// a signature with a different salt length must fail
+	shortSalt, err := rsa.SignPSS(rand.Reader, privKey, crypto.SHA256, digest, &rsa.PSSOptions{SaltLength: 20})
+	c.Assert(err, qt.IsNil)
+	invalidSalt := *witness
+	invalidSalt.Signature, err = SignatureFromNative[Mod2048](shortSalt)
+	c.Assert(err, qt.IsNil)
+	c.Assert(test.IsSolved(&testPSSCircuit{}, &invalidSalt, ecc.BN254.ScalarField()), qt.IsNotNil)
+	// a PKCS #1 v1.5 signature must fail
+	pkcsSig, err := rsa.SignPKCS1v15(rand.Reader, privKey, crypto.SHA256, digest)
+	c.Assert(err, qt.IsNil)
+	invalidPadding := *witness
+	invalidPadding.Signature, err = SignatureFromNative[Mod2048](pkcsSig)
+	c.Assert(err, qt.IsNil)
+	c.Assert(test.IsSolved(&testPSSCircuit{}, &invalidPadding, ecc.BN254.ScalarField()), qt.IsNotNil)
+}
+
+func TestNativeHelpers(t *testing.T) {
+	c := qt.New(t)
+	privKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	c.Assert(err, qt.IsNil)
+	_, err = PublicKeyFromNative[Mod2048](&privKey.PublicKey)
+	c.Assert(err, qt.IsNotNil)
+	_, err = PublicKeyFromNative[Mod2048](&rsa.PublicKey{N: new(big.Int).Lsh(big.NewInt(1), 2047), E: 3})
+	c.Assert(err, qt.IsNotNil)
+	_, err = SignatureFromNative[Mod2048](make([]byte, 128))
+	c.Assert(err, qt.IsNotNil)
+}
@@ -0,0 +1,67 @@
+// signature package defines a common interface for the signature schemes
+// supported in the circuits (EdDSA over twisted edwards curves and ECDSA
+// over secp256k1), to write circuits that accept credentials of any of them,
+// and a selector gadget that verifies one of several schemes chosen by a
+// private witness.
+package signature
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark/frontend"
+)
+
+// Verifier is implemented by the credentials of each signature scheme, which
+// contain the public key, the signature and the message to verify (see
+// eddsa.Credential and ecdsa.Credential).
+type Verifier interface {
+	// IsValid returns 1 if the signature is valid for the public key and the
+	// message, 0 otherwise.
+	IsValid() (frontend.Variable, error)
+	// Identity returns the identity of the signer derived from the public
+	// key, such as an address or a hash of the public key.
+	Identity() (frontend.Variable, error)
+}
+
+// Select returns the validity flag and the identity of the verifier at the
+// position selector of the list. The selector can be a private witness, so
+// the scheme used is not revealed, and must be lower than the number of
+// verifiers. The constraints of every verifier are included in the circuit,
+// so the inputs of the verifiers not selected must still satisfy the checks
+// of their schemes (points on the curve, non-zero scalars...), which is the
+// case of any valid signature. The DummyInputs of the eddsa and ecdsa
+// packages are the canonical public placeholders for those inputs.
+func Select(api frontend.API, selector frontend.Variable, verifiers ...Verifier) (frontend.Variable, frontend.Variable, error) {
+	if len(verifiers) == 0 {
+		return nil, nil, fmt.Errorf("no verifiers provided")
+	}
+	var valid, identity, selected frontend.Variable = 0, 0, 0
+	for i, v := range verifiers {
+		isValid, err := v.IsValid()
+		if err != nil {
+			return nil, nil, fmt.Errorf("error verifying the signature %d: %w", i, err)
+		}
+		id, err := v.Identity()
+		if err != nil {
+			return nil, nil, fmt.Errorf("error deriving the identity %d: %w", i, err)
+		}
+		isSelected := api.IsZero(api.Sub(selector, i))
+		valid = api.Add(valid, api.Mul(isSelected, isValid))
+		identity = api.Add(identity, api.Mul(isSelected, id))
+		selected = api.Add(selected, isSelected)
+	}
+	// the selector must match one of the verifiers
+	api.AssertIsEqual(selected, 1)
+	return valid, identity, nil
+}
+
+// Verify asserts that the signature of the verifier at the position selector
+// of the list is valid and returns the identity of the signer. See Select.
+func Verify(api frontend.API, selector frontend.Variable, verifiers ...Verifier) (frontend.Variable, error) {
+	valid, identity, err := Select(api, selector, verifiers...)
+	if err != nil {
+		return nil, err
+	}
+	api.AssertIsEqual(valid, 1)
+	return identity, nil
+}
@@ -0,0 +1,139 @@
+package signature
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/profile"
+	"github.com/consensys/gnark/std/math/emulated"
+	gecdsa "github.com/consensys/gnark/std/signature/ecdsa"
+	"github.com/consensys/gnark/test"
+	"github.com/ethereum/go-ethereum/crypto"
+	qt "github.com/frankban/quicktest"
+	"github.com/iden3/go-iden3-crypto/babyjub"
+	"github.com/iden3/go-iden3-crypto/poseidon"
+	"github.com/vocdoni/davinci-node/util"
+	"github.com/vocdoni/gnark-crypto-primitives/ecc/bn254/eddsa"
+	"github.com/vocdoni/gnark-crypto-primitives/ecc/secp256k1/ecdsa"
+	"github.com/vocdoni/gnark-crypto-primitives/hash/native"
+	"github.com/vocdoni/gnark-crypto-primitives/testutil"
+)
+
+type testSelectorCircuit struct {
+	Identity frontend.Variable `gnark:",public"`
+	Selector frontend.Variable
+	// EdDSA over BabyJubJub
+	EdDSAPublicKey eddsa.PublicKey
+	EdDSASignature eddsa.Signature
+	EdDSAMessage   frontend.Variable
+	// ECDSA over secp256k1
+	ECDSAPublicKey gecdsa.PublicKey[emulated.Secp256k1Fp, emulated.Secp256k1Fr]
+	ECDSASignature gecdsa.Signature[emulated.Secp256k1Fr]
+	ECDSAMessage   emulated.Element[emulated.Secp256k1Fr]
+}
+
+func (c *testSelectorCircuit) Define(api frontend.API) error {
+	hashFn, err := native.Poseidon(api)
+	if err != nil {
+		return err
+	}
+	verifier, err := eddsa.NewVerifier(api, hashFn)
+	if err != nil {
+		return err
+	}
+	identity, err := Verify(api, c.Selector,
+		verifier.Credential(c.EdDSAPublicKey, c.EdDSASignature, c.EdDSAMessage),
+		ecdsa.NewCredential(api, c.ECDSAPublicKey, c.ECDSASignature, c.ECDSAMessage),
+	)
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(identity, c.Identity)
+	return nil
+}
+
+func TestSelector(t *testing.T) {
+	c := qt.New(t)
+	// EdDSA signature
+	privKey := babyjub.NewRandPrivKey()
+	eddsaMsg := new(big.Int).SetBytes(util.RandomBytes(31))
+	eddsaPubKey := eddsa.PublicKeyFromIden3(privKey.Public())
+	eddsaID, err := poseidon.Hash([]*big.Int{privKey.Public().X, privKey.Public().Y})
+	c.Assert(err, qt.IsNil)
+	// ECDSA signature
+	ecdsaMsg := crypto.Keccak256Hash([]byte("hello")).Bytes()
+	ecdsaSig, err := testutil.GenerateAccountAndSign(ecdsaMsg)
+	c.Assert(err, qt.IsNil)
+
+	p := profile.Start()
+	now := time.Now()
+	_, err = frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &testSelectorCircuit{})
+	c.Assert(err, qt.IsNil)
+	fmt.Println("elapsed", time.Since(now))
+	p.Stop()
+	fmt.Println("constrains", p.NbConstraints())
+
+	witness := testSelectorCircuit{
+		EdDSAPublicKey: eddsaPubKey,
+		EdDSASignature: eddsa.SignatureFromIden3(privKey.SignPoseidon(eddsaMsg)),
+		EdDSAMessage:   eddsaMsg,
+		ECDSAPublicKey: gecdsa.PublicKey[emulated.Secp256k1Fp, emulated.Secp256k1Fr]{
+			X: emulated.ValueOf[emulated.Secp256k1Fp](ecdsaSig.PublicKey.X),
+			Y: emulated.ValueOf[emulated.Secp256k1Fp](ecdsaSig.PublicKey.Y),
+		},
+		ECDSASignature: gecdsa.Signature[emulated.Secp256k1Fr]{
+			R: emulated.ValueOf[emulated.Secp256k1Fr](ecdsaSig.R),
+			S: emulated.ValueOf[emulated.Secp256k1Fr](ecdsaSig.S),
+		},
+		ECDSAMessage: emulated.ValueOf[emulated.Secp256k1Fr](ecdsaMsg),
+	}
+	// the public key of the ECDSA placeholders is [2]G, the public key of the
+	// private key 2
+	dummyPrivKey, err := crypto.ToECDSA(big.NewInt(2).FillBytes(make([]byte, 32)))
+	c.Assert(err, qt.IsNil)
+	ecdsaDummyAddress := new(big.Int).SetBytes(crypto.PubkeyToAddress(dummyPrivKey.PublicKey).Bytes())
+
+	field := ecc.BN254.ScalarField()
+	c.Run("eddsa", func(c *qt.C) {
+		w := witness
+		w.Selector, w.Identity = 0, eddsaID
+		c.Assert(test.IsSolved(&testSelectorCircuit{}, &w, field), qt.IsNil)
+		// the identity of the other scheme must fail
+		w.Identity = ecdsaSig.Address
+		c.Assert(test.IsSolved(&testSelectorCircuit{}, &w, field), qt.IsNotNil)
+		// an invalid signature of the selected scheme must fail
+		w.Identity, w.EdDSAMessage = eddsaID, new(big.Int).Add(eddsaMsg, big.NewInt(1))
+		c.Assert(test.IsSolved(&testSelectorCircuit{}, &w, field), qt.IsNotNil)
+		// the inputs of the other scheme can be the public placeholders
+		w.EdDSAMessage = eddsaMsg
+		w.ECDSAPublicKey, w.ECDSASignature, w.ECDSAMessage = ecdsa.DummyInputs()
+		c.Assert(test.IsSolved(&testSelectorCircuit{}, &w, field), qt.IsNil)
+		// but they are not a valid signature
+		w.Selector, w.Identity = 1, ecdsaDummyAddress
+		c.Assert(test.IsSolved(&testSelectorCircuit{}, &w, field), qt.IsNotNil)
+	})
+	c.Run("ecdsa", func(c *qt.C) {
+		w := witness
+		w.Selector, w.Identity = 1, ecdsaSig.Address
+		c.Assert(test.IsSolved(&testSelectorCircuit{}, &w, field), qt.IsNil)
+		// an invalid signature of the other scheme is ignored
+		w.EdDSAMessage = new(big.Int).Add(eddsaMsg, big.NewInt(1))
+		c.Assert(test.IsSolved(&testSelectorCircuit{}, &w, field), qt.IsNil)
+		// and so are the public placeholders
+		w.EdDSAPublicKey, w.EdDSASignature, w.EdDSAMessage = eddsa.DummyInputs()
+		c.Assert(test.IsSolved(&testSelectorCircuit{}, &w, field), qt.IsNil)
+		// an invalid signature of the selected scheme must fail
+		w.ECDSAMessage = emulated.ValueOf[emulated.Secp256k1Fr](crypto.Keccak256([]byte("bye")))
+		c.Assert(test.IsSolved(&testSelectorCircuit{}, &w, field), qt.IsNotNil)
+	})
+	c.Run("out of range", func(c *qt.C) {
+		w := witness
+		w.Selector, w.Identity = 2, 0
+		c.Assert(test.IsSolved(&testSelectorCircuit{}, &w, field), qt.IsNotNil)
+	})
+}
@@ -87,3 +87,20 @@ func BytesFromBigInt(b *big.Int, fixedLen int) Bytes {
 func BytesFromString(s string, fixexLen int) Bytes {
 	return BytesFromBigInt(new(big.Int).SetBytes([]byte(s)), fixexLen)
 }
+
+// BytesFromSlice converts a byte slice to a byte slice of the circuit with a
+// fixed length. If the input is shorter than fixedLen, it is right-padded
+// with zeros, and if it is longer, it is truncated. Unlike BytesFromBigInt,
+// it preserves the leading zeros of the input, so it is useful to assign
+// variable-length messages to fixed-size circuit inputs.
+func BytesFromSlice(b []byte, fixedLen int) Bytes {
+	u8 := make([]uints.U8, fixedLen)
+	for i := range u8 {
+		if i < len(b) {
+			u8[i] = uints.U8{Val: frontend.Variable(b[i])}
+		} else {
+			u8[i] = uints.U8{Val: frontend.Variable(0)}
+		}
+	}
+	return u8
+}
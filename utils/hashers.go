@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"math/big"
+
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/std/hash/mimc"
 	"github.com/vocdoni/gnark-crypto-primitives/hash/native/bn254/poseidon"
@@ -34,3 +36,9 @@ func PoseidonHasher(api frontend.API, data ...frontend.Variable) (frontend.Varia
 func Poseidon2Hasher(api frontend.API, data ...frontend.Variable) (frontend.Variable, error) {
 	return poseidon2.HashPoseidon2Gnark(api, data...)
 }
+
+// NativeHasher is the off-circuit counterpart of Hasher, used to compute
+// natively the values that a circuit calculates with a Hasher. It has the
+// signature of the Iden3 Poseidon hash function, which matches
+// PoseidonHasher.
+type NativeHasher func([]*big.Int) (*big.Int, error)